@@ -50,17 +50,23 @@ func MonitorParsedHTTPBody(ctx context.Context, body interface{}) error {
 // APM tracer middleware on use according to your blocking configuration.
 // This function always returns nil when appsec is disabled and doesn't block users.
 func SetUser(ctx context.Context, id string, opts ...tracer.UserMonitoringOption) error {
+	return setUser(ctx, id, id, opts...)
+}
+
+// setUser is SetUser with distinct user IDs for the span tags and the user
+// blocking checks, which differ when the span tags are anonymized.
+func setUser(ctx context.Context, tagID, monitoredID string, opts ...tracer.UserMonitoringOption) error {
 	s, ok := tracer.SpanFromContext(ctx)
 	if !ok {
 		log.Debug("appsec: could not retrieve span from context. User ID tag won't be set")
 		return nil
 	}
-	tracer.SetUser(s, id, opts...)
+	tracer.SetUser(s, tagID, opts...)
 	if !appsec.Enabled() {
 		appsecDisabledLog.Do(func() { log.Warn("appsec: not enabled. User blocking checks won't be performed.") })
 		return nil
 	}
-	return sharedsec.MonitorUser(ctx, id)
+	return sharedsec.MonitorUser(ctx, monitoredID)
 }
 
 // TrackUserLoginSuccessEvent sets a successful user login event, with the given
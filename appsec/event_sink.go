@@ -0,0 +1,25 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2024 Datadog, Inc.
+
+package appsec
+
+import (
+	"gopkg.in/DataDog/dd-trace-go.v1/internal/appsec/eventsink"
+)
+
+// SecurityEvent is a security event detected by AppSec, as exported to an
+// EventSink.
+type SecurityEvent = eventsink.Event
+
+// EventSink receives the security events detected by AppSec, in order to
+// export them to the destination of the application's choice, such as a SIEM
+// tool. It is set with tracer.WithAppSecEventSink, and takes precedence over
+// the event sink configured with DD_APPSEC_EVENT_SINK.
+//
+// The events are written asynchronously, at most DD_APPSEC_EVENT_SINK_RATE_LIMIT
+// per second, and its methods are never called concurrently. Close is not
+// called by the tracer: the application closes the sink once tracer.Stop has
+// returned, since the pending events are written by then.
+type EventSink = eventsink.Sink
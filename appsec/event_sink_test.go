@@ -0,0 +1,74 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2024 Datadog, Inc.
+
+package appsec_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"gopkg.in/DataDog/dd-trace-go.v1/appsec"
+	httptrace "gopkg.in/DataDog/dd-trace-go.v1/contrib/net/http"
+	"gopkg.in/DataDog/dd-trace-go.v1/ddtrace/tracer"
+	privateAppsec "gopkg.in/DataDog/dd-trace-go.v1/internal/appsec"
+
+	"github.com/stretchr/testify/require"
+)
+
+// memorySink is an appsec.EventSink keeping the security events in memory.
+type memorySink struct {
+	events []*appsec.SecurityEvent
+	mu     sync.Mutex
+}
+
+var _ appsec.EventSink = (*memorySink)(nil)
+
+func (s *memorySink) Write(e *appsec.SecurityEvent) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.events = append(s.events, e)
+	return nil
+}
+
+func (s *memorySink) Close() error {
+	return nil
+}
+
+func TestEventSink(t *testing.T) {
+	t.Setenv("DD_APPSEC_ENABLED", "true")
+	t.Setenv("DD_APPSEC_RULES", "../internal/appsec/testdata/blocking.json")
+	sink := &memorySink{}
+	tracer.Start(tracer.WithAppSecEventSink(sink))
+	defer tracer.Stop()
+	if !privateAppsec.Enabled() {
+		t.Skip("AppSec needs to be enabled for this test")
+	}
+
+	mux := httptrace.NewServeMux()
+	mux.HandleFunc("/user", func(w http.ResponseWriter, r *http.Request) {
+		if err := appsec.SetUser(r.Context(), "blocked-user-1"); err != nil {
+			return
+		}
+		w.Write([]byte("Hello World!\n"))
+	})
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	res, err := srv.Client().Get(srv.URL + "/user")
+	require.NoError(t, err)
+	res.Body.Close()
+	require.Equal(t, http.StatusForbidden, res.StatusCode)
+
+	// Stop the tracer to flush the event sink
+	tracer.Stop()
+	sink.mu.Lock()
+	defer sink.mu.Unlock()
+	require.Len(t, sink.events, 1)
+	require.Equal(t, "blk-001-002", sink.events[0].RuleID)
+	require.Equal(t, "blocked-user-1", sink.events[0].UserID)
+	require.True(t, sink.events[0].Blocked)
+}
@@ -0,0 +1,31 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2024 Datadog, Inc.
+
+// Package events provides the error types returned by function calls protected
+// by Application Security. They allow callers to tell security blocking errors
+// apart from regular errors.
+package events
+
+import "errors"
+
+var _ error = (*BlockingSecurityEvent)(nil)
+
+// BlockingSecurityEvent is the error type returned by function calls blocked by
+// AppSec, such as an outgoing HTTP request to a URL controlled by an attacker.
+// Even though AppSec takes care of responding automatically to the blocked
+// request, it is the caller's duty to abort its current execution flow when
+// such an error is returned.
+type BlockingSecurityEvent struct{}
+
+func (*BlockingSecurityEvent) Error() string {
+	return "request blocked by WAF"
+}
+
+// IsSecurityError returns true if the error is, or wraps, a security blocking
+// error.
+func IsSecurityError(err error) bool {
+	var secErr *BlockingSecurityEvent
+	return errors.As(err, &secErr)
+}
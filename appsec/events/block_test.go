@@ -0,0 +1,21 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2024 Datadog, Inc.
+
+package events
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestIsSecurityError(t *testing.T) {
+	require.True(t, IsSecurityError(&BlockingSecurityEvent{}))
+	require.True(t, IsSecurityError(fmt.Errorf("wrapped: %w", &BlockingSecurityEvent{})))
+	require.False(t, IsSecurityError(errors.New("not a security error")))
+	require.False(t, IsSecurityError(nil))
+}
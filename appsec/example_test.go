@@ -6,6 +6,7 @@
 package appsec_test
 
 import (
+	"context"
 	"encoding/json"
 	"io"
 	"net/http"
@@ -79,3 +80,18 @@ func ExampleSetUser() {
 		w.Write([]byte("User monitored using AppSec SetUser SDK\n"))
 	})
 }
+
+func checkPassword(_ context.Context, username, password string) (valid bool, exists bool) {
+	// Check the credentials against your user database
+	return false, false
+}
+
+// Authenticate requests with HTTP basic authentication while automatically
+// tracking login success and failure events
+func ExampleWrapBasicAuth() {
+	mux := httptrace.NewServeMux()
+	mux.Handle("/account", appsec.WrapBasicAuth(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("Authenticated and monitored using AppSec WrapBasicAuth\n"))
+	}), "account", checkPassword))
+	http.ListenAndServe(":8080", mux)
+}
@@ -0,0 +1,163 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2024 Datadog, Inc.
+
+package appsec
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+
+	"gopkg.in/DataDog/dd-trace-go.v1/ddtrace/ext"
+	"gopkg.in/DataDog/dd-trace-go.v1/internal/appsec"
+	"gopkg.in/DataDog/dd-trace-go.v1/internal/appsec/config"
+)
+
+// BasicAuthValidator validates the credentials of an HTTP basic authentication.
+// It returns whether the credentials are valid and, when they are not, whether
+// the user exists.
+type BasicAuthValidator func(ctx context.Context, username, password string) (valid bool, exists bool)
+
+// WrapBasicAuth returns an HTTP handler authenticating the requests with HTTP
+// basic authentication before calling h. The credentials are checked with the
+// given validator, and the outcome is automatically tracked as a login success
+// or failure event, the way TrackUserLoginSuccessEvent and
+// TrackUserLoginFailureEvent do, with the users identified according to
+// DD_APPSEC_AUTO_USER_INSTRUMENTATION_MODE (identification, anonymization or
+// disabled). The authenticated user is also set with SetUser() so that blocked
+// users are blocked without calling h, including in anonymization mode where
+// the users are blocked according to their actual user IDs.
+// Requests without valid credentials get a 401 Unauthorized response
+// challenging the client for the given realm.
+// The returned handler must be served by an APM tracer HTTP middleware, such
+// as the one of contrib/net/http, for the events to be tracked and the blocking
+// response to be sent.
+func WrapBasicAuth(h http.Handler, realm string, validate BasicAuthValidator) http.Handler {
+	challenge := fmt.Sprintf("Basic realm=%q, charset=\"UTF-8\"", realm)
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+		username, password, ok := r.BasicAuth()
+		if !ok {
+			// Not a login attempt
+			w.Header().Set("WWW-Authenticate", challenge)
+			http.Error(w, http.StatusText(http.StatusUnauthorized), http.StatusUnauthorized)
+			return
+		}
+		if valid, exists := validate(ctx, username, password); !valid {
+			trackAutoLoginFailureEvent(ctx, username, exists)
+			w.Header().Set("WWW-Authenticate", challenge)
+			http.Error(w, http.StatusText(http.StatusUnauthorized), http.StatusUnauthorized)
+			return
+		}
+		if err := trackAutoLoginSuccessEvent(ctx, username); err != nil {
+			// The user is blocked and the blocking response is sent by the
+			// APM tracer middleware
+			return
+		}
+		h.ServeHTTP(w, r)
+	})
+}
+
+// JWTLoginHook automatically tracks the outcome of JWT authentications as login
+// success or failure events, the way TrackUserLoginSuccessEvent and
+// TrackUserLoginFailureEvent do, with the users identified according to
+// DD_APPSEC_AUTO_USER_INSTRUMENTATION_MODE (identification, anonymization or
+// disabled). Its methods are meant to be called by the success and error
+// handlers of JWT middlewares, with the request context.
+type JWTLoginHook struct {
+	// UserIDClaim is the name of the claim holding the user ID. It defaults to
+	// the standard subject claim "sub".
+	UserIDClaim string
+}
+
+// OnSuccess tracks a login success event for the user of the given validated
+// token claims, and sets it with SetUser(). As documented in SetUser(), an
+// error is returned when the user is blocked, in which case the request handler
+// must not be called.
+func (h JWTLoginHook) OnSuccess(ctx context.Context, claims map[string]any) error {
+	uid, ok := h.userID(claims)
+	if !ok {
+		return nil
+	}
+	return trackAutoLoginSuccessEvent(ctx, uid)
+}
+
+// OnFailure tracks a login failure event when the given claims of the rejected
+// token, if any, hold a user ID. Whether the user exists is unknown and always
+// reported as false.
+func (h JWTLoginHook) OnFailure(ctx context.Context, claims map[string]any) {
+	uid, ok := h.userID(claims)
+	if !ok {
+		return
+	}
+	trackAutoLoginFailureEvent(ctx, uid, false)
+}
+
+func (h JWTLoginHook) userID(claims map[string]any) (string, bool) {
+	claim := h.UserIDClaim
+	if claim == "" {
+		claim = "sub"
+	}
+	switch uid := claims[claim].(type) {
+	case string:
+		return uid, uid != ""
+	case nil:
+		return "", false
+	default:
+		return fmt.Sprint(uid), true
+	}
+}
+
+// trackAutoLoginSuccessEvent tracks an automatic login success event of the
+// given user, identified according to the current user identification mode,
+// and sets the user with SetUser(). In anonymization mode, only the span tags
+// are anonymized: the user blocking checks are still performed with the actual
+// user ID so that the denylisted users keep being blocked.
+func trackAutoLoginSuccessEvent(ctx context.Context, uid string) error {
+	mode := appsec.UserIdentificationMode()
+	if mode == config.UserIdentificationModeDisabled {
+		return nil
+	}
+	span := getRootSpan(ctx)
+	if span == nil {
+		return nil
+	}
+	const tagPrefix = "appsec.events.users.login.success."
+	span.SetTag(tagPrefix+"track", true)
+	span.SetTag("_dd."+tagPrefix+"auto.mode", string(mode))
+	span.SetTag(ext.SamplingPriority, ext.PriorityUserKeep)
+	return setUser(ctx, identifyUser(uid, mode), uid)
+}
+
+// trackAutoLoginFailureEvent tracks an automatic login failure event of the
+// given user, identified according to the current user identification mode.
+func trackAutoLoginFailureEvent(ctx context.Context, uid string, exists bool) {
+	mode := appsec.UserIdentificationMode()
+	if mode == config.UserIdentificationModeDisabled {
+		return
+	}
+	span := getRootSpan(ctx)
+	if span == nil {
+		return
+	}
+	const tagPrefix = "appsec.events.users.login.failure."
+	span.SetTag(tagPrefix+"track", true)
+	span.SetTag("_dd."+tagPrefix+"auto.mode", string(mode))
+	span.SetTag(tagPrefix+"usr.id", identifyUser(uid, mode))
+	span.SetTag(tagPrefix+"usr.exists", exists)
+	span.SetTag(ext.SamplingPriority, ext.PriorityUserKeep)
+}
+
+// identifyUser returns the given user ID as-is in identification mode, and its
+// anonymized hash in anonymization mode.
+func identifyUser(uid string, mode config.UserIdentificationMode) string {
+	if mode != config.UserIdentificationModeAnonymization {
+		return uid
+	}
+	sum := sha256.Sum256([]byte(uid))
+	return "anon_" + hex.EncodeToString(sum[:16])
+}
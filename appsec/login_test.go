@@ -0,0 +1,195 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2024 Datadog, Inc.
+
+package appsec_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"gopkg.in/DataDog/dd-trace-go.v1/appsec"
+	httptrace "gopkg.in/DataDog/dd-trace-go.v1/contrib/net/http"
+	"gopkg.in/DataDog/dd-trace-go.v1/ddtrace/mocktracer"
+	"gopkg.in/DataDog/dd-trace-go.v1/ddtrace/tracer"
+	privateAppsec "gopkg.in/DataDog/dd-trace-go.v1/internal/appsec"
+	"gopkg.in/DataDog/dd-trace-go.v1/internal/appsec/config"
+
+	"github.com/stretchr/testify/require"
+)
+
+func validateTestCredentials(_ context.Context, username, password string) (valid bool, exists bool) {
+	switch username {
+	case "jane", "blocked-user-1":
+		return password == "secret", true
+	default:
+		return false, false
+	}
+}
+
+func TestWrapBasicAuth(t *testing.T) {
+	t.Setenv("DD_APPSEC_RULES", "../internal/appsec/testdata/blocking.json")
+
+	mux := httptrace.NewServeMux()
+	mux.Handle("/login", appsec.WrapBasicAuth(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.Write([]byte("Welcome!\n"))
+	}), "test", validateTestCredentials))
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	login := func(t *testing.T, username, password string) (*http.Response, mocktracer.Span) {
+		mt := mocktracer.Start()
+		defer mt.Stop()
+		req, err := http.NewRequest("GET", srv.URL+"/login", nil)
+		require.NoError(t, err)
+		if username != "" {
+			req.SetBasicAuth(username, password)
+		}
+		res, err := srv.Client().Do(req)
+		require.NoError(t, err)
+		res.Body.Close()
+		spans := mt.FinishedSpans()
+		require.Len(t, spans, 1)
+		return res, spans[0]
+	}
+
+	for _, tc := range []struct {
+		name         string
+		mode         string
+		expectedMode string
+		expectedUser string
+	}{
+		{name: "default", expectedMode: "identification", expectedUser: "jane"},
+		{name: "identification", mode: "identification", expectedMode: "identification", expectedUser: "jane"},
+		{name: "anonymization", mode: "anonymization", expectedMode: "anonymization", expectedUser: "anon_81f8f6dde88365f3928796ec7aa53f72"},
+		{name: "disabled", mode: "disabled"},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Setenv(config.EnvAutoUserInstrumentationMode, tc.mode)
+			privateAppsec.Start()
+			defer privateAppsec.Stop()
+			if !privateAppsec.Enabled() {
+				t.Skip("AppSec needs to be enabled for this test")
+			}
+
+			t.Run("success", func(t *testing.T) {
+				res, span := login(t, "jane", "secret")
+				require.Equal(t, http.StatusOK, res.StatusCode)
+				if tc.expectedMode == "" {
+					require.Nil(t, span.Tag("appsec.events.users.login.success.track"))
+					require.Nil(t, span.Tag("usr.id"))
+					return
+				}
+				require.Equal(t, true, span.Tag("appsec.events.users.login.success.track"))
+				require.Equal(t, tc.expectedMode, span.Tag("_dd.appsec.events.users.login.success.auto.mode"))
+				require.Equal(t, tc.expectedUser, span.Tag("usr.id"))
+			})
+
+			t.Run("failure", func(t *testing.T) {
+				res, span := login(t, "jane", "wrong")
+				require.Equal(t, http.StatusUnauthorized, res.StatusCode)
+				require.Equal(t, `Basic realm="test", charset="UTF-8"`, res.Header.Get("WWW-Authenticate"))
+				if tc.expectedMode == "" {
+					require.Nil(t, span.Tag("appsec.events.users.login.failure.track"))
+					return
+				}
+				require.Equal(t, true, span.Tag("appsec.events.users.login.failure.track"))
+				require.Equal(t, tc.expectedMode, span.Tag("_dd.appsec.events.users.login.failure.auto.mode"))
+				require.Equal(t, tc.expectedUser, span.Tag("appsec.events.users.login.failure.usr.id"))
+				require.Equal(t, true, span.Tag("appsec.events.users.login.failure.usr.exists"))
+				require.Nil(t, span.Tag("usr.id"))
+			})
+
+			t.Run("no-credentials", func(t *testing.T) {
+				res, span := login(t, "", "")
+				require.Equal(t, http.StatusUnauthorized, res.StatusCode)
+				require.Nil(t, span.Tag("appsec.events.users.login.failure.track"))
+			})
+		})
+	}
+
+	t.Run("blocked-user", func(t *testing.T) {
+		privateAppsec.Start()
+		defer privateAppsec.Stop()
+		if !privateAppsec.Enabled() {
+			t.Skip("AppSec needs to be enabled for this test")
+		}
+		res, span := login(t, "blocked-user-1", "secret")
+		require.Equal(t, http.StatusForbidden, res.StatusCode)
+		require.Equal(t, true, span.Tag("appsec.events.users.login.success.track"))
+		require.Equal(t, true, span.Tag("appsec.blocked"))
+	})
+
+	t.Run("blocked-user-anonymization", func(t *testing.T) {
+		t.Setenv(config.EnvAutoUserInstrumentationMode, "anonymization")
+		privateAppsec.Start()
+		defer privateAppsec.Stop()
+		if !privateAppsec.Enabled() {
+			t.Skip("AppSec needs to be enabled for this test")
+		}
+		// The user is blocked according to its actual user ID while the span
+		// is tagged with its anonymized one
+		res, span := login(t, "blocked-user-1", "secret")
+		require.Equal(t, http.StatusForbidden, res.StatusCode)
+		require.Equal(t, true, span.Tag("appsec.blocked"))
+		require.Equal(t, "anon_baa03547cc62ca53768ea852458f6221", span.Tag("usr.id"))
+	})
+}
+
+func TestJWTLoginHook(t *testing.T) {
+	privateAppsec.Start()
+	defer privateAppsec.Stop()
+	if !privateAppsec.Enabled() {
+		t.Skip("AppSec needs to be enabled for this test")
+	}
+
+	for _, tc := range []struct {
+		name   string
+		hook   appsec.JWTLoginHook
+		claims map[string]any
+		user   string
+	}{
+		{name: "sub", claims: map[string]any{"sub": "jane"}, user: "jane"},
+		{name: "custom-claim", hook: appsec.JWTLoginHook{UserIDClaim: "uid"}, claims: map[string]any{"uid": 42.}, user: "42"},
+		{name: "no-user", claims: map[string]any{"iss": "test"}},
+		{name: "no-claims"},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Run("success", func(t *testing.T) {
+				mt := mocktracer.Start()
+				defer mt.Stop()
+				span, ctx := tracer.StartSpanFromContext(context.Background(), "example")
+				require.NoError(t, tc.hook.OnSuccess(ctx, tc.claims))
+				span.Finish()
+
+				finished := mt.FinishedSpans()[0]
+				if tc.user == "" {
+					require.Nil(t, finished.Tag("appsec.events.users.login.success.track"))
+					return
+				}
+				require.Equal(t, true, finished.Tag("appsec.events.users.login.success.track"))
+				require.Equal(t, tc.user, finished.Tag("usr.id"))
+			})
+
+			t.Run("failure", func(t *testing.T) {
+				mt := mocktracer.Start()
+				defer mt.Stop()
+				span, ctx := tracer.StartSpanFromContext(context.Background(), "example")
+				tc.hook.OnFailure(ctx, tc.claims)
+				span.Finish()
+
+				finished := mt.FinishedSpans()[0]
+				if tc.user == "" {
+					require.Nil(t, finished.Tag("appsec.events.users.login.failure.track"))
+					return
+				}
+				require.Equal(t, true, finished.Tag("appsec.events.users.login.failure.track"))
+				require.Equal(t, tc.user, finished.Tag("appsec.events.users.login.failure.usr.id"))
+				require.Equal(t, false, finished.Tag("appsec.events.users.login.failure.usr.exists"))
+			})
+		})
+	}
+}
@@ -0,0 +1,64 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2024 Datadog, Inc.
+
+// Command gotest-import reports to CI Visibility the tests of a `go test -json` output, read from a file or
+// from the standard input, for the test binaries that can't be instrumented with gotesting.RunM:
+//
+//	go test -json ./... | gotest-import
+//	gotest-import -input test-output.json -payload-file payload.msgpack
+//
+// The tests are sent through the CI Visibility writer, configured with the usual environment variables, or
+// written to a payload file. The command exits with 1 when a package failed, as `go test` would.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"gopkg.in/DataDog/dd-trace-go.v1/civisibility/gotestjson"
+	"gopkg.in/DataDog/dd-trace-go.v1/internal/civisibility"
+	"gopkg.in/DataDog/dd-trace-go.v1/internal/civisibility/constants"
+)
+
+func main() {
+	os.Exit(run())
+}
+
+// run imports the `go test -json` output and returns the exit code of the command.
+func run() int {
+	input := flag.String("input", "-", "file containing the `go test -json` output, - for the standard input")
+	payloadFile := flag.String("payload-file", "", "file the CI Visibility payloads are written to instead of being sent")
+	command := flag.String("command", "go test -json", "command reported for the test session")
+	workingDirectory := flag.String("working-directory", "", "working directory reported for the test session, the current one by default")
+	flag.Parse()
+
+	var r io.Reader = os.Stdin
+	if *input != "-" {
+		f, err := os.Open(*input)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "gotest-import: %v\n", err)
+			return 2
+		}
+		defer f.Close()
+		r = f
+	}
+	if *payloadFile != "" {
+		os.Setenv(constants.CiVisibilityPayloadFileEnvironmentVariable, *payloadFile)
+	}
+
+	opts := []gotestjson.Option{gotestjson.WithCommand(*command)}
+	if *workingDirectory != "" {
+		opts = append(opts, gotestjson.WithWorkingDirectory(*workingDirectory))
+	}
+	exitCode, err := gotestjson.Import(r, opts...)
+	civisibility.ExitCiVisibility()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "gotest-import: error reading the go test output: %v\n", err)
+		return 2
+	}
+	return exitCode
+}
@@ -7,7 +7,11 @@
 
 package gotesting
 
-import "testing"
+import (
+	"errors"
+	"io"
+	"testing"
+)
 
 // getCoverage retrieves the code coverage percentage using the standard testing package.
 // This function is used for Go versions prior to 1.20, where the old coverage format is the default.
@@ -21,3 +25,13 @@ import "testing"
 func getCoverage() (float64, error) {
 	return testing.Coverage() * 100, nil
 }
+
+// writeCoverageMeta is not supported prior to Go 1.20, as it requires the runtime/coverage package.
+func writeCoverageMeta(io.Writer) error {
+	return errors.New("per-test coverage requires Go 1.20 or later")
+}
+
+// writeCoverageCounters is not supported prior to Go 1.20, as it requires the runtime/coverage package.
+func writeCoverageCounters(io.Writer) error {
+	return errors.New("per-test coverage requires Go 1.20 or later")
+}
@@ -0,0 +1,244 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2024 Datadog, Inc.
+
+package gotesting
+
+import (
+	"encoding/binary"
+	"errors"
+	"strconv"
+)
+
+// The decoding of the coverage meta-data and counter data written by the runtime/coverage package, whose
+// formats are defined by the internal/coverage package of the Go distribution (format version 1).
+
+const (
+	// coverageMetaFileHeaderSize is the size of the header of the meta-data (internal/coverage.MetaFileHeader).
+	coverageMetaFileHeaderSize = 56
+	// coverageMetaPackageHeaderSize is the size of the header of the meta-data of a package
+	// (internal/coverage.MetaSymbolHeader).
+	coverageMetaPackageHeaderSize = 44
+	// coverageCounterFileHeaderSize is the size of the header of the counter data
+	// (internal/coverage.CounterFileHeader).
+	coverageCounterFileHeaderSize = 32
+	// coverageCounterSegmentHeaderSize is the size of the header of a segment of the counter data
+	// (internal/coverage.CounterSegmentHeader).
+	coverageCounterSegmentHeaderSize = 16
+
+	// coverageGranularityPerFunc is the counter granularity with a single counter per function.
+	coverageGranularityPerFunc = 2
+	// coverageFlavorRaw and coverageFlavorULEB128 are the encodings of the counters.
+	coverageFlavorRaw, coverageFlavorULEB128 = 1, 2
+)
+
+var (
+	coverageMetaMagic    = [4]byte{0x00, 0x63, 0x76, 0x6d}
+	coverageCounterMagic = [4]byte{0x00, 0x63, 0x77, 0x6d}
+
+	errMalformedCoverageData = errors.New("malformed coverage data")
+)
+
+// coverageMeta maps the coverage counters of the functions of the test binary to the blocks they count.
+type coverageMeta struct {
+	// perFunc is true when there is a single counter per function rather than per block.
+	perFunc bool
+	// blocks holds the blocks of every function ("file.go:line.col,line.col"), by package and function index.
+	blocks [][][]string
+}
+
+// decodeCoverageMeta decodes the coverage meta-data written by coverage.WriteMeta.
+func decodeCoverageMeta(b []byte) (*coverageMeta, error) {
+	r := &coverageDataReader{b: b}
+	if r.magic() != coverageMetaMagic || r.u32() != 1 {
+		return nil, errors.New("unsupported coverage meta-data")
+	}
+	r.u64() // total length
+	entries := r.u64()
+	r.skip(16 + 4 + 4 + 1) // hash, string table offset and length, counter mode
+	meta := &coverageMeta{perFunc: r.u8() == coverageGranularityPerFunc}
+	r.seek(coverageMetaFileHeaderSize)
+	if r.err != nil || entries > uint64(len(b)) {
+		return nil, errMalformedCoverageData
+	}
+	offsets := make([]uint64, entries)
+	for i := range offsets {
+		offsets[i] = r.u64()
+	}
+	meta.blocks = make([][][]string, entries)
+	for i := range meta.blocks {
+		length := r.u64()
+		if r.err != nil || offsets[i]+length > uint64(len(b)) {
+			return nil, errMalformedCoverageData
+		}
+		blocks, err := decodeCoveragePackageMeta(b[offsets[i] : offsets[i]+length])
+		if err != nil {
+			return nil, err
+		}
+		meta.blocks[i] = blocks
+	}
+	return meta, nil
+}
+
+// decodeCoveragePackageMeta decodes the coverage meta-data of a package, returning the blocks of its functions.
+func decodeCoveragePackageMeta(b []byte) ([][]string, error) {
+	r := &coverageDataReader{b: b}
+	r.skip(4 + 4 + 4 + 4 + 16 + 4 + 4) // length, package name, path and module path, hash, padding, files
+	numFuncs := r.u32()
+	if r.err != nil || coverageMetaPackageHeaderSize+uint64(numFuncs)*4 > uint64(len(b)) {
+		return nil, errMalformedCoverageData
+	}
+	funcOffsets := make([]uint32, numFuncs)
+	for i := range funcOffsets {
+		funcOffsets[i] = r.u32()
+	}
+	strTab := r.stringTable()
+
+	blocks := make([][]string, numFuncs)
+	for i, offset := range funcOffsets {
+		r.seek(int(offset))
+		numUnits := r.uleb()
+		r.uleb() // function name
+		file := r.string(strTab, r.uleb())
+		if r.err != nil || numUnits > uint64(len(b)) {
+			return nil, errMalformedCoverageData
+		}
+		blocks[i] = make([]string, numUnits)
+		for j := range blocks[i] {
+			startLine, startCol, endLine, endCol := r.uleb(), r.uleb(), r.uleb(), r.uleb()
+			r.uleb() // number of statements
+			blocks[i][j] = file + ":" + strconv.FormatUint(startLine, 10) + "." + strconv.FormatUint(startCol, 10) +
+				"," + strconv.FormatUint(endLine, 10) + "." + strconv.FormatUint(endCol, 10)
+		}
+	}
+	if r.err != nil {
+		return nil, r.err
+	}
+	return blocks, nil
+}
+
+// decodeCounters decodes the coverage counter data written by coverage.WriteCounters, returning the counters
+// of the executed blocks.
+func (m *coverageMeta) decodeCounters(b []byte) (coverageCounters, error) {
+	r := &coverageDataReader{b: b}
+	if r.magic() != coverageCounterMagic || r.u32() != 1 {
+		return nil, errors.New("unsupported coverage counter data")
+	}
+	r.skip(16) // meta-data hash
+	flavor, bigEndian := r.u8(), r.u8() != 0
+	r.seek(coverageCounterFileHeaderSize)
+	funcs := r.u64()
+	strTabLen, argsLen := r.u32(), r.u32()
+	r.seek(coverageCounterFileHeaderSize + coverageCounterSegmentHeaderSize + int(strTabLen) + int(argsLen))
+	r.seek((r.off + 3) &^ 3)
+
+	var counter func() uint64
+	switch {
+	case flavor == coverageFlavorULEB128:
+		counter = r.uleb
+	case flavor == coverageFlavorRaw && bigEndian:
+		counter = func() uint64 { return uint64(r.u32be()) }
+	case flavor == coverageFlavorRaw:
+		counter = func() uint64 { return uint64(r.u32()) }
+	default:
+		return nil, errMalformedCoverageData
+	}
+
+	counters := coverageCounters{}
+	for i := uint64(0); i < funcs && r.err == nil; i++ {
+		numCounters, pkgIdx, funcIdx := counter(), counter(), counter()
+		if r.err != nil || pkgIdx >= uint64(len(m.blocks)) || funcIdx >= uint64(len(m.blocks[pkgIdx])) {
+			return nil, errMalformedCoverageData
+		}
+		blocks := m.blocks[pkgIdx][funcIdx]
+		for j := uint64(0); j < numCounters; j++ {
+			count := counter()
+			switch {
+			case count == 0:
+			case m.perFunc:
+				for _, block := range blocks {
+					counters[block] += count
+				}
+			case j < uint64(len(blocks)):
+				counters[blocks[j]] += count
+			}
+		}
+	}
+	if r.err != nil {
+		return nil, r.err
+	}
+	return counters, nil
+}
+
+// coverageDataReader reads the little-endian coverage data in b, recording the first out-of-bounds read in err.
+type coverageDataReader struct {
+	b   []byte
+	off int
+	err error
+}
+
+func (r *coverageDataReader) read(n int) []byte {
+	if r.err != nil || n < 0 || r.off+n > len(r.b) {
+		// Return zeros to the fixed-size reads, which are at most 8 bytes long
+		r.err = errMalformedCoverageData
+		return make([]byte, 8)
+	}
+	r.off += n
+	return r.b[r.off-n : r.off]
+}
+
+func (r *coverageDataReader) seek(off int) {
+	if off < 0 || off > len(r.b) {
+		r.err = errMalformedCoverageData
+		return
+	}
+	r.off = off
+}
+
+func (r *coverageDataReader) skip(n int) { r.read(n) }
+
+func (r *coverageDataReader) magic() (magic [4]byte) {
+	copy(magic[:], r.read(4))
+	return magic
+}
+
+func (r *coverageDataReader) u8() uint8     { return r.read(1)[0] }
+func (r *coverageDataReader) u32() uint32   { return binary.LittleEndian.Uint32(r.read(4)) }
+func (r *coverageDataReader) u32be() uint32 { return binary.BigEndian.Uint32(r.read(4)) }
+func (r *coverageDataReader) u64() uint64   { return binary.LittleEndian.Uint64(r.read(8)) }
+
+// uleb reads an unsigned LEB128 value.
+func (r *coverageDataReader) uleb() (value uint64) {
+	for shift := uint(0); shift < 64; shift += 7 {
+		b := r.u8()
+		value |= uint64(b&0x7f) << shift
+		if b&0x80 == 0 {
+			break
+		}
+	}
+	return value
+}
+
+// stringTable reads a string table, made of its number of strings followed by each string and its length.
+func (r *coverageDataReader) stringTable() []string {
+	n := r.uleb()
+	if n > uint64(len(r.b)) {
+		r.err = errMalformedCoverageData
+		return nil
+	}
+	strTab := make([]string, 0, n)
+	for i := uint64(0); i < n && r.err == nil; i++ {
+		strTab = append(strTab, string(r.read(int(r.uleb()))))
+	}
+	return strTab
+}
+
+// string returns the string of index idx in the given string table.
+func (r *coverageDataReader) string(strTab []string, idx uint64) string {
+	if idx >= uint64(len(strTab)) {
+		r.err = errMalformedCoverageData
+		return ""
+	}
+	return strTab[idx]
+}
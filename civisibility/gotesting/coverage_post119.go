@@ -59,3 +59,23 @@ func getCoverage() (float64, error) {
 	}
 	return 0, err
 }
+
+// writeCoverageMeta writes the coverage meta-data of the test binary to w. The meta-data of a test binary
+// is only finalized once written to a directory, which is done once, in a temporary directory, beforehand.
+func writeCoverageMeta(w io.Writer) error {
+	dir, err := os.MkdirTemp("", "dd-test-coverage-")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(dir)
+	if err := runtime_coverage_processCoverTestDirInternal(dir, "", testing.CoverMode(), "", io.Discard); err != nil {
+		return err
+	}
+	return coverage.WriteMeta(w)
+}
+
+// writeCoverageCounters writes a snapshot of the coverage counters of the test binary to w. It requires the
+// atomic cover mode.
+func writeCoverageCounters(w io.Writer) error {
+	return coverage.WriteCounters(w)
+}
@@ -0,0 +1,65 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2024 Datadog, Inc.
+
+package gotesting
+
+import (
+	"time"
+
+	internal "gopkg.in/DataDog/dd-trace-go.v1/internal/civisibility"
+	"gopkg.in/DataDog/dd-trace-go.v1/internal/civisibility/constants"
+	logger "gopkg.in/DataDog/dd-trace-go.v1/internal/log"
+)
+
+// initEarlyFlakeDetection marks the tests unknown to the backend as new, so that they are run several
+// times by Early Flake Detection. The session is considered faulty, and Early Flake Detection aborted,
+// when the percentage of new tests exceeds the threshold of the settings.
+func initEarlyFlakeDetection(tests []*testingTInfo) {
+	settings := internal.GetSettings().EarlyFlakeDetection
+	knownTests := internal.GetKnownTests()
+	if !settings.Enabled || knownTests == nil || len(tests) == 0 {
+		return
+	}
+
+	// New tests are run in isolation from the testing framework.
+	if !canIsolateTests() {
+		logger.Warn("Early Flake Detection is not supported with this Go version, new tests won't be retried.")
+		return
+	}
+
+	session.SetTag(constants.TestEarlyFlakeDetectionEnabled, "true")
+	var newTests []*testingTInfo
+	for _, test := range tests {
+		if !knownTests.Contains(test.moduleName, test.suiteName, test.testName) {
+			newTests = append(newTests, test)
+		}
+	}
+	if threshold := settings.FaultySessionThreshold; threshold > 0 && len(newTests)*100 > threshold*len(tests) {
+		logger.Warn("Early Flake Detection aborted: %d of %d tests are new.", len(newTests), len(tests))
+		session.SetTag(constants.TestEarlyFlakeDetectionRetryAborted, "faulty")
+		return
+	}
+	for _, test := range newTests {
+		test.isNew = true
+	}
+}
+
+// earlyFlakeDetectionRetryCount returns the number of retries of a new test whose first execution
+// lasted the given duration.
+func earlyFlakeDetectionRetryCount(duration time.Duration) int64 {
+	retries := internal.GetSettings().EarlyFlakeDetection.SlowTestRetries
+	switch {
+	case duration < 5*time.Second:
+		return int64(retries.FiveS)
+	case duration < 10*time.Second:
+		return int64(retries.TenS)
+	case duration < 30*time.Second:
+		return int64(retries.ThirtyS)
+	case duration < 5*time.Minute:
+		return int64(retries.FiveM)
+	default:
+		return 0
+	}
+}
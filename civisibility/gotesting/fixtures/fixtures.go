@@ -0,0 +1,37 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2024 Datadog, Inc.
+
+// Package fixtures provides traced versions of the fixtures commonly shared by tests, whose calls are
+// reported as the children of the running test by the CI Visibility instrumentation of the gotesting package.
+package fixtures
+
+import (
+	"database/sql"
+	"net/http"
+	"net/http/httptest"
+
+	sqltrace "gopkg.in/DataDog/dd-trace-go.v1/contrib/database/sql"
+	httptrace "gopkg.in/DataDog/dd-trace-go.v1/contrib/net/http"
+)
+
+// NewServer starts and returns a new httptest.Server serving handler, like httptest.NewServer, with both
+// its handler and the client returned by its Client method traced. The requests sent and served during a
+// test are then reported as the children of the test, along with the service calls the test made, so slow
+// dependencies can be spotted per test. The caller should call Close when finished, to shut it down.
+func NewServer(handler http.Handler, opts ...httptrace.Option) *httptest.Server {
+	resourceNamer := httptrace.WithResourceNamer(func(r *http.Request) string {
+		return r.Method + " " + r.URL.Path
+	})
+	s := httptest.NewServer(httptrace.WrapHandler(handler, "", "", append([]httptrace.Option{resourceNamer}, opts...)...))
+	httptrace.WrapClient(s.Client())
+	return s
+}
+
+// OpenDB opens a database specified by its database driver name and a driver-specific data source name,
+// like sql.Open, with its queries traced. It is meant to open the databases shared by the tests, in
+// TestMain for instance: the queries run during a test are then reported as the children of the test.
+func OpenDB(driverName, dataSourceName string, opts ...sqltrace.Option) (*sql.DB, error) {
+	return sqltrace.Open(driverName, dataSourceName, opts...)
+}
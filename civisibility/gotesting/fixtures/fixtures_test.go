@@ -0,0 +1,63 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2024 Datadog, Inc.
+
+package fixtures
+
+import (
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"gopkg.in/DataDog/dd-trace-go.v1/civisibility/gotesting"
+	ddtracer "gopkg.in/DataDog/dd-trace-go.v1/ddtrace/tracer"
+	"gopkg.in/DataDog/dd-trace-go.v1/internal/civisibility/constants"
+)
+
+// TestMain runs the tests with CI Visibility, writing the payloads to a file instead of sending them.
+func TestMain(m *testing.M) {
+	dir, err := os.MkdirTemp("", "fixtures")
+	if err != nil {
+		panic(err)
+	}
+	os.Setenv(constants.CiVisibilityPayloadFileEnvironmentVariable, filepath.Join(dir, "payload.msgpack"))
+	exitCode := gotesting.RunM(m)
+	os.RemoveAll(dir)
+	os.Exit(exitCode)
+}
+
+// TestNewServer demonstrates the spans of the integrations reported as the children of the test,
+// without passing its context around.
+func TestNewServer(t *testing.T) {
+	testSpan, _ := ddtracer.SpanFromContext((*gotesting.T)(t).Context())
+	testTraceID := testSpan.Context().TraceID()
+
+	s := NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// The request is traced by the client of the server, in the trace of the test.
+		if span, ok := ddtracer.SpanFromContext(r.Context()); !ok || span.Context().TraceID() != testTraceID {
+			t.Error("expected the request to be traced in the trace of the test")
+		}
+		_, _ = w.Write([]byte("Hello World"))
+	}))
+	defer s.Close()
+
+	resp, err := s.Client().Get(s.URL + "/hello/world")
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+
+	// The spans started in the goroutines of the test are in its trace too.
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		span := ddtracer.StartSpan("background.work")
+		defer span.Finish()
+		if span.Context().TraceID() != testTraceID {
+			t.Error("expected the span to be in the trace of the test")
+		}
+	}()
+	<-done
+}
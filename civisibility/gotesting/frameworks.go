@@ -0,0 +1,78 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2024 Datadog, Inc.
+
+package gotesting
+
+import (
+	"reflect"
+	"runtime"
+	"sync/atomic"
+	"testing"
+
+	"gopkg.in/DataDog/dd-trace-go.v1/civisibility"
+	"gopkg.in/DataDog/dd-trace-go.v1/internal/civisibility/utils"
+)
+
+// Subtest is a test of a testing framework running its tests as subtests, see RunSubtests.
+type Subtest struct {
+	// Name is the name of the subtest, and of the test reported to CI visibility.
+	Name string
+
+	// Func is the function of the test, whose source location and module are reported. It defaults to F.
+	Func *runtime.Func
+
+	// F is the function running the test.
+	F func(*testing.T)
+}
+
+// RunSubtests runs the tests as subtests of t, like t.Run, and reports them to CI visibility as the tests
+// of the suite suiteName, with Automatic Test Retries. It lets the testing frameworks running their tests
+// as subtests, such as testify suites, report each of them on its own instead of a single Go test. The
+// subtests are run without instrumentation when the tests aren't run with RunM.
+func RunSubtests(t *testing.T, suiteName string, tests []Subtest) {
+	if session == nil {
+		for _, test := range tests {
+			t.Run(test.Name, test.F)
+		}
+		return
+	}
+
+	// Count all the tests beforehand, so that the suite stays open until its last test.
+	moduleNames := make([]string, len(tests))
+	for idx := range tests {
+		if tests[idx].Func == nil {
+			tests[idx].Func = runtime.FuncForPC(reflect.Indirect(reflect.ValueOf(tests[idx].F)).Pointer())
+		}
+		moduleNames[idx], _ = utils.GetModuleAndSuiteName(tests[idx].Func.Entry())
+		atomic.AddInt32(getTestsCounter(modulesCounters, moduleNames[idx]), 1)
+		atomic.AddInt32(getTestsCounter(suitesCounters, suiteName), 1)
+	}
+
+	var suite civisibility.DdTestSuite
+	for idx, test := range tests {
+		moduleName := moduleNames[idx]
+		ran := false
+		t.Run(test.Name, func(t *testing.T) {
+			ran = true
+			module := session.GetOrCreateModuleWithFramework(moduleName, testFramework, runtime.Version())
+			suite = module.GetOrCreateSuite(suiteName)
+			runTest(t, module, suite, test.Name, test.Func, test.F, false)
+		})
+		if !ran {
+			// The subtest was filtered out by -run: uncount it.
+			atomic.AddInt32(getTestsCounter(modulesCounters, moduleName), -1)
+			if atomic.AddInt32(getTestsCounter(suitesCounters, suiteName), -1) <= 0 && suite != nil {
+				suite.Close()
+			}
+		}
+	}
+}
+
+// GetTestSession returns the CI visibility test session of the tests run with RunM, or nil. It lets the
+// testing frameworks reporting their tests through the manual API, such as Ginkgo, report them in the
+// session of the Go tests.
+func GetTestSession() civisibility.DdTestSession {
+	return session
+}
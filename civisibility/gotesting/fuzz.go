@@ -0,0 +1,79 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2024 Datadog, Inc.
+
+package gotesting
+
+import (
+	"bufio"
+	"io"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// fuzzStatsRegex matches the statistics logged by the fuzzing coordinator, such as
+// "fuzz: elapsed: 3s, execs: 1234 (411/sec), new interesting: 3 (total: 10)".
+var fuzzStatsRegex = regexp.MustCompile(`^fuzz: elapsed: \S+, execs: (\d+) \([^)]*\)(?:, new interesting: (\d+))?`)
+
+// fuzzStats holds the statistics of a fuzzing run.
+type fuzzStats struct {
+	iterations     int64
+	newInteresting int64
+}
+
+// isFuzzWorker returns true when the process is a fuzzing worker, started by the fuzzing coordinator
+// to run the fuzz target with the generated inputs. Only the coordinator is reported to CI visibility.
+func isFuzzWorker() bool {
+	for _, v := range os.Args {
+		if strings.Contains(v, "test.fuzzworker") {
+			return true
+		}
+	}
+	return false
+}
+
+// captureFuzzStats collects the statistics logged by the fuzzing coordinator to the standard error,
+// which still receives them. The returned function stops the collection and returns the statistics.
+func captureFuzzStats() func() fuzzStats {
+	reader, writer, err := os.Pipe()
+	if err != nil {
+		return func() fuzzStats { return fuzzStats{} }
+	}
+	stderr := os.Stderr
+	os.Stderr = writer
+
+	var stats fuzzStats
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		scanner := bufio.NewScanner(io.TeeReader(reader, stderr))
+		for scanner.Scan() {
+			parseFuzzStats(scanner.Text(), &stats)
+		}
+		// Keep forwarding the output if a line was too long to be scanned.
+		_, _ = io.Copy(io.Discard, io.TeeReader(reader, stderr))
+	}()
+
+	return func() fuzzStats {
+		os.Stderr = stderr
+		_ = writer.Close()
+		<-done
+		_ = reader.Close()
+		return stats
+	}
+}
+
+// parseFuzzStats updates the statistics from a line logged by the fuzzing coordinator.
+func parseFuzzStats(line string, stats *fuzzStats) {
+	matches := fuzzStatsRegex.FindStringSubmatch(line)
+	if matches == nil {
+		return
+	}
+	stats.iterations, _ = strconv.ParseInt(matches[1], 10, 64)
+	if matches[2] != "" {
+		stats.newInteresting, _ = strconv.ParseInt(matches[2], 10, 64)
+	}
+}
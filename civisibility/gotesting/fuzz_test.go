@@ -0,0 +1,28 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2024 Datadog, Inc.
+
+package gotesting
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseFuzzStats(t *testing.T) {
+	var stats fuzzStats
+	parseFuzzStats("fuzz: elapsed: 0s, gathering baseline coverage: 0/2 completed", &stats)
+	assert.Equal(t, fuzzStats{}, stats)
+
+	parseFuzzStats("fuzz: elapsed: 3s, execs: 1234 (411/sec), new interesting: 3 (total: 5)", &stats)
+	assert.Equal(t, fuzzStats{iterations: 1234, newInteresting: 3}, stats)
+
+	// Without coverage instrumentation
+	parseFuzzStats("fuzz: elapsed: 6s, execs: 2468 (411/sec)", &stats)
+	assert.Equal(t, fuzzStats{iterations: 2468, newInteresting: 3}, stats)
+
+	parseFuzzStats("PASS", &stats)
+	assert.Equal(t, fuzzStats{iterations: 2468, newInteresting: 3}, stats)
+}
@@ -0,0 +1,128 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2024 Datadog, Inc.
+
+package gotesting
+
+import (
+	"reflect"
+	"runtime"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"gopkg.in/DataDog/dd-trace-go.v1/civisibility"
+	internal "gopkg.in/DataDog/dd-trace-go.v1/internal/civisibility"
+	"gopkg.in/DataDog/dd-trace-go.v1/internal/civisibility/constants"
+)
+
+// skippedByItrReason is the reason of the tests skipped by the Intelligent Test Runner.
+const skippedByItrReason = "Skipped by Datadog Intelligent Test Runner"
+
+var (
+	// testsSkippingEnabled indicates if the Intelligent Test Runner skips the skippable tests.
+	testsSkippingEnabled bool
+
+	// itrCorrelationID is the correlation ID of the skippable tests, reported with every test.
+	itrCorrelationID string
+
+	// skippedTestsCount is the number of tests skipped by the Intelligent Test Runner in the session.
+	skippedTestsCount int64
+
+	// modulesSkippedCounters keeps track of the number of tests skipped by the Intelligent Test Runner per module.
+	modulesSkippedCounters = map[string]*int64{}
+
+	// unskippableTests holds the entry points of the test functions marked as unskippable.
+	unskippableTests = map[uintptr]struct{}{}
+
+	// unskippableTestsMutex synchronizes access to unskippableTests.
+	unskippableTestsMutex sync.RWMutex
+)
+
+// MarkUnskippable marks the given test functions as unskippable: the Intelligent Test Runner always runs
+// them, even when they aren't affected by the changes of the commit (e.g. tests depending on files that
+// aren't Go code). It must be called before RunM, typically in TestMain.
+func MarkUnskippable(tests ...func(*testing.T)) {
+	unskippableTestsMutex.Lock()
+	defer unskippableTestsMutex.Unlock()
+	for _, test := range tests {
+		if test != nil {
+			unskippableTests[reflect.ValueOf(test).Pointer()] = struct{}{}
+		}
+	}
+}
+
+// isUnskippable returns true when the test function is marked as unskippable.
+func isUnskippable(testFunc *runtime.Func) bool {
+	if testFunc == nil {
+		return false
+	}
+	unskippableTestsMutex.RLock()
+	defer unskippableTestsMutex.RUnlock()
+	_, ok := unskippableTests[testFunc.Entry()]
+	return ok
+}
+
+// initIntelligentTestRunner marks the tests that the Intelligent Test Runner can skip, as they aren't
+// affected by the changes of the commit, unless they are marked as unskippable.
+func initIntelligentTestRunner(tests []*testingTInfo) {
+	skippableTests := internal.GetSkippableTests()
+	if !internal.GetSettings().TestsSkipping || skippableTests == nil {
+		return
+	}
+
+	testsSkippingEnabled = true
+	itrCorrelationID = skippableTests.CorrelationID
+	for _, test := range tests {
+		if _, ok := modulesSkippedCounters[test.moduleName]; !ok {
+			var v int64 = 0
+			modulesSkippedCounters[test.moduleName] = &v
+		}
+		if skippableTests.Contains(test.moduleName, test.suiteName, test.testName) {
+			test.skippedByItr = !isUnskippable(runtime.FuncForPC(reflect.ValueOf(test.originalFunc).Pointer()))
+		}
+	}
+}
+
+// skipTestWithItr reports a test skipped by the Intelligent Test Runner and skips it.
+func skipTestWithItr(t *testing.T, module civisibility.DdTestModule, suite civisibility.DdTestSuite, testName string, testFunc *runtime.Func) {
+	test := createTest(suite, testName, testFunc)
+	test.SetTag(constants.TestSkippedByItrTagName, "true")
+	test.CloseWithFinishTimeAndSkipReason(civisibility.ResultStatusSkip, time.Now(), skippedByItrReason)
+	setTestsSkippingTags(module, atomic.AddInt64(modulesSkippedCounters[module.Name()], 1))
+	atomic.AddInt64(&skippedTestsCount, 1)
+	checkModuleAndSuite(module, suite)
+	t.Skip(skippedByItrReason)
+}
+
+// setItrTestTags sets the Intelligent Test Runner tags of a test and its suite.
+func setItrTestTags(test civisibility.DdTest, suite civisibility.DdTestSuite, testName string, testFunc *runtime.Func) {
+	if !testsSkippingEnabled {
+		return
+	}
+	if itrCorrelationID != "" {
+		test.SetTag(constants.ItrCorrelationIdTagName, itrCorrelationID)
+	}
+	if isUnskippable(testFunc) {
+		test.SetTag(constants.TestUnskippableTagName, "true")
+		suite.SetTag(constants.TestUnskippableTagName, "true")
+		if internal.GetSkippableTests().Contains(suite.Module().Name(), suite.Name(), testName) {
+			test.SetTag(constants.TestForcedToRunTagName, "true")
+			suite.SetTag(constants.TestForcedToRunTagName, "true")
+		}
+	}
+}
+
+// setTestsSkippingTags sets the tests skipping tags of the test session or a module, with its number of skipped tests.
+func setTestsSkippingTags(event interface{ SetTag(string, interface{}) }, skipped int64) {
+	event.SetTag(constants.TestsSkippingEnabledTagName, "true")
+	event.SetTag(constants.TestsSkippingTypeTagName, constants.TestTypeTest)
+	event.SetTag(constants.TestsSkippingCountTagName, skipped)
+	if skipped > 0 {
+		event.SetTag(constants.ItrTestsSkippedTagName, "true")
+	} else {
+		event.SetTag(constants.ItrTestsSkippedTagName, "false")
+	}
+}
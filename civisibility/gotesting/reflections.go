@@ -10,6 +10,7 @@ import (
 	"reflect"
 	"sync"
 	"testing"
+	"time"
 	"unsafe"
 )
 
@@ -48,6 +49,45 @@ func getInternalBenchmarkArray(m *testing.M) *[]testing.InternalBenchmark {
 	return nil
 }
 
+// FUZZ TESTS
+
+// getInternalFuzzTargetArray gets the pointer to the testing.InternalFuzzTarget array inside
+// a testing.M instance containing all the fuzz tests
+func getInternalFuzzTargetArray(m *testing.M) *[]testing.InternalFuzzTarget {
+	if ptr, err := getFieldPointerFrom(m, "fuzzTargets"); err == nil {
+		return (*[]testing.InternalFuzzTarget)(ptr)
+	}
+	return nil
+}
+
+// fuzzResult mirrors the private testing.fuzzResult type, the result of a fuzzing run
+type fuzzResult struct {
+	N     int           // The number of iterations.
+	T     time.Duration // The total time taken.
+	Error error         // Error is the error from the failing input
+}
+
+// fuzzModeCoordinator is the value of the private testing.fuzzMode of the process coordinating the fuzzing
+const fuzzModeCoordinator = 1
+
+// isFuzzing returns true when the testing.F is fuzzing, instead of only running its seed corpus
+func isFuzzing(f *testing.F) bool {
+	fuzzContext := reflect.ValueOf(f).Elem().FieldByName("fuzzContext")
+	if !fuzzContext.IsValid() || fuzzContext.IsNil() {
+		return false
+	}
+	mode := fuzzContext.Elem().FieldByName("mode")
+	return mode.IsValid() && mode.Uint() == fuzzModeCoordinator
+}
+
+// getFuzzResult gets the pointer to the result of the fuzzing run of a testing.F
+func getFuzzResult(f *testing.F) *fuzzResult {
+	if ptr, err := getFieldPointerFrom(f, "result"); err == nil {
+		return (*fuzzResult)(ptr)
+	}
+	return nil
+}
+
 // commonPrivateFields is collection of required private fields from testing.common
 type commonPrivateFields struct {
 	mu    *sync.RWMutex
@@ -101,3 +141,141 @@ func getBenchmarkPrivateFields(b *testing.B) *benchmarkPrivateFields {
 
 	return benchFields
 }
+
+// TESTS PRIVATE FIELDS
+
+// testPrivateFields is a collection of required private fields from testing.T
+type testPrivateFields struct {
+	mu       *sync.RWMutex
+	output   *[]byte
+	cleanups *[]func()
+	parent   *unsafe.Pointer // Pointer to the testing.common of the parent test.
+	barrier  *chan bool      // To signal parallel subtests they may start.
+	sub      *[]*testing.T   // Queue of parallel subtests to be run.
+	signal   *chan bool      // To signal a test is done.
+}
+
+// getTestPrivateFields is a method to retrieve all required privates field from
+// testing.T, returning a testPrivateFields instance
+func getTestPrivateFields(t *testing.T) *testPrivateFields {
+	testFields := &testPrivateFields{}
+	if ptr, err := getFieldPointerFrom(t, "mu"); err == nil {
+		testFields.mu = (*sync.RWMutex)(ptr)
+	}
+	if ptr, err := getFieldPointerFrom(t, "output"); err == nil {
+		testFields.output = (*[]byte)(ptr)
+	}
+	if ptr, err := getFieldPointerFrom(t, "cleanups"); err == nil {
+		testFields.cleanups = (*[]func())(ptr)
+	}
+	if ptr, err := getFieldPointerFrom(t, "parent"); err == nil {
+		testFields.parent = (*unsafe.Pointer)(ptr)
+	}
+	if ptr, err := getFieldPointerFrom(t, "barrier"); err == nil {
+		testFields.barrier = (*chan bool)(ptr)
+	}
+	if ptr, err := getFieldPointerFrom(t, "sub"); err == nil {
+		testFields.sub = (*[]*testing.T)(ptr)
+	}
+	if ptr, err := getFieldPointerFrom(t, "signal"); err == nil {
+		testFields.signal = (*chan bool)(ptr)
+	}
+	return testFields
+}
+
+// isComplete returns true when all the private fields were found.
+func (f *testPrivateFields) isComplete() bool {
+	return f.mu != nil && f.output != nil && f.cleanups != nil && f.parent != nil &&
+		f.barrier != nil && f.sub != nil && f.signal != nil
+}
+
+// testContextPrivateFields is a collection of required private fields from the testing.testContext
+// shared by the tests, which limits the number of tests running in parallel
+type testContextPrivateFields struct {
+	mu            *sync.Mutex
+	startParallel *chan bool
+	running       *int // Number of tests currently running in parallel.
+	numWaiting    *int // Number of tests waiting to be run in parallel.
+	maxParallel   *int
+}
+
+// getTestContextPrivateFields is a method to retrieve all required private fields from the
+// testing.testContext of t, returning nil if they can't be found
+func getTestContextPrivateFields(t *testing.T) *testContextPrivateFields {
+	context := reflect.Indirect(reflect.ValueOf(t)).FieldByName("context")
+	if !context.IsValid() || context.IsNil() {
+		return nil
+	}
+	fields := []string{"mu", "startParallel", "running", "numWaiting", "maxParallel"}
+	ptrs := make([]unsafe.Pointer, len(fields))
+	for i, name := range fields {
+		field := context.Elem().FieldByName(name)
+		if !field.IsValid() {
+			return nil
+		}
+		ptrs[i] = unsafe.Pointer(field.UnsafeAddr())
+	}
+	return &testContextPrivateFields{
+		mu:            (*sync.Mutex)(ptrs[0]),
+		startParallel: (*chan bool)(ptrs[1]),
+		running:       (*int)(ptrs[2]),
+		numWaiting:    (*int)(ptrs[3]),
+		maxParallel:   (*int)(ptrs[4]),
+	}
+}
+
+// waitParallel mirrors testing.testContext.waitParallel, waiting for a test to be allowed to run
+func (c *testContextPrivateFields) waitParallel() {
+	c.mu.Lock()
+	if *c.running < *c.maxParallel {
+		*c.running++
+		c.mu.Unlock()
+		return
+	}
+	*c.numWaiting++
+	c.mu.Unlock()
+	<-*c.startParallel
+}
+
+// release mirrors testing.testContext.release, letting a waiting test run
+func (c *testContextPrivateFields) release() {
+	c.mu.Lock()
+	if *c.numWaiting == 0 {
+		*c.running--
+		c.mu.Unlock()
+		return
+	}
+	*c.numWaiting--
+	c.mu.Unlock()
+	*c.startParallel <- true
+}
+
+// getTestMatcherSubNames gets the mutex and the map used by the testing.matcher of
+// the test context to deduplicate the subtest names, or nils if they can't be found.
+func getTestMatcherSubNames(t *testing.T) (*sync.Mutex, *map[string]int32) {
+	context := reflect.Indirect(reflect.ValueOf(t)).FieldByName("context")
+	if !context.IsValid() || context.IsNil() {
+		return nil, nil
+	}
+	match := context.Elem().FieldByName("match")
+	if !match.IsValid() || match.IsNil() {
+		return nil, nil
+	}
+	mu := match.Elem().FieldByName("mu")
+	subNames := match.Elem().FieldByName("subNames")
+	if !mu.IsValid() || !subNames.IsValid() {
+		return nil, nil
+	}
+	return (*sync.Mutex)(unsafe.Pointer(mu.UnsafeAddr())), (*map[string]int32)(unsafe.Pointer(subNames.UnsafeAddr()))
+}
+
+// copyTest copies the testing.T src, including its private fields, to dst
+func copyTest(dst, src *testing.T) {
+	srcFields := getTestPrivateFields(src)
+	srcFields.mu.RLock()
+	reflect.ValueOf(dst).Elem().Set(reflect.ValueOf(src).Elem())
+	srcFields.mu.RUnlock()
+
+	// The copied mutex is read locked, reset it
+	*getTestPrivateFields(dst).mu = sync.RWMutex{}
+}
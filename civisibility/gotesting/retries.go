@@ -0,0 +1,286 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2024 Datadog, Inc.
+
+package gotesting
+
+import (
+	"reflect"
+	"runtime"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+	"unsafe"
+
+	"gopkg.in/DataDog/dd-trace-go.v1/civisibility"
+	"gopkg.in/DataDog/dd-trace-go.v1/ddtrace/ext"
+	globalinternal "gopkg.in/DataDog/dd-trace-go.v1/internal"
+	"gopkg.in/DataDog/dd-trace-go.v1/internal/civisibility/constants"
+	"gopkg.in/DataDog/dd-trace-go.v1/internal/civisibility/utils"
+	logger "gopkg.in/DataDog/dd-trace-go.v1/internal/log"
+)
+
+const (
+	// defaultFlakyRetryCount is the default maximum number of retries of a failed test.
+	defaultFlakyRetryCount = 5
+
+	// defaultTotalFlakyRetryCount is the default maximum number of retries in the whole session.
+	defaultTotalFlakyRetryCount = 1000
+)
+
+var (
+	// flakyRetriesEnabled indicates if the failed tests are retried (Automatic Test Retries).
+	flakyRetriesEnabled bool
+
+	// flakyRetryCount is the maximum number of retries of a failed test.
+	flakyRetryCount int64
+
+	// flakyRetriesRemaining is the number of retries left in the whole session.
+	flakyRetriesRemaining int64
+)
+
+type (
+	// testExecution holds the outcome of an isolated execution of a test function.
+	testExecution struct {
+		failed      bool
+		skipped     bool
+		skipReason  string
+		panicData   any
+		panicStack  string
+		panicOutput string
+		duration    time.Duration
+	}
+)
+
+// initFlakyRetries loads the Automatic Test Retries settings from the environment.
+func initFlakyRetries() {
+	flakyRetriesEnabled = globalinternal.BoolEnv(constants.CiVisibilityFlakyRetryEnabledEnvironmentVariable, false)
+	if !flakyRetriesEnabled {
+		return
+	}
+
+	// Retried tests are run in isolation from the testing framework.
+	if !canIsolateTests() {
+		logger.Warn("Automatic Test Retries are not supported with %s, failed tests won't be retried.", runtime.Version())
+		flakyRetriesEnabled = false
+		return
+	}
+
+	flakyRetryCount = int64(globalinternal.IntEnv(constants.CiVisibilityFlakyRetryCountEnvironmentVariable, defaultFlakyRetryCount))
+	atomic.StoreInt64(&flakyRetriesRemaining, int64(globalinternal.IntEnv(constants.CiVisibilityTotalFlakyRetryCountEnvironmentVariable, defaultTotalFlakyRetryCount)))
+}
+
+// canIsolateTests returns true when the private fields of testing.T required to run a test in
+// isolation are available in this Go version.
+func canIsolateTests() bool {
+	if !getTestPrivateFields(&testing.T{}).isComplete() {
+		return false
+	}
+	// The test context is only set on running tests, check its type instead.
+	context, ok := reflect.TypeOf(testing.T{}).FieldByName("context")
+	if !ok || context.Type.Kind() != reflect.Pointer {
+		return false
+	}
+	for _, name := range []string{"mu", "startParallel", "running", "numWaiting", "maxParallel"} {
+		if _, ok := context.Type.Elem().FieldByName(name); !ok {
+			return false
+		}
+	}
+	return true
+}
+
+// reserveFlakyRetry takes a retry from the session budget, returning false when it's exhausted.
+func reserveFlakyRetry() bool {
+	return atomic.AddInt64(&flakyRetriesRemaining, -1) >= 0
+}
+
+// runTestWithRetries runs the test function f in isolation from t, retrying it while it fails and
+// there are retries left, or, for a new test, as many times as Early Flake Detection requires. Every
+// execution is reported as its own test, and t only fails if all its executions failed.
+func runTestWithRetries(t *testing.T, module civisibility.DdTestModule, suite civisibility.DdTestSuite, testName string, testFunc *runtime.Func, f func(*testing.T), isNew bool) {
+	var execution testExecution
+	var newTestRetries int64
+	allFailed := true
+	for retry := int64(0); ; retry++ {
+		if retry > 0 {
+			// Let the subtests of the retry keep the names of the previous execution.
+			clearSubtestNames(t)
+		}
+
+		test := createTest(suite, testName, testFunc)
+		if retry > 0 {
+			test.SetTag(constants.TestIsRetry, "true")
+		}
+		if isNew {
+			test.SetTag(constants.TestIsNew, "true")
+		}
+		execution = runIsolatedExecution(t, test, f)
+		if execution.panicData != nil {
+			closeTestWithPanic(module, suite, test, execution.panicData, execution.panicStack)
+			panic(execution.panicData)
+		}
+
+		if execution.failed {
+			test.SetTag(ext.Error, true)
+			test.Close(civisibility.ResultStatusFail)
+		} else if execution.skipped {
+			test.CloseWithFinishTimeAndSkipReason(civisibility.ResultStatusSkip, time.Now(), execution.skipReason)
+		} else {
+			test.Close(civisibility.ResultStatusPass)
+		}
+
+		allFailed = allFailed && execution.failed
+
+		if isNew {
+			// Early Flake Detection: the number of retries depends on the duration of the first execution.
+			if retry == 0 && !execution.skipped {
+				newTestRetries = earlyFlakeDetectionRetryCount(execution.duration)
+			}
+			if retry >= newTestRetries {
+				break
+			}
+			continue
+		}
+
+		// Automatic Test Retries
+		if !execution.failed || !flakyRetriesEnabled || retry >= flakyRetryCount || !reserveFlakyRetry() {
+			break
+		}
+		t.Logf("Automatic Test Retries: retrying failed test (retry %d of %d)", retry+1, flakyRetryCount)
+	}
+
+	if allFailed {
+		suite.SetTag(ext.Error, true)
+		module.SetTag(ext.Error, true)
+	}
+	checkModuleAndSuite(module, suite)
+
+	// Report the outcome to the testing framework.
+	if allFailed {
+		t.Fail()
+	} else if execution.skipped {
+		t.SkipNow()
+	}
+}
+
+// runIsolatedExecution runs the test function f on a copy of t detached from its parent, so a failure
+// doesn't propagate to the parent tests, and returns the outcome of the execution. The output of the
+// execution is appended to the output of t.
+//
+// The copy has no parallel barrier to wait for, so calling Parallel on it has no effect. It has its own
+// barrier for its parallel subtests though, released once f returns, like tRunner does, so that their
+// outcome is part of the execution.
+func runIsolatedExecution(t *testing.T, test civisibility.DdTest, f func(*testing.T)) testExecution {
+	localT := &testing.T{}
+	copyTest(localT, t)
+	localFields := getTestPrivateFields(localT)
+	*localFields.output = nil
+	*localFields.cleanups = nil
+	*localFields.parent = unsafe.Pointer(&testing.T{})
+	*localFields.barrier = make(chan bool)
+	*localFields.sub = nil
+	setCiVisibilityTest(localT, test)
+
+	// Run the test function in its own goroutine, like tRunner does, so FailNow and SkipNow only end the execution.
+	var execution testExecution
+	done := make(chan struct{})
+	collectCoverage := startTestCoverage(test)
+	startTime := time.Now()
+	endTestContext := startTestContext(t, test)
+	go func() {
+		defer close(done)
+		defer func() {
+			if r := recover(); r != nil {
+				execution.panicData = r
+				execution.panicStack = utils.GetStacktrace(1)
+				execution.panicOutput = getPanicOutput(r)
+			}
+		}()
+		defer runTestCleanups(localFields)
+		defer runParallelSubtests(localT, localFields)
+		f(localT)
+	}()
+	<-done
+	endTestContext()
+
+	execution.duration = time.Since(startTime)
+	if execution.panicData == nil {
+		collectCoverage()
+	}
+	execution.failed = localT.Failed()
+	execution.skipped = localT.Skipped()
+	if execution.skipped {
+		execution.skipReason = getSkipReason(localT)
+	}
+	reportTestOutput(localT, test, execution.failed || execution.panicData != nil, execution.panicOutput)
+
+	// Keep the output of the execution.
+	localFields.mu.RLock()
+	output := *localFields.output
+	localFields.mu.RUnlock()
+	if len(output) > 0 {
+		fields := getTestPrivateFields(t)
+		fields.mu.Lock()
+		*fields.output = append(*fields.output, output...)
+		fields.mu.Unlock()
+	}
+
+	return execution
+}
+
+// runParallelSubtests releases the parallel subtests of an isolated execution and waits for them to
+// complete, like tRunner does once the test function returns.
+func runParallelSubtests(localT *testing.T, fields *testPrivateFields) {
+	if len(*fields.sub) == 0 {
+		return
+	}
+	// The test doesn't count as running while waiting for its subtests.
+	context := getTestContextPrivateFields(localT)
+	if context != nil {
+		context.release()
+	}
+	close(*fields.barrier)
+	for _, sub := range *fields.sub {
+		<-*getTestPrivateFields(sub).signal
+	}
+	if context != nil {
+		context.waitParallel()
+	}
+}
+
+// runTestCleanups calls the cleanup functions registered by an isolated execution, in last added,
+// first called order.
+func runTestCleanups(fields *testPrivateFields) {
+	for {
+		fields.mu.Lock()
+		var cleanup func()
+		if n := len(*fields.cleanups); n > 0 {
+			cleanup = (*fields.cleanups)[n-1]
+			*fields.cleanups = (*fields.cleanups)[:n-1]
+		}
+		fields.mu.Unlock()
+		if cleanup == nil {
+			return
+		}
+		cleanup()
+	}
+}
+
+// clearSubtestNames forgets the subtest names of t seen by the test matcher, which would otherwise
+// suffix the subtests of a retry with #01, #02...
+func clearSubtestNames(t *testing.T) {
+	mu, subNames := getTestMatcherSubNames(t)
+	if mu == nil {
+		return
+	}
+	prefix := t.Name() + "/"
+	mu.Lock()
+	defer mu.Unlock()
+	for name := range *subNames {
+		if strings.HasPrefix(name, prefix) {
+			delete(*subNames, name)
+		}
+	}
+}
@@ -0,0 +1,200 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2024 Datadog, Inc.
+
+package gotesting
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+
+	"gopkg.in/DataDog/dd-trace-go.v1/civisibility"
+	ddtracer "gopkg.in/DataDog/dd-trace-go.v1/ddtrace/tracer"
+	internal "gopkg.in/DataDog/dd-trace-go.v1/internal/civisibility"
+	"gopkg.in/DataDog/dd-trace-go.v1/internal/civisibility/coverage"
+	"gopkg.in/DataDog/dd-trace-go.v1/internal/civisibility/utils"
+	logger "gopkg.in/DataDog/dd-trace-go.v1/internal/log"
+)
+
+var (
+	// testCoverageMeta maps the coverage counters to the code blocks, and is only set when the code coverage of
+	// every test is collected.
+	testCoverageMeta *coverageMeta
+
+	// testCoverageBuffers holds the buffers the coverage counters are written to.
+	testCoverageBuffers = sync.Pool{New: func() any { return new(bytes.Buffer) }}
+
+	// modulePath and moduleDir are the path and the directory of the Go module being tested,
+	// used to resolve the files of the coverage profiles.
+	modulePath, moduleDir string
+)
+
+// coverageCounters holds the coverage counters of the executed blocks ("file.go:line.col,line.col").
+type coverageCounters map[string]uint64
+
+// initTestCoverage enables the collection of the code coverage of every test when the CI Visibility
+// settings require it. The coverage of a test is the difference of the coverage counters before and
+// after its execution, read in memory from the runtime, so it requires the tests to be run with
+// -covermode=atomic (the default cover mode with -race), and includes the code executed by the tests
+// running in parallel.
+func initTestCoverage() {
+	if !internal.GetSettings().CodeCoverage || !coverage.Enabled() {
+		return
+	}
+	if mode := testing.CoverMode(); mode != "atomic" {
+		if mode == "" {
+			mode = "none"
+		}
+		logger.Warn("Per-test code coverage requires -covermode=atomic, got %s: the code coverage of the tests won't be reported.", mode)
+		return
+	}
+	buf := new(bytes.Buffer)
+	if err := writeCoverageMeta(buf); err != nil {
+		logger.Warn("Error getting the coverage meta-data: %s", err)
+		return
+	}
+	meta, err := decodeCoverageMeta(buf.Bytes())
+	if err != nil {
+		logger.Warn("Error decoding the coverage meta-data: %s", err)
+		return
+	}
+	modulePath, moduleDir = getModule()
+	testCoverageMeta = meta
+}
+
+// startTestCoverage snapshots the coverage counters before the execution of a test, returning the function
+// collecting the code executed by the test and sending it once the execution is over.
+func startTestCoverage(test civisibility.DdTest) func() {
+	if testCoverageMeta == nil {
+		return func() {}
+	}
+	before, err := getCoverageCounters()
+	if err != nil {
+		logger.Debug("Error getting the coverage counters: %s", err)
+		return func() {}
+	}
+	return func() {
+		after, err := getCoverageCounters()
+		if err != nil {
+			logger.Debug("Error getting the coverage counters: %s", err)
+			return
+		}
+		suite := test.Suite()
+		coverage.Write(&coverage.TestCoverage{
+			SessionID: spanIDFromContext(suite.Module().Session().Context()),
+			SuiteID:   spanIDFromContext(suite.Context()),
+			SpanID:    spanIDFromContext(test.Context()),
+			Files:     getExecutedFiles(before, after),
+		})
+	}
+}
+
+// getCoverageCounters returns a snapshot of the coverage counters of the executed blocks.
+func getCoverageCounters() (coverageCounters, error) {
+	buf := testCoverageBuffers.Get().(*bytes.Buffer)
+	defer testCoverageBuffers.Put(buf)
+	buf.Reset()
+	if err := writeCoverageCounters(buf); err != nil {
+		return nil, err
+	}
+	return testCoverageMeta.decodeCounters(buf.Bytes())
+}
+
+// getExecutedFiles returns the coverage of the files whose blocks were executed between the
+// before and after counters, sorted by file name.
+func getExecutedFiles(before, after coverageCounters) []*coverage.FileCoverage {
+	bitmaps := map[string]*coverage.Bitmap{}
+	for block, count := range after {
+		if count <= before[block] {
+			continue
+		}
+		file, startLine, endLine, ok := parseCoverageBlock(block)
+		if !ok {
+			continue
+		}
+		bitmap := bitmaps[file]
+		if bitmap == nil {
+			bitmap = &coverage.Bitmap{}
+			bitmaps[file] = bitmap
+		}
+		for line := startLine; line <= endLine; line++ {
+			bitmap.Set(line)
+		}
+	}
+
+	files := make([]*coverage.FileCoverage, 0, len(bitmaps))
+	for file, bitmap := range bitmaps {
+		files = append(files, &coverage.FileCoverage{Filename: getCoverageFilePath(file), Bitmap: *bitmap})
+	}
+	sort.Slice(files, func(i, j int) bool { return files[i].Filename < files[j].Filename })
+	return files
+}
+
+// parseCoverageBlock parses a block of a coverage profile ("file.go:line.col,line.col").
+func parseCoverageBlock(block string) (file string, startLine int, endLine int, ok bool) {
+	colon := strings.LastIndexByte(block, ':')
+	if colon < 0 {
+		return "", 0, 0, false
+	}
+	start, end, found := strings.Cut(block[colon+1:], ",")
+	if !found {
+		return "", 0, 0, false
+	}
+	startLine, err := strconv.Atoi(strings.SplitN(start, ".", 2)[0])
+	if err != nil {
+		return "", 0, 0, false
+	}
+	endLine, err = strconv.Atoi(strings.SplitN(end, ".", 2)[0])
+	if err != nil {
+		return "", 0, 0, false
+	}
+	return block[:colon], startLine, endLine, true
+}
+
+// getCoverageFilePath returns the path relative to the repository root of a file of a coverage profile,
+// named after its package import path, or the file name as is when it's not in the tested module.
+func getCoverageFilePath(file string) string {
+	if modulePath == "" || !strings.HasPrefix(file, modulePath+"/") {
+		return file
+	}
+	return utils.GetRelativePathFromCiTagsSourceRoot(filepath.Join(moduleDir, strings.TrimPrefix(file, modulePath+"/")))
+}
+
+// getModule returns the path and the directory of the Go module containing the working directory.
+func getModule() (path string, dir string) {
+	dir, err := os.Getwd()
+	if err != nil {
+		return "", ""
+	}
+	for {
+		if content, err := os.ReadFile(filepath.Join(dir, "go.mod")); err == nil {
+			for _, line := range strings.Split(string(content), "\n") {
+				if fields := strings.Fields(line); len(fields) == 2 && fields[0] == "module" {
+					return strings.Trim(fields[1], `"`), dir
+				}
+			}
+			return "", ""
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return "", ""
+		}
+		dir = parent
+	}
+}
+
+// spanIDFromContext returns the ID of the span of the given context, or 0.
+func spanIDFromContext(ctx context.Context) uint64 {
+	if span, ok := ddtracer.SpanFromContext(ctx); ok {
+		return span.Context().SpanID()
+	}
+	return 0
+}
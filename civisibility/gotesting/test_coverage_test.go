@@ -0,0 +1,149 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2024 Datadog, Inc.
+
+package gotesting
+
+import (
+	"bytes"
+	"encoding/binary"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseCoverageBlock(t *testing.T) {
+	file, startLine, endLine, ok := parseCoverageBlock("example.com/pkg/a.go:3.10,5.2")
+	assert.True(t, ok)
+	assert.Equal(t, "example.com/pkg/a.go", file)
+	assert.Equal(t, 3, startLine)
+	assert.Equal(t, 5, endLine)
+
+	_, _, _, ok = parseCoverageBlock("example.com/pkg/a.go")
+	assert.False(t, ok)
+	_, _, _, ok = parseCoverageBlock("example.com/pkg/a.go:3.10")
+	assert.False(t, ok)
+}
+
+func TestGetExecutedFiles(t *testing.T) {
+	before := coverageCounters{
+		"example.com/pkg/a.go:3.10,5.2":  1,
+		"example.com/pkg/a.go:9.10,10.2": 2,
+		"example.com/pkg/b.go:1.1,2.2":   4,
+	}
+	after := coverageCounters{
+		"example.com/pkg/a.go:3.10,5.2":  2,
+		"example.com/pkg/a.go:9.10,10.2": 2,
+		"example.com/pkg/b.go:1.1,2.2":   4,
+		"example.com/pkg/c.go:8.1,8.20":  1,
+	}
+
+	files := getExecutedFiles(before, after)
+	require.Len(t, files, 2)
+	assert.Equal(t, "example.com/pkg/a.go", files[0].Filename)
+	for line := 0; line < 16; line++ {
+		assert.Equal(t, line >= 3 && line <= 5, files[0].Bitmap.IsSet(line), "line %d", line)
+	}
+	assert.Equal(t, "example.com/pkg/c.go", files[1].Filename)
+	assert.True(t, files[1].Bitmap.IsSet(8))
+	assert.False(t, files[1].Bitmap.IsSet(7))
+}
+
+func TestGetCoverageCounters(t *testing.T) {
+	if testing.CoverMode() != "atomic" {
+		t.Skip("requires the atomic cover mode")
+	}
+	buf := new(bytes.Buffer)
+	require.NoError(t, writeCoverageMeta(buf))
+	meta, err := decodeCoverageMeta(buf.Bytes())
+	require.NoError(t, err)
+	prevMeta := testCoverageMeta
+	testCoverageMeta = meta
+	defer func() { testCoverageMeta = prevMeta }()
+
+	before, err := getCoverageCounters()
+	require.NoError(t, err)
+	_, _, _, _ = parseCoverageBlock("example.com/pkg/a.go:3.10,5.2")
+	after, err := getCoverageCounters()
+	require.NoError(t, err)
+
+	files := getExecutedFiles(before, after)
+	var found bool
+	for _, file := range files {
+		found = found || strings.HasSuffix(file.Filename, "test_coverage.go")
+	}
+	assert.True(t, found, "test_coverage.go should be executed")
+}
+
+func TestDecodeCoverageData(t *testing.T) {
+	uleb := func(b []byte, values ...uint64) []byte {
+		for _, v := range values {
+			for ; v >= 0x80; v >>= 7 {
+				b = append(b, byte(v)|0x80)
+			}
+			b = append(b, byte(v))
+		}
+		return b
+	}
+
+	// Meta-data of a package with a function of two blocks in example.com/pkg/a.go
+	pkg := make([]byte, 48)
+	binary.LittleEndian.PutUint32(pkg[40:], 1) // number of functions
+	binary.LittleEndian.PutUint32(pkg[44:], 0) // offset of the function, set below
+	pkg = uleb(pkg, 2)
+	pkg = append(uleb(pkg, 4), "func"...)
+	pkg = append(uleb(pkg, 20), "example.com/pkg/a.go"...)
+	binary.LittleEndian.PutUint32(pkg[44:], uint32(len(pkg)))
+	pkg = uleb(pkg, 2, 0, 1, 3, 10, 5, 2, 2, 7, 10, 8, 2, 1, 0)
+	metaData := make([]byte, coverageMetaFileHeaderSize+16)
+	copy(metaData, coverageMetaMagic[:])
+	binary.LittleEndian.PutUint32(metaData[4:], 1)  // version
+	binary.LittleEndian.PutUint64(metaData[16:], 1) // number of packages
+	metaData[49] = 1                                // per-block granularity
+	binary.LittleEndian.PutUint64(metaData[56:], uint64(len(metaData)))
+	binary.LittleEndian.PutUint64(metaData[64:], uint64(len(pkg)))
+	metaData = append(metaData, pkg...)
+
+	meta, err := decodeCoverageMeta(metaData)
+	require.NoError(t, err)
+	assert.Equal(t, [][][]string{{{"example.com/pkg/a.go:3.10,5.2", "example.com/pkg/a.go:7.10,8.2"}}}, meta.blocks)
+
+	// Counter data with a single segment, made of an empty string table and arguments
+	counterData := make([]byte, coverageCounterFileHeaderSize+coverageCounterSegmentHeaderSize+2)
+	copy(counterData, coverageCounterMagic[:])
+	binary.LittleEndian.PutUint32(counterData[4:], 1) // version
+	counterData[24] = coverageFlavorULEB128
+	binary.LittleEndian.PutUint64(counterData[32:], 1) // number of functions
+	binary.LittleEndian.PutUint32(counterData[40:], 1) // string table length
+	binary.LittleEndian.PutUint32(counterData[44:], 1) // arguments length
+	counterData = append(counterData, 0, 0)            // padding
+	segment := counterData[:len(counterData):len(counterData)]
+	counterData = uleb(segment, 2, 0, 0, 0, 300)
+
+	counters, err := meta.decodeCounters(counterData)
+	require.NoError(t, err)
+	assert.Equal(t, coverageCounters{"example.com/pkg/a.go:7.10,8.2": 300}, counters)
+
+	t.Run("per-function", func(t *testing.T) {
+		meta := &coverageMeta{perFunc: true, blocks: meta.blocks}
+		counters, err := meta.decodeCounters(uleb(segment, 1, 0, 0, 4))
+		require.NoError(t, err)
+		assert.Equal(t, coverageCounters{"example.com/pkg/a.go:3.10,5.2": 4, "example.com/pkg/a.go:7.10,8.2": 4}, counters)
+	})
+
+	t.Run("malformed", func(t *testing.T) {
+		for i := 0; i < len(metaData); i++ {
+			_, err := decodeCoverageMeta(metaData[:i])
+			assert.Error(t, err, "meta-data truncated at %d", i)
+		}
+		for i := 0; i < len(counterData); i++ {
+			_, err := meta.decodeCounters(counterData[:i])
+			assert.Error(t, err, "counter data truncated at %d", i)
+		}
+		_, err := meta.decodeCounters(uleb(segment, 1, 1, 0, 4))
+		assert.Error(t, err, "unknown package")
+	})
+}
@@ -0,0 +1,140 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2024 Datadog, Inc.
+
+package gotesting
+
+import (
+	"fmt"
+	"regexp"
+	"runtime"
+	"strings"
+	"sync"
+	"testing"
+
+	"gopkg.in/DataDog/dd-trace-go.v1/civisibility"
+	"gopkg.in/DataDog/dd-trace-go.v1/internal/civisibility/constants"
+)
+
+const (
+	// maxTestOutputSize is the maximum size of the output reported for a test, only the end is kept beyond it.
+	maxTestOutputSize = 64 * 1024
+
+	// maxGoroutineDumpSize is the maximum size of the goroutine dump reported for a panic.
+	maxGoroutineDumpSize = 64 * 1024
+)
+
+// logLocationRegex matches the location prefixing the messages logged by a test, such as "foo_test.go:12: ".
+var logLocationRegex = regexp.MustCompile(`^\S+\.go:\d+: `)
+
+var (
+	// testOutputs holds the output logged through the T wrappers, by *testing.T.
+	testOutputs = map[*testing.T]*testOutput{}
+
+	// testOutputsMutex synchronizes access to testOutputs.
+	testOutputsMutex sync.Mutex
+)
+
+// testOutput is a buffer keeping the last maxTestOutputSize bytes written to it.
+type testOutput struct {
+	mu        sync.Mutex
+	buf       []byte
+	truncated bool
+}
+
+// Write appends p to the buffer, discarding its beginning when it exceeds maxTestOutputSize.
+func (o *testOutput) Write(p []byte) (int, error) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.buf = append(o.buf, p...)
+	if n := len(o.buf); n > maxTestOutputSize {
+		o.buf = append(o.buf[:0], o.buf[n-maxTestOutputSize:]...)
+		o.truncated = true
+	}
+	return len(p), nil
+}
+
+// Bytes returns the content of the buffer, and whether its beginning was discarded.
+func (o *testOutput) Bytes() ([]byte, bool) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	return o.buf, o.truncated
+}
+
+// logTestOutput records a message logged by the given test, if it's reported to CI visibility.
+func logTestOutput(t *testing.T, message string) {
+	if getCiVisibilityTest(t) == nil {
+		return
+	}
+	testOutputsMutex.Lock()
+	output, ok := testOutputs[t]
+	if !ok {
+		output = &testOutput{}
+		testOutputs[t] = output
+	}
+	testOutputsMutex.Unlock()
+	if n := len(message); n == 0 || message[n-1] != '\n' {
+		message += "\n"
+	}
+	_, _ = output.Write([]byte(message))
+}
+
+// reportTestOutput attaches the output of the given test to its test event when it failed, followed by the
+// panic output if any. The output kept by the testing framework is preferred, as it includes the messages
+// logged directly through testing.T; it's empty in verbose mode, where it's streamed to the standard
+// output instead, and the messages logged through the T wrappers are reported.
+func reportTestOutput(t *testing.T, test civisibility.DdTest, failed bool, panicOutput string) {
+	testOutputsMutex.Lock()
+	logged := testOutputs[t]
+	delete(testOutputs, t)
+	testOutputsMutex.Unlock()
+	if !failed {
+		return
+	}
+
+	output := &testOutput{}
+	if fields := getTestPrivateFields(t); fields.mu != nil && fields.output != nil {
+		fields.mu.RLock()
+		_, _ = output.Write(*fields.output)
+		fields.mu.RUnlock()
+	}
+	if len(output.buf) == 0 && logged != nil {
+		buf, truncated := logged.Bytes()
+		_, _ = output.Write(buf)
+		output.truncated = output.truncated || truncated
+	}
+	if panicOutput != "" {
+		_, _ = output.Write([]byte(panicOutput))
+	}
+
+	if buf, truncated := output.Bytes(); len(buf) > 0 {
+		test.SetTag(constants.TestOutput, string(buf))
+		if truncated {
+			test.SetTag(constants.TestOutputTruncated, "true")
+		}
+	}
+}
+
+// getSkipReason returns the skip reason of a skipped test, the last message it logged, when the testing
+// framework kept its output. It's empty in verbose mode, where the output is streamed instead.
+func getSkipReason(t *testing.T) string {
+	fields := getTestPrivateFields(t)
+	if fields.mu == nil || fields.output == nil {
+		return ""
+	}
+	fields.mu.RLock()
+	output := strings.TrimSpace(string(*fields.output))
+	fields.mu.RUnlock()
+	if i := strings.LastIndexByte(output, '\n'); i >= 0 {
+		output = strings.TrimSpace(output[i+1:])
+	}
+	return logLocationRegex.ReplaceAllString(output, "")
+}
+
+// getPanicOutput returns the output reported for a panic: its value and the stack traces of all the goroutines.
+func getPanicOutput(r any) string {
+	buf := make([]byte, maxGoroutineDumpSize)
+	n := runtime.Stack(buf, true)
+	return fmt.Sprintf("panic: %v\n\n%s\n", r, buf[:n])
+}
@@ -0,0 +1,75 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2024 Datadog, Inc.
+
+package gotesting
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"gopkg.in/DataDog/dd-trace-go.v1/civisibility"
+	"gopkg.in/DataDog/dd-trace-go.v1/internal/civisibility/constants"
+)
+
+// taggedTest is a civisibility.DdTest recording its tags.
+type taggedTest struct {
+	civisibility.DdTest
+	tags map[string]any
+}
+
+func (t *taggedTest) SetTag(key string, value any) {
+	t.tags[key] = value
+}
+
+func TestTestOutputBuffer(t *testing.T) {
+	output := &testOutput{}
+	_, _ = output.Write([]byte("first\n"))
+	buf, truncated := output.Bytes()
+	assert.Equal(t, "first\n", string(buf))
+	assert.False(t, truncated)
+
+	_, _ = output.Write([]byte(strings.Repeat("a", maxTestOutputSize-1) + "b"))
+	buf, truncated = output.Bytes()
+	assert.Len(t, buf, maxTestOutputSize)
+	assert.True(t, strings.HasSuffix(string(buf), "ab"))
+	assert.True(t, truncated)
+}
+
+func TestReportTestOutput(gt *testing.T) {
+	t := (*T)(gt)
+	t.Log("logged message")
+	t.Logf("formatted %s", "message")
+
+	passed := &taggedTest{tags: map[string]any{}}
+	reportTestOutput(gt, passed, false, "")
+	assert.Empty(gt, passed.tags)
+
+	t.Log("logged message")
+	failed := &taggedTest{tags: map[string]any{}}
+	reportTestOutput(gt, failed, true, getPanicOutput("boom"))
+	output, _ := failed.tags[constants.TestOutput].(string)
+	assert.Contains(gt, output, "logged message")
+	assert.Contains(gt, output, "panic: boom")
+	assert.Contains(gt, output, "goroutine ")
+	assert.NotContains(gt, failed.tags, constants.TestOutputTruncated)
+}
+
+func TestGetSkipReason(t *testing.T) {
+	var skipReason string
+	t.Run("skipped", func(t *testing.T) {
+		defer func() { skipReason = getSkipReason(t) }()
+		t.Log("first message")
+		t.Skip("skip reason")
+	})
+
+	if testing.Verbose() {
+		// The output is streamed in verbose mode.
+		assert.Empty(t, skipReason)
+	} else {
+		assert.Equal(t, "skip reason", skipReason)
+	}
+}
@@ -11,6 +11,7 @@ import (
 	"reflect"
 	"runtime"
 	"strings"
+	"sync"
 	"sync/atomic"
 	"testing"
 	"time"
@@ -39,11 +40,18 @@ var (
 	// benchmarkInfos holds information about the instrumented benchmarks.
 	benchmarkInfos []*testingBInfo
 
+	// fuzzInfos holds information about the instrumented fuzz tests.
+	fuzzInfos []*testingFInfo
+
 	// modulesCounters keeps track of the number of tests per module.
 	modulesCounters = map[string]*int32{}
 
 	// suitesCounters keeps track of the number of tests per suite.
 	suitesCounters = map[string]*int32{}
+
+	// countersMutex synchronizes access to modulesCounters and suitesCounters, which get new entries
+	// while the tests run for the suites of the testing frameworks.
+	countersMutex sync.RWMutex
 )
 
 type (
@@ -58,6 +66,8 @@ type (
 	testingTInfo struct {
 		commonInfo
 		originalFunc func(*testing.T)
+		isNew        bool // Whether the test is unknown to the backend (Early Flake Detection).
+		skippedByItr bool // Whether the test is skipped by the Intelligent Test Runner.
 	}
 
 	// testingBInfo holds information specific to benchmarks.
@@ -66,23 +76,52 @@ type (
 		originalFunc func(b *testing.B)
 	}
 
+	// testingFInfo holds information specific to fuzz tests.
+	testingFInfo struct {
+		commonInfo
+		originalFunc func(f *testing.F)
+	}
+
 	// M is a wrapper around testing.M to provide instrumentation.
 	M testing.M
 )
 
 // Run initializes CI Visibility, instruments tests and benchmarks, and runs them.
+//
+// When the per-test code coverage is enabled in the CI Visibility settings, the tests must be run with
+// -covermode=atomic (the default cover mode with -race) for their code coverage to be reported.
 func (ddm *M) Run() int {
+	// Fuzzing workers only run the inputs of the fuzzing coordinator, which reports the fuzz test.
+	if isFuzzWorker() {
+		return (*testing.M)(ddm).Run()
+	}
+
 	internal.EnsureCiVisibilityInitialization()
 	defer internal.ExitCiVisibility()
 
 	// Create a new test session for CI visibility.
 	session = civisibility.CreateTestSession()
 
+	// Load the Automatic Test Retries settings.
+	initFlakyRetries()
+
+	// Collect the code coverage of every test if required.
+	initTestCoverage()
+
 	m := (*testing.M)(ddm)
 
 	// Instrument the internal tests for CI visibility.
 	ddm.instrumentInternalTests(getInternalTestArray(m))
 
+	// Skip the tests unaffected by the changes with the Intelligent Test Runner.
+	initIntelligentTestRunner(testInfos)
+
+	// Run the new tests several times with Early Flake Detection.
+	initEarlyFlakeDetection(testInfos)
+
+	// Instrument the internal fuzz tests for CI visibility.
+	ddm.instrumentInternalFuzzTargets(getInternalFuzzTargetArray(m))
+
 	// Instrument the internal benchmarks for CI visibility.
 	for _, v := range os.Args {
 		// check if benchmarking is enabled to instrument
@@ -106,6 +145,11 @@ func (ddm *M) Run() int {
 		}
 	}
 
+	// Report the number of tests skipped by the Intelligent Test Runner.
+	if testsSkippingEnabled {
+		setTestsSkippingTags(session, atomic.LoadInt64(&skippedTestsCount))
+	}
+
 	// Close the session and return the exit code.
 	session.Close(exitCode)
 	return exitCode
@@ -127,20 +171,9 @@ func (ddm *M) instrumentInternalTests(internalTests *[]testing.InternalTest) {
 				},
 			}
 
-			// Initialize module and suite counters if not already present.
-			if _, ok := modulesCounters[moduleName]; !ok {
-				var v int32 = 0
-				modulesCounters[moduleName] = &v
-			}
-			// Increment the test count in the module.
-			atomic.AddInt32(modulesCounters[moduleName], 1)
-
-			if _, ok := suitesCounters[suiteName]; !ok {
-				var v int32 = 0
-				suitesCounters[suiteName] = &v
-			}
-			// Increment the test count in the suite.
-			atomic.AddInt32(suitesCounters[suiteName], 1)
+			// Increment the test count in the module and suite.
+			atomic.AddInt32(getTestsCounter(modulesCounters, moduleName), 1)
+			atomic.AddInt32(getTestsCounter(suitesCounters, suiteName), 1)
 
 			testInfos[idx] = testInfo
 		}
@@ -161,41 +194,171 @@ func (ddm *M) instrumentInternalTests(internalTests *[]testing.InternalTest) {
 func (ddm *M) executeInternalTest(testInfo *testingTInfo) func(*testing.T) {
 	originalFunc := runtime.FuncForPC(reflect.Indirect(reflect.ValueOf(testInfo.originalFunc)).Pointer())
 	return func(t *testing.T) {
-		// Create or retrieve the module, suite, and test for CI visibility.
+		// Create or retrieve the module and suite for CI visibility.
 		module := session.GetOrCreateModuleWithFramework(testInfo.moduleName, testFramework, runtime.Version())
 		suite := module.GetOrCreateSuite(testInfo.suiteName)
-		test := suite.CreateTest(testInfo.testName)
-		test.SetTestFunc(originalFunc)
-		setCiVisibilityTest(t, test)
+		if testInfo.skippedByItr {
+			skipTestWithItr(t, module, suite, testInfo.testName, originalFunc)
+			return
+		}
+		if testsSkippingEnabled {
+			setTestsSkippingTags(module, atomic.LoadInt64(modulesSkippedCounters[module.Name()]))
+		}
+		runTest(t, module, suite, testInfo.testName, originalFunc, testInfo.originalFunc, testInfo.isNew)
+	}
+}
+
+// runTest runs the test function f, reporting its execution to CI visibility. Failed tests are
+// retried in place when Automatic Test Retries are enabled, and new tests are run several times
+// by Early Flake Detection.
+func runTest(t *testing.T, module civisibility.DdTestModule, suite civisibility.DdTestSuite, testName string, testFunc *runtime.Func, f func(*testing.T), isNew bool) {
+	if flakyRetriesEnabled || isNew {
+		runTestWithRetries(t, module, suite, testName, testFunc, f, isNew)
+		return
+	}
+
+	test := createTest(suite, testName, testFunc)
+	setCiVisibilityTest(t, test)
+	collectCoverage := startTestCoverage(test)
+	defer func() {
+		if r := recover(); r != nil {
+			// Handle panic and set error information.
+			reportTestOutput(t, test, true, getPanicOutput(r))
+			closeTestWithPanic(module, suite, test, r, utils.GetStacktrace(1))
+			panic(r)
+		} else {
+			// Normal finalization: report the code coverage and output, and determine the test result based on its state.
+			collectCoverage()
+			reportTestOutput(t, test, t.Failed(), "")
+			if t.Failed() {
+				test.SetTag(ext.Error, true)
+				suite.SetTag(ext.Error, true)
+				module.SetTag(ext.Error, true)
+				test.Close(civisibility.ResultStatusFail)
+			} else if t.Skipped() {
+				test.CloseWithFinishTimeAndSkipReason(civisibility.ResultStatusSkip, time.Now(), getSkipReason(t))
+			} else {
+				test.Close(civisibility.ResultStatusPass)
+			}
+
+			checkModuleAndSuite(module, suite)
+		}
+	}()
+	defer startTestContext(t, test)()
+
+	// Execute the original test function.
+	f(t)
+}
+
+// createTest creates the CI visibility test of an execution of the test function.
+func createTest(suite civisibility.DdTestSuite, testName string, testFunc *runtime.Func) civisibility.DdTest {
+	test := suite.CreateTest(testName)
+	test.SetTestFunc(testFunc)
+	setItrTestTags(test, suite, testName, testFunc)
+	return test
+}
+
+// closeTestWithPanic closes a test that panicked, along with its module and suite when it was their
+// last test, and exits CI visibility as the panic is about to end the test process.
+func closeTestWithPanic(module civisibility.DdTestModule, suite civisibility.DdTestSuite, test civisibility.DdTest, r any, stacktrace string) {
+	test.SetErrorInfo("panic", fmt.Sprint(r), stacktrace)
+	suite.SetTag(ext.Error, true)
+	module.SetTag(ext.Error, true)
+	test.Close(civisibility.ResultStatusFail)
+	checkModuleAndSuite(module, suite)
+	internal.ExitCiVisibility()
+}
+
+// instrumentInternalFuzzTargets instruments the internal fuzz tests for CI visibility.
+func (ddm *M) instrumentInternalFuzzTargets(internalFuzzTargets *[]testing.InternalFuzzTarget) {
+	if internalFuzzTargets != nil {
+		// Extract info from internal fuzz tests
+		fuzzInfos = make([]*testingFInfo, len(*internalFuzzTargets))
+		for idx, fuzzTarget := range *internalFuzzTargets {
+			moduleName, suiteName := utils.GetModuleAndSuiteName(reflect.Indirect(reflect.ValueOf(fuzzTarget.Fn)).Pointer())
+			fuzzInfo := &testingFInfo{
+				originalFunc: fuzzTarget.Fn,
+				commonInfo: commonInfo{
+					moduleName: moduleName,
+					suiteName:  suiteName,
+					testName:   fuzzTarget.Name,
+				},
+			}
+
+			// Increment the test count in the module and suite.
+			atomic.AddInt32(getTestsCounter(modulesCounters, moduleName), 1)
+			atomic.AddInt32(getTestsCounter(suitesCounters, suiteName), 1)
+
+			fuzzInfos[idx] = fuzzInfo
+		}
+
+		// Create new instrumented internal fuzz tests
+		newFuzzTargetArray := make([]testing.InternalFuzzTarget, len(*internalFuzzTargets))
+		for idx, fuzzInfo := range fuzzInfos {
+			newFuzzTargetArray[idx] = testing.InternalFuzzTarget{
+				Name: fuzzInfo.testName,
+				Fn:   ddm.executeInternalFuzzTarget(fuzzInfo),
+			}
+		}
+		*internalFuzzTargets = newFuzzTargetArray
+	}
+}
+
+// executeInternalFuzzTarget wraps the original fuzz test function to include CI visibility instrumentation.
+// The fuzz test is reported as a test, both when only its seed corpus is run and when it's fuzzed.
+func (ddm *M) executeInternalFuzzTarget(fuzzInfo *testingFInfo) func(*testing.F) {
+	originalFunc := runtime.FuncForPC(reflect.Indirect(reflect.ValueOf(fuzzInfo.originalFunc)).Pointer())
+	return func(f *testing.F) {
+		// Create or retrieve the module and suite for CI visibility.
+		module := session.GetOrCreateModuleWithFramework(fuzzInfo.moduleName, testFramework, runtime.Version())
+		suite := module.GetOrCreateSuite(fuzzInfo.suiteName)
+		test := createTest(suite, fuzzInfo.testName, originalFunc)
+		setCiVisibilityFuzzTest(f, test)
+
+		fuzzing := isFuzzing(f)
+		stopFuzzStats := func() fuzzStats { return fuzzStats{} }
+		if fuzzing {
+			test.SetTag(constants.TestFuzzMode, "fuzzing")
+			stopFuzzStats = captureFuzzStats()
+		} else {
+			test.SetTag(constants.TestFuzzMode, "seed_corpus")
+		}
+
 		defer func() {
+			if fuzzing {
+				stats := stopFuzzStats()
+				test.SetTag(constants.TestFuzzIterations, stats.iterations)
+				test.SetTag(constants.TestFuzzNewInterestingInputs, stats.newInteresting)
+				if result := getFuzzResult(f); result != nil && result.Error != nil {
+					if crashErr, ok := result.Error.(interface{ CrashPath() string }); ok {
+						test.SetTag(constants.TestFuzzCrashInputPath, crashErr.CrashPath())
+					}
+				}
+			}
+
 			if r := recover(); r != nil {
 				// Handle panic and set error information.
-				test.SetErrorInfo("panic", fmt.Sprint(r), utils.GetStacktrace(1))
+				closeTestWithPanic(module, suite, test, r, utils.GetStacktrace(1))
+				panic(r)
+			}
+
+			// Normal finalization: determine the fuzz test result based on its state.
+			if f.Failed() {
+				test.SetTag(ext.Error, true)
 				suite.SetTag(ext.Error, true)
 				module.SetTag(ext.Error, true)
 				test.Close(civisibility.ResultStatusFail)
-				checkModuleAndSuite(module, suite)
-				internal.ExitCiVisibility()
-				panic(r)
+			} else if f.Skipped() {
+				test.Close(civisibility.ResultStatusSkip)
 			} else {
-				// Normal finalization: determine the test result based on its state.
-				if t.Failed() {
-					test.SetTag(ext.Error, true)
-					suite.SetTag(ext.Error, true)
-					module.SetTag(ext.Error, true)
-					test.Close(civisibility.ResultStatusFail)
-				} else if t.Skipped() {
-					test.Close(civisibility.ResultStatusSkip)
-				} else {
-					test.Close(civisibility.ResultStatusPass)
-				}
-
-				checkModuleAndSuite(module, suite)
+				test.Close(civisibility.ResultStatusPass)
 			}
+
+			checkModuleAndSuite(module, suite)
 		}()
 
-		// Execute the original test function.
-		testInfo.originalFunc(t)
+		// Execute the original fuzz test function.
+		fuzzInfo.originalFunc(f)
 	}
 }
 
@@ -215,20 +378,9 @@ func (ddm *M) instrumentInternalBenchmarks(internalBenchmarks *[]testing.Interna
 				},
 			}
 
-			// Initialize module and suite counters if not already present.
-			if _, ok := modulesCounters[moduleName]; !ok {
-				var v int32 = 0
-				modulesCounters[moduleName] = &v
-			}
-			// Increment the test count in the module.
-			atomic.AddInt32(modulesCounters[moduleName], 1)
-
-			if _, ok := suitesCounters[suiteName]; !ok {
-				var v int32 = 0
-				suitesCounters[suiteName] = &v
-			}
-			// Increment the test count in the suite.
-			atomic.AddInt32(suitesCounters[suiteName], 1)
+			// Increment the test count in the module and suite.
+			atomic.AddInt32(getTestsCounter(modulesCounters, moduleName), 1)
+			atomic.AddInt32(getTestsCounter(suitesCounters, suiteName), 1)
 
 			benchmarkInfos[idx] = benchmarkInfo
 		}
@@ -349,7 +501,7 @@ func (ddm *M) executeInternalBenchmark(benchmarkInfo *testingBInfo) func(*testin
 	}
 }
 
-// RunM runs the tests and benchmarks using CI visibility.
+// RunM runs the tests and benchmarks using CI visibility, see M.Run.
 func RunM(m *testing.M) int {
 	return (*M)(m).Run()
 }
@@ -362,12 +514,30 @@ func RunAndExit(m *testing.M) {
 // checkModuleAndSuite checks and closes the modules and suites if all tests are executed.
 func checkModuleAndSuite(module civisibility.DdTestModule, suite civisibility.DdTestSuite) {
 	// If all tests in a suite has been executed we can close the suite
-	if atomic.AddInt32(suitesCounters[suite.Name()], -1) <= 0 {
+	if atomic.AddInt32(getTestsCounter(suitesCounters, suite.Name()), -1) <= 0 {
 		suite.Close()
 	}
 
 	// If all tests in a module has been executed we can close the module
-	if atomic.AddInt32(modulesCounters[module.Name()], -1) <= 0 {
+	if atomic.AddInt32(getTestsCounter(modulesCounters, module.Name()), -1) <= 0 {
 		module.Close()
 	}
 }
+
+// getTestsCounter returns the tests counter of a module or suite, creating it if needed.
+func getTestsCounter(counters map[string]*int32, name string) *int32 {
+	countersMutex.RLock()
+	counter, ok := counters[name]
+	countersMutex.RUnlock()
+	if ok {
+		return counter
+	}
+
+	countersMutex.Lock()
+	defer countersMutex.Unlock()
+	if counter, ok = counters[name]; !ok {
+		counter = new(int32)
+		counters[name] = counter
+	}
+	return counter
+}
@@ -55,10 +55,10 @@ func (ddb *B) Run(name string, f func(*testing.B)) bool {
 	originalFunc := runtime.FuncForPC(fReflect.Pointer())
 
 	// Increment the test count in the module.
-	atomic.AddInt32(modulesCounters[moduleName], 1)
+	atomic.AddInt32(getTestsCounter(modulesCounters, moduleName), 1)
 
 	// Increment the test count in the suite.
-	atomic.AddInt32(suitesCounters[suiteName], 1)
+	atomic.AddInt32(getTestsCounter(suitesCounters, suiteName), 1)
 
 	pb := (*testing.B)(ddb)
 	return pb.Run(subBenchmarkAutoName, func(b *testing.B) {
@@ -0,0 +1,186 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2024 Datadog, Inc.
+
+package gotesting
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"runtime"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"gopkg.in/DataDog/dd-trace-go.v1/civisibility"
+	"gopkg.in/DataDog/dd-trace-go.v1/internal/civisibility/utils"
+)
+
+var (
+	// ciVisibilityFuzzTests holds a map of *testing.F to civisibility.DdTest for tracking fuzz tests.
+	ciVisibilityFuzzTests = map[*testing.F]civisibility.DdTest{}
+
+	// ciVisibilityFuzzTestsMutex is a read-write mutex for synchronizing access to ciVisibilityFuzzTests.
+	ciVisibilityFuzzTestsMutex sync.RWMutex
+)
+
+// F is a type alias for testing.F to provide additional methods for CI visibility.
+type F testing.F
+
+// GetFuzz is a helper to return *gotesting.F from *testing.F.
+// Internally, it is just a (*gotesting.F)(f) cast.
+func GetFuzz(f *testing.F) *F {
+	return (*F)(f)
+}
+
+// Add will add the arguments to the seed corpus for the fuzz test. This will be
+// a no-op if called after or within the fuzz target, and args must match the
+// arguments for the fuzz target.
+func (ddf *F) Add(args ...any) {
+	(*testing.F)(ddf).Add(args...)
+}
+
+// Fuzz runs the fuzz function, ff, for fuzz testing. If ff fails for a set of
+// arguments, those arguments will be added to the seed corpus.
+//
+// When only the seed corpus is run, each of its entries is reported to CI visibility
+// as a test, named after the subtest running it. When fuzzing, the inputs are run by the
+// fuzzing workers, and only the fuzz test is reported.
+func (ddf *F) Fuzz(ff any) {
+	f := (*testing.F)(ddf)
+	fuzzTest := getCiVisibilityFuzzTest(f)
+	fn := reflect.ValueOf(ff)
+	if fuzzTest == nil || isFuzzing(f) || fn.Kind() != reflect.Func || fn.Type().NumIn() == 0 || fn.Type().In(0) != reflect.TypeOf((*testing.T)(nil)) {
+		// Let testing.F handle (and report) anything else than an instrumented seed corpus run.
+		f.Fuzz(ff)
+		return
+	}
+
+	suite := fuzzTest.Suite()
+	module := suite.Module()
+	originalFunc := runtime.FuncForPC(fn.Pointer())
+	f.Fuzz(reflect.MakeFunc(fn.Type(), func(args []reflect.Value) []reflect.Value {
+		// Increment the test count in the module and suite for the seed corpus entry.
+		atomic.AddInt32(getTestsCounter(modulesCounters, module.Name()), 1)
+		atomic.AddInt32(getTestsCounter(suitesCounters, suite.Name()), 1)
+
+		t := args[0].Interface().(*testing.T)
+		runTest(t, module, suite, t.Name(), originalFunc, func(t *testing.T) {
+			args[0] = reflect.ValueOf(t)
+			fn.Call(args)
+		}, false)
+		return nil
+	}).Interface())
+}
+
+// Context returns the CI Visibility context of the Test span.
+// This may be used to create test's children spans useful for
+// integration tests.
+func (ddf *F) Context() context.Context {
+	f := (*testing.F)(ddf)
+	ciTest := getCiVisibilityFuzzTest(f)
+	if ciTest != nil {
+		return ciTest.Context()
+	}
+
+	return context.Background()
+}
+
+// Fail marks the function as having failed but continues execution.
+func (ddf *F) Fail() {
+	f := (*testing.F)(ddf)
+	ciTest := getCiVisibilityFuzzTest(f)
+	if ciTest != nil {
+		ciTest.SetErrorInfo("Fail", "failed test", utils.GetStacktrace(1))
+	}
+
+	f.Fail()
+}
+
+// Error is equivalent to Log followed by Fail.
+func (ddf *F) Error(args ...any) {
+	f := (*testing.F)(ddf)
+	ciTest := getCiVisibilityFuzzTest(f)
+	if ciTest != nil {
+		ciTest.SetErrorInfo("Error", fmt.Sprint(args...), utils.GetStacktrace(1))
+	}
+
+	f.Error(args...)
+}
+
+// Errorf is equivalent to Logf followed by Fail.
+func (ddf *F) Errorf(format string, args ...any) {
+	f := (*testing.F)(ddf)
+	ciTest := getCiVisibilityFuzzTest(f)
+	if ciTest != nil {
+		ciTest.SetErrorInfo("Errorf", fmt.Sprintf(format, args...), utils.GetStacktrace(1))
+	}
+
+	f.Errorf(format, args...)
+}
+
+// Fatal is equivalent to Log followed by FailNow.
+func (ddf *F) Fatal(args ...any) {
+	f := (*testing.F)(ddf)
+	ciTest := getCiVisibilityFuzzTest(f)
+	if ciTest != nil {
+		ciTest.SetErrorInfo("Fatal", fmt.Sprint(args...), utils.GetStacktrace(1))
+	}
+
+	f.Fatal(args...)
+}
+
+// Fatalf is equivalent to Logf followed by FailNow.
+func (ddf *F) Fatalf(format string, args ...any) {
+	f := (*testing.F)(ddf)
+	ciTest := getCiVisibilityFuzzTest(f)
+	if ciTest != nil {
+		ciTest.SetErrorInfo("Fatalf", fmt.Sprintf(format, args...), utils.GetStacktrace(1))
+	}
+
+	f.Fatalf(format, args...)
+}
+
+// Skip is equivalent to Log followed by SkipNow.
+func (ddf *F) Skip(args ...any) {
+	f := (*testing.F)(ddf)
+	ciTest := getCiVisibilityFuzzTest(f)
+	if ciTest != nil {
+		ciTest.CloseWithFinishTimeAndSkipReason(civisibility.ResultStatusSkip, time.Now(), fmt.Sprint(args...))
+	}
+
+	f.Skip(args...)
+}
+
+// Skipf is equivalent to Logf followed by SkipNow.
+func (ddf *F) Skipf(format string, args ...any) {
+	f := (*testing.F)(ddf)
+	ciTest := getCiVisibilityFuzzTest(f)
+	if ciTest != nil {
+		ciTest.CloseWithFinishTimeAndSkipReason(civisibility.ResultStatusSkip, time.Now(), fmt.Sprintf(format, args...))
+	}
+
+	f.Skipf(format, args...)
+}
+
+// getCiVisibilityFuzzTest retrieves the CI visibility test associated with a given *testing.F.
+func getCiVisibilityFuzzTest(f *testing.F) civisibility.DdTest {
+	ciVisibilityFuzzTestsMutex.RLock()
+	defer ciVisibilityFuzzTestsMutex.RUnlock()
+
+	if v, ok := ciVisibilityFuzzTests[f]; ok {
+		return v
+	}
+
+	return nil
+}
+
+// setCiVisibilityFuzzTest associates a CI visibility test with a given *testing.F.
+func setCiVisibilityFuzzTest(f *testing.F, ciTest civisibility.DdTest) {
+	ciVisibilityFuzzTestsMutex.Lock()
+	defer ciVisibilityFuzzTestsMutex.Unlock()
+	ciVisibilityFuzzTests[f] = ciTest
+}
@@ -16,8 +16,9 @@ import (
 	"time"
 
 	"gopkg.in/DataDog/dd-trace-go.v1/civisibility"
-	"gopkg.in/DataDog/dd-trace-go.v1/ddtrace/ext"
-	internal "gopkg.in/DataDog/dd-trace-go.v1/internal/civisibility"
+	"gopkg.in/DataDog/dd-trace-go.v1/ddtrace"
+	"gopkg.in/DataDog/dd-trace-go.v1/ddtrace/tracer"
+	"gopkg.in/DataDog/dd-trace-go.v1/internal/civisibility/testcontext"
 	"gopkg.in/DataDog/dd-trace-go.v1/internal/civisibility/utils"
 )
 
@@ -51,51 +52,24 @@ func (ddt *T) Run(name string, f func(*testing.T)) bool {
 	originalFunc := runtime.FuncForPC(fReflect.Pointer())
 
 	// Increment the test count in the module.
-	atomic.AddInt32(modulesCounters[moduleName], 1)
+	atomic.AddInt32(getTestsCounter(modulesCounters, moduleName), 1)
 
 	// Increment the test count in the suite.
-	atomic.AddInt32(suitesCounters[suiteName], 1)
+	atomic.AddInt32(getTestsCounter(suitesCounters, suiteName), 1)
 
 	t := (*testing.T)(ddt)
 	return t.Run(name, func(t *testing.T) {
-		// Create or retrieve the module, suite, and test for CI visibility.
+		// Create or retrieve the module and suite for CI visibility.
 		module := session.GetOrCreateModuleWithFramework(moduleName, testFramework, runtime.Version())
 		suite := module.GetOrCreateSuite(suiteName)
-		test := suite.CreateTest(t.Name())
-		test.SetTestFunc(originalFunc)
-		setCiVisibilityTest(t, test)
-		defer func() {
-			if r := recover(); r != nil {
-				// Handle panic and set error information.
-				test.SetErrorInfo("panic", fmt.Sprint(r), utils.GetStacktrace(1))
-				test.Close(civisibility.ResultStatusFail)
-				checkModuleAndSuite(module, suite)
-				internal.ExitCiVisibility()
-				panic(r)
-			} else {
-				// Normal finalization: determine the test result based on its state.
-				if t.Failed() {
-					test.SetTag(ext.Error, true)
-					suite.SetTag(ext.Error, true)
-					module.SetTag(ext.Error, true)
-					test.Close(civisibility.ResultStatusFail)
-				} else if t.Skipped() {
-					test.Close(civisibility.ResultStatusSkip)
-				} else {
-					test.Close(civisibility.ResultStatusPass)
-				}
-				checkModuleAndSuite(module, suite)
-			}
-		}()
-
-		// Execute the original test function.
-		f(t)
+		runTest(t, module, suite, t.Name(), originalFunc, f, false)
 	})
 }
 
 // Context returns the CI Visibility context of the Test span.
 // This may be used to create test's children spans useful for
-// integration tests.
+// integration tests. The spans started without a parent while
+// no other test runs in parallel are the test's children anyway.
 func (ddt *T) Context() context.Context {
 	t := (*testing.T)(ddt)
 	ciTest := getCiVisibilityTest(t)
@@ -106,6 +80,29 @@ func (ddt *T) Context() context.Context {
 	return context.Background()
 }
 
+// Log formats its arguments using default formatting, analogous to Println,
+// and records the text in the error log. For tests, the text will be printed only if
+// the test fails or the -test.v flag is set. For benchmarks, the text is always
+// printed to avoid having performance depend on the value of the -test.v flag.
+func (ddt *T) Log(args ...any) {
+	t := (*testing.T)(ddt)
+	t.Helper()
+	logTestOutput(t, fmt.Sprintln(args...))
+	t.Log(args...)
+}
+
+// Logf formats its arguments according to the format, analogous to Printf, and
+// records the text in the error log. A final newline is added if not provided. For
+// tests, the text will be printed only if the test fails or the -test.v flag is
+// set. For benchmarks, the text is always printed to avoid having performance
+// depend on the value of the -test.v flag.
+func (ddt *T) Logf(format string, args ...any) {
+	t := (*testing.T)(ddt)
+	t.Helper()
+	logTestOutput(t, fmt.Sprintf(format, args...))
+	t.Logf(format, args...)
+}
+
 // Fail marks the function as having failed but continues execution.
 func (ddt *T) Fail() {
 	t := (*testing.T)(ddt)
@@ -139,6 +136,7 @@ func (ddt *T) Error(args ...any) {
 	ciTest := getCiVisibilityTest(t)
 	if ciTest != nil {
 		ciTest.SetErrorInfo("Error", fmt.Sprint(args...), utils.GetStacktrace(1))
+		logTestOutput(t, fmt.Sprint(args...))
 	}
 
 	t.Error(args...)
@@ -150,6 +148,7 @@ func (ddt *T) Errorf(format string, args ...any) {
 	ciTest := getCiVisibilityTest(t)
 	if ciTest != nil {
 		ciTest.SetErrorInfo("Errorf", fmt.Sprintf(format, args...), utils.GetStacktrace(1))
+		logTestOutput(t, fmt.Sprintf(format, args...))
 	}
 
 	t.Errorf(format, args...)
@@ -161,6 +160,7 @@ func (ddt *T) Fatal(args ...any) {
 	ciTest := getCiVisibilityTest(t)
 	if ciTest != nil {
 		ciTest.SetErrorInfo("Fatal", fmt.Sprint(args...), utils.GetStacktrace(1))
+		logTestOutput(t, fmt.Sprint(args...))
 	}
 
 	t.Fatal(args...)
@@ -172,6 +172,7 @@ func (ddt *T) Fatalf(format string, args ...any) {
 	ciTest := getCiVisibilityTest(t)
 	if ciTest != nil {
 		ciTest.SetErrorInfo("Fatalf", fmt.Sprintf(format, args...), utils.GetStacktrace(1))
+		logTestOutput(t, fmt.Sprintf(format, args...))
 	}
 
 	t.Fatalf(format, args...)
@@ -182,6 +183,7 @@ func (ddt *T) Skip(args ...any) {
 	t := (*testing.T)(ddt)
 	ciTest := getCiVisibilityTest(t)
 	if ciTest != nil {
+		logTestOutput(t, fmt.Sprint(args...))
 		ciTest.CloseWithFinishTimeAndSkipReason(civisibility.ResultStatusSkip, time.Now(), fmt.Sprint(args...))
 	}
 
@@ -193,6 +195,7 @@ func (ddt *T) Skipf(format string, args ...any) {
 	t := (*testing.T)(ddt)
 	ciTest := getCiVisibilityTest(t)
 	if ciTest != nil {
+		logTestOutput(t, fmt.Sprintf(format, args...))
 		ciTest.CloseWithFinishTimeAndSkipReason(civisibility.ResultStatusSkip, time.Now(), fmt.Sprintf(format, args...))
 	}
 
@@ -255,3 +258,24 @@ func setCiVisibilityTest(t *testing.T, ciTest civisibility.DdTest) {
 	defer ciVisibilityTestsMutex.Unlock()
 	ciVisibilityTests[t] = ciTest
 }
+
+// startTestContext marks the CI visibility test of an execution of t as running, so the spans started without
+// a parent during the execution, such as the spans of the integrations, are reported as its children. It
+// returns the function marking the end of the execution.
+func startTestContext(t *testing.T, test civisibility.DdTest) (end func()) {
+	var parentCtx ddtrace.SpanContext
+	if parent := getTestPrivateFields(t).parent; parent != nil && *parent != nil {
+		if parentTest := getCiVisibilityTest((*testing.T)(*parent)); parentTest != nil {
+			parentCtx = getSpanContext(parentTest)
+		}
+	}
+	return testcontext.Start(getSpanContext(test), parentCtx)
+}
+
+// getSpanContext returns the span context of a CI visibility test.
+func getSpanContext(test civisibility.DdTest) ddtrace.SpanContext {
+	if span, ok := tracer.SpanFromContext(test.Context()); ok {
+		return span.Context()
+	}
+	return nil
+}
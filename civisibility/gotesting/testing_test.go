@@ -6,24 +6,101 @@
 package gotesting
 
 import (
+	"encoding/json"
 	"fmt"
 	"net/http"
 	"net/http/httptest"
 	"os"
+	"reflect"
+	"strings"
+	"sync/atomic"
 	"testing"
 
 	ddhttp "gopkg.in/DataDog/dd-trace-go.v1/contrib/net/http"
 	ddtracer "gopkg.in/DataDog/dd-trace-go.v1/ddtrace/tracer"
+	"gopkg.in/DataDog/dd-trace-go.v1/internal/civisibility/constants"
+	"gopkg.in/DataDog/dd-trace-go.v1/internal/civisibility/utils"
+	"gopkg.in/DataDog/dd-trace-go.v1/internal/civisibility/utils/net"
 )
 
 // TestMain is the entry point for testing and runs before any test.
 func TestMain(m *testing.M) {
+	// Enable the Automatic Test Retries of the failed tests
+	os.Setenv(constants.CiVisibilityFlakyRetryEnabledEnvironmentVariable, "true")
+
+	// Always run TestUnskippable, although it's skippable
+	MarkUnskippable(TestUnskippable)
+
+	// Replace the CI Visibility backend with a local stand-in
+	server := newBackendStandIn(m)
 
 	// (*M)(m).Run() cast m to gotesting.M and just run
 	// or use a helper method gotesting.RunM(m)
 
-	// os.Exit((*M)(m).Run())
-	os.Exit(RunM(m))
+	// exitCode := (*M)(m).Run()
+	exitCode := RunM(m)
+	server.Close()
+
+	// Fuzz tests run after the tests, check their seed corpus was run once the tests are over
+	if fuzzSeedCorpusRan && !isFuzzWorker() && seedExecutions != 2 && exitCode == 0 {
+		fmt.Printf("expected 2 executions of the FuzzSeedCorpus seed corpus, got %d\n", seedExecutions)
+		exitCode = 1
+	}
+	os.Exit(exitCode)
+}
+
+// newBackendStandIn starts a stand-in of the CI Visibility backend, in agentless mode, enabling
+// the code coverage and Early Flake Detection, and knowing all the tests of m but the TestEarlyFlakeDetection
+// ones. The Intelligent Test Runner can skip TestSkippedByItr and TestUnskippable, and all the commits are known.
+func newBackendStandIn(m *testing.M) *httptest.Server {
+	knownTests := net.KnownTestsModules{}
+	for _, test := range *getInternalTestArray(m) {
+		if test.Name == "TestEarlyFlakeDetection" || test.Name == "TestEarlyFlakeDetectionParallelSubtest" {
+			continue
+		}
+		module, suite := utils.GetModuleAndSuiteName(reflect.ValueOf(test.F).Pointer())
+		if knownTests[module] == nil {
+			knownTests[module] = net.KnownTestsSuites{}
+		}
+		knownTests[module][suite] = append(knownTests[module][suite], test.Name)
+	}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var attributes any
+		switch {
+		case strings.HasSuffix(r.URL.Path, "/api/v2/libraries/tests/services/setting"):
+			settings := net.SettingsResponseData{CodeCoverage: true, ItrEnabled: true, TestsSkipping: true}
+			settings.EarlyFlakeDetection.Enabled = true
+			settings.EarlyFlakeDetection.SlowTestRetries.FiveS = 10
+			settings.EarlyFlakeDetection.FaultySessionThreshold = 30
+			attributes = settings
+		case strings.HasSuffix(r.URL.Path, "/api/v2/ci/libraries/tests"):
+			attributes = net.KnownTestsResponseData{Tests: knownTests}
+		case strings.HasSuffix(r.URL.Path, "/api/v2/git/repository/search_commits"):
+			// Every commit is known, there's nothing to upload
+			var commits map[string]any
+			_ = json.NewDecoder(r.Body).Decode(&commits)
+			_ = json.NewEncoder(w).Encode(map[string]any{"data": commits["data"]})
+			return
+		case strings.HasSuffix(r.URL.Path, "/api/v2/ci/tests/skippable"):
+			var data []any
+			for _, name := range []string{"TestSkippedByItr", "TestUnskippable"} {
+				data = append(data, map[string]any{"type": "test", "attributes": net.SkippableTest{Suite: "testing_test.go", Name: name}})
+			}
+			_ = json.NewEncoder(w).Encode(map[string]any{"meta": map[string]any{"correlation_id": "correlation"}, "data": data})
+			return
+		default:
+			// Test cycle and test coverage payloads
+			w.WriteHeader(http.StatusAccepted)
+			return
+		}
+		_ = json.NewEncoder(w).Encode(map[string]any{"data": map[string]any{"attributes": attributes}})
+	}))
+
+	os.Setenv(constants.CiVisibilityAgentlessEnabledEnvironmentVariable, "true")
+	os.Setenv(constants.CiVisibilityAgentlessUrlEnvironmentVariable, server.URL)
+	os.Setenv(constants.ApiKeyEnvironmentVariable, "api-key")
+	return server
 }
 
 // TestMyTest02 demonstrates instrumentation of InternalTests
@@ -144,6 +221,181 @@ func TestSkip(gt *testing.T) {
 	t.Skip("Nothing to do here, skipping!")
 }
 
+var (
+	// flakyExecutions counts the executions of TestFlakyRetries
+	flakyExecutions int
+
+	// flakyCleanups counts the cleanups of TestFlakyRetries
+	flakyCleanups int
+
+	// flakySubtestExecutions counts the executions of the TestFlakySubtestRetries subtest
+	flakySubtestExecutions int
+
+	// flakyParallelSubtestExecutions counts the executions of the TestFlakyParallelSubtestRetries subtest
+	flakyParallelSubtestExecutions int32
+)
+
+// TestFlakyRetries demonstrates the Automatic Test Retries of a flaky test,
+// failing in its first two executions.
+func TestFlakyRetries(t *testing.T) {
+	flakyExecutions++
+	t.Cleanup(func() { flakyCleanups++ })
+	if flakyExecutions < 3 {
+		t.Fatalf("flaky failure #%d", flakyExecutions)
+	}
+}
+
+// TestFlakySubtestRetries demonstrates the Automatic Test Retries of a flaky subtest.
+func TestFlakySubtestRetries(gt *testing.T) {
+	t := (*T)(gt)
+	t.Run("flaky", func(t *testing.T) {
+		flakySubtestExecutions++
+		if t.Name() != "TestFlakySubtestRetries/flaky" {
+			t.Fatalf("unexpected subtest name %s", t.Name())
+		}
+		if flakySubtestExecutions == 1 {
+			t.Error("flaky failure")
+		}
+	})
+}
+
+// TestFlakyParallelSubtestRetries demonstrates the Automatic Test Retries of a test whose parallel
+// subtest fails in its first execution, after the test function returned.
+func TestFlakyParallelSubtestRetries(t *testing.T) {
+	t.Run("parallel", func(t *testing.T) {
+		t.Parallel()
+		if atomic.AddInt32(&flakyParallelSubtestExecutions, 1) == 1 {
+			t.Fatal("flaky failure")
+		}
+	})
+}
+
+// TestFlakyRetriesExecutions checks the flaky tests were retried until they passed.
+func TestFlakyRetriesExecutions(t *testing.T) {
+	if flakyExecutions != 3 || flakyCleanups != 3 {
+		t.Fatalf("expected 3 executions and cleanups of TestFlakyRetries, got %d and %d", flakyExecutions, flakyCleanups)
+	}
+	if flakySubtestExecutions != 2 {
+		t.Fatalf("expected 2 executions of TestFlakySubtestRetries/flaky, got %d", flakySubtestExecutions)
+	}
+	if n := atomic.LoadInt32(&flakyParallelSubtestExecutions); n != 2 {
+		t.Fatalf("expected 2 executions of TestFlakyParallelSubtestRetries/parallel, got %d", n)
+	}
+}
+
+var (
+	// newTestExecutions counts the executions of TestEarlyFlakeDetection
+	newTestExecutions int
+
+	// newParallelSubtestExecutions counts the executions of the TestEarlyFlakeDetectionParallelSubtest subtest
+	newParallelSubtestExecutions int32
+)
+
+// TestEarlyFlakeDetection demonstrates Early Flake Detection of a new test,
+// unknown to the backend, failing in its first execution.
+func TestEarlyFlakeDetection(t *testing.T) {
+	newTestExecutions++
+	if newTestExecutions == 1 {
+		t.Error("flaky failure")
+	}
+}
+
+// TestEarlyFlakeDetectionParallelSubtest demonstrates Early Flake Detection of a new test whose
+// parallel subtest fails in its first execution, after the test function returned.
+func TestEarlyFlakeDetectionParallelSubtest(t *testing.T) {
+	t.Run("parallel", func(t *testing.T) {
+		t.Parallel()
+		if atomic.AddInt32(&newParallelSubtestExecutions, 1) == 1 {
+			t.Fatal("flaky failure")
+		}
+	})
+}
+
+// TestEarlyFlakeDetectionExecutions checks the new tests were retried 10 times,
+// as fast tests, and reported as passed.
+func TestEarlyFlakeDetectionExecutions(t *testing.T) {
+	if newTestExecutions != 11 {
+		t.Fatalf("expected 11 executions of TestEarlyFlakeDetection, got %d", newTestExecutions)
+	}
+	if n := atomic.LoadInt32(&newParallelSubtestExecutions); n != 11 {
+		t.Fatalf("expected 11 executions of TestEarlyFlakeDetectionParallelSubtest/parallel, got %d", n)
+	}
+}
+
+// unskippableExecutions counts the executions of TestUnskippable
+var unskippableExecutions int
+
+// TestSkippedByItr demonstrates a test skipped by the Intelligent Test Runner,
+// which would fail if it was run.
+func TestSkippedByItr(t *testing.T) {
+	t.Fatal("TestSkippedByItr should be skipped by the Intelligent Test Runner")
+}
+
+// TestUnskippable demonstrates a skippable test marked as unskippable.
+func TestUnskippable(t *testing.T) {
+	unskippableExecutions++
+}
+
+// TestIntelligentTestRunner checks the skippable tests were skipped, but the unskippable one.
+func TestIntelligentTestRunner(t *testing.T) {
+	if unskippableExecutions != 1 {
+		t.Fatalf("expected 1 execution of TestUnskippable, got %d", unskippableExecutions)
+	}
+	if skippedTestsCount != 1 {
+		t.Fatalf("expected 1 test skipped by the Intelligent Test Runner, got %d", skippedTestsCount)
+	}
+}
+
+// frameworkSubtestExecutions counts the executions of the subtests of TestRunSubtests
+var frameworkSubtestExecutions = map[string]int{}
+
+// TestRunSubtests demonstrates the subtests of a testing framework reported as the tests of a suite,
+// the flaky one being retried by Automatic Test Retries.
+func TestRunSubtests(t *testing.T) {
+	RunSubtests(t, "FrameworkSuite", []Subtest{
+		{Name: "TestPass", F: func(t *testing.T) {
+			frameworkSubtestExecutions[t.Name()]++
+		}},
+		{Name: "TestFlaky", F: func(t *testing.T) {
+			frameworkSubtestExecutions[t.Name()]++
+			if frameworkSubtestExecutions[t.Name()] == 1 {
+				t.Error("flaky failure")
+			}
+		}},
+	})
+	if n := frameworkSubtestExecutions["TestRunSubtests/TestPass"]; n != 1 {
+		t.Fatalf("expected 1 execution of TestRunSubtests/TestPass, got %d", n)
+	}
+	if n := frameworkSubtestExecutions["TestRunSubtests/TestFlaky"]; n != 2 {
+		t.Fatalf("expected 2 executions of TestRunSubtests/TestFlaky, got %d", n)
+	}
+}
+
+var (
+	// fuzzSeedCorpusRan is set when FuzzSeedCorpus only runs its seed corpus
+	fuzzSeedCorpusRan bool
+
+	// seedExecutions counts the executions of the FuzzSeedCorpus seed corpus
+	seedExecutions int
+)
+
+// FuzzSeedCorpus demonstrates the instrumentation of the seed corpus of a fuzz test,
+// each seed being reported as a test.
+func FuzzSeedCorpus(gf *testing.F) {
+	fuzzSeedCorpusRan = !isFuzzing(gf)
+	f := (*F)(gf)
+	f.Add("hello")
+	f.Add("world")
+	f.Fuzz(func(t *testing.T, s string) {
+		if fuzzSeedCorpusRan {
+			seedExecutions++
+		}
+		if strings.ToUpper(strings.ToLower(s)) != strings.ToUpper(s) {
+			t.Errorf("unexpected case mapping of %q", s)
+		}
+	})
+}
+
 // BenchmarkFirst demonstrates benchmark instrumentation with sub-benchmarks.
 func BenchmarkFirst(gb *testing.B) {
 
@@ -0,0 +1,266 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2024 Datadog, Inc.
+
+// Package gotestjson reports to CI Visibility the tests of a `go test -json` output, for the test binaries
+// that can't be instrumented with gotesting.RunM. The session, module, suite and test hierarchy is rebuilt
+// from the events of the output through the CI Visibility manual API, with the timings of the events.
+package gotestjson
+
+import (
+	"bufio"
+	"encoding/json"
+	"io"
+	"os"
+	"regexp"
+	"strings"
+	"time"
+
+	"gopkg.in/DataDog/dd-trace-go.v1/civisibility"
+	"gopkg.in/DataDog/dd-trace-go.v1/internal/civisibility/constants"
+	"gopkg.in/DataDog/dd-trace-go.v1/internal/civisibility/utils"
+)
+
+const (
+	// testFramework represents the name of the testing framework.
+	testFramework = "golang.org/pkg/testing"
+
+	// maxTestOutputSize is the maximum size of the output reported for a test, only the end is kept beyond it.
+	maxTestOutputSize = 64 * 1024
+
+	// maxTestLogs is the maximum number of messages reported as the skip reason or error of a test, only the last ones are kept beyond it.
+	maxTestLogs = 100
+
+	// maxLineSize is the maximum size of a line of the `go test -json` output.
+	maxLineSize = 4 * 1024 * 1024
+)
+
+// logLocationRegex matches the location prefixing the messages logged by a test, such as "foo_test.go:12: ".
+var logLocationRegex = regexp.MustCompile(`^\S+\.go:\d+: `)
+
+// event is an event of the `go test -json` output, as described by `go doc test2json`.
+type event struct {
+	Time    time.Time
+	Action  string
+	Package string
+	Test    string
+	Elapsed float64
+	Output  string
+}
+
+type (
+	// importer rebuilds the CI Visibility events from the `go test -json` events.
+	importer struct {
+		cfg      *config
+		session  civisibility.DdTestSession
+		packages map[string]*packageState
+		lastTime time.Time
+		exitCode int
+	}
+
+	// packageState holds the CI Visibility events of a package being run.
+	packageState struct {
+		startTime time.Time
+		module    civisibility.DdTestModule
+		suite     civisibility.DdTestSuite
+		tests     map[string]*testState
+		output    strings.Builder
+	}
+
+	// testState holds the CI Visibility event of a test being run, and its output.
+	testState struct {
+		test      civisibility.DdTest
+		output    []byte
+		truncated bool
+		logs      []string
+	}
+)
+
+// Import reads the `go test -json` output from r and reports its tests to CI Visibility. Each package is
+// reported as a module with a single suite named after it, as the output doesn't tell the files of the
+// tests, and each test and subtest is reported as a test. It returns the exit code of the test session,
+// 1 if a package failed.
+//
+// The lines of r which aren't JSON events, such as the output of the go command, are ignored.
+func Import(r io.Reader, opts ...Option) (exitCode int, err error) {
+	cfg := new(config)
+	defaults(cfg)
+	for _, fn := range opts {
+		fn(cfg)
+	}
+
+	imp := &importer{cfg: cfg, packages: map[string]*packageState{}}
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(nil, maxLineSize)
+	for scanner.Scan() {
+		line := scanner.Bytes()
+		if len(line) == 0 || line[0] != '{' {
+			continue
+		}
+		var ev event
+		if err := json.Unmarshal(line, &ev); err != nil || ev.Action == "" {
+			continue
+		}
+		imp.handle(&ev)
+	}
+	err = scanner.Err()
+	imp.close()
+	return imp.exitCode, err
+}
+
+// handle updates the CI Visibility events with a `go test -json` event.
+func (imp *importer) handle(ev *event) {
+	if ev.Time.IsZero() {
+		ev.Time = time.Now()
+	}
+	imp.lastTime = ev.Time
+	if imp.session == nil {
+		imp.session = civisibility.CreateTestSessionWith(imp.cfg.command, imp.cfg.workingDirectory, "", ev.Time)
+	}
+	if ev.Package == "" {
+		return
+	}
+	pkg, ok := imp.packages[ev.Package]
+	if !ok {
+		pkg = &packageState{startTime: ev.Time, tests: map[string]*testState{}}
+		imp.packages[ev.Package] = pkg
+	}
+
+	if ev.Test == "" {
+		switch ev.Action {
+		case "output":
+			pkg.output.WriteString(ev.Output)
+		case "pass", "fail", "skip":
+			imp.closePackage(ev.Package, pkg, ev.Action == "fail", ev.Time)
+		}
+		return
+	}
+
+	switch ev.Action {
+	case "run":
+		imp.startTest(ev.Package, pkg, ev.Test, ev.Time)
+	case "output":
+		if test, ok := pkg.tests[ev.Test]; ok {
+			test.write(ev.Output)
+		}
+	case "pass":
+		imp.closeTest(pkg, ev.Test, civisibility.ResultStatusPass, ev.Time)
+	case "fail":
+		imp.closeTest(pkg, ev.Test, civisibility.ResultStatusFail, ev.Time)
+	case "skip":
+		imp.closeTest(pkg, ev.Test, civisibility.ResultStatusSkip, ev.Time)
+	}
+}
+
+// startTest creates the test event of a test starting to run, along with its module and suite.
+func (imp *importer) startTest(name string, pkg *packageState, testName string, startTime time.Time) {
+	if pkg.module == nil {
+		pkg.module = imp.session.GetOrCreateModuleWithFrameworkAndStartTime(name, testFramework, "", pkg.startTime)
+		pkg.suite = pkg.module.GetOrCreateSuiteWithStartTime(name, pkg.startTime)
+	}
+	if test, ok := pkg.tests[testName]; ok {
+		// The test is run again (-count): close its previous execution if it wasn't.
+		test.close(civisibility.ResultStatusFail, startTime)
+	}
+	pkg.tests[testName] = &testState{test: pkg.suite.CreateTestWithStartTime(testName, startTime)}
+}
+
+// closeTest closes the test event of a test which ran.
+func (imp *importer) closeTest(pkg *packageState, testName string, status civisibility.TestResultStatus, finishTime time.Time) {
+	test, ok := pkg.tests[testName]
+	if !ok {
+		return
+	}
+	test.close(status, finishTime)
+	delete(pkg.tests, testName)
+}
+
+// closePackage closes the events of a package which ran. A failed package without any test, such as a
+// package failing to build, is reported as a module with its output as error.
+func (imp *importer) closePackage(name string, pkg *packageState, failed bool, finishTime time.Time) {
+	delete(imp.packages, name)
+	if failed {
+		imp.exitCode = 1
+		if pkg.module == nil {
+			pkg.module = imp.session.GetOrCreateModuleWithFrameworkAndStartTime(name, testFramework, "", pkg.startTime)
+			pkg.module.SetErrorInfo("Fail", strings.TrimSpace(pkg.output.String()), "")
+		}
+	}
+	if pkg.module == nil {
+		return
+	}
+
+	// The tests still running when the package ends didn't complete, because of a panic or a timeout.
+	for _, test := range pkg.tests {
+		test.close(civisibility.ResultStatusFail, finishTime)
+	}
+	if pkg.suite != nil {
+		pkg.suite.CloseWithFinishTime(finishTime)
+	}
+	pkg.module.CloseWithFinishTime(finishTime)
+}
+
+// close closes the events of the packages still running, when the output ends prematurely, and the session.
+func (imp *importer) close() {
+	if imp.session == nil {
+		return
+	}
+	for name, pkg := range imp.packages {
+		if pkg.module != nil {
+			imp.closePackage(name, pkg, true, imp.lastTime)
+		}
+	}
+	imp.session.CloseWithFinishTime(imp.exitCode, imp.lastTime)
+}
+
+// write appends output to the output of the test, keeping its messages apart from the test framework lines.
+func (test *testState) write(output string) {
+	test.output = append(test.output, output...)
+	if n := len(test.output); n > maxTestOutputSize {
+		test.output = append(test.output[:0], test.output[n-maxTestOutputSize:]...)
+		test.truncated = true
+	}
+
+	line := strings.TrimSpace(output)
+	if line == "" || strings.HasPrefix(line, "=== ") || strings.HasPrefix(line, "--- ") {
+		return
+	}
+	test.logs = append(test.logs, logLocationRegex.ReplaceAllString(line, ""))
+	if len(test.logs) > maxTestLogs {
+		test.logs = test.logs[1:]
+	}
+}
+
+// close closes the test event with the given status, reporting the messages of the test as its skip
+// reason or error, along with its output when it failed.
+func (test *testState) close(status civisibility.TestResultStatus, finishTime time.Time) {
+	message := strings.Join(test.logs, "\n")
+	switch status {
+	case civisibility.ResultStatusFail:
+		if message == "" {
+			message = "failed test"
+		}
+		test.test.SetErrorInfo("Fail", message, "")
+		if len(test.output) > 0 {
+			test.test.SetTag(constants.TestOutput, string(test.output))
+			if test.truncated {
+				test.test.SetTag(constants.TestOutputTruncated, "true")
+			}
+		}
+		test.test.CloseWithFinishTime(status, finishTime)
+	case civisibility.ResultStatusSkip:
+		test.test.CloseWithFinishTimeAndSkipReason(status, finishTime, message)
+	default:
+		test.test.CloseWithFinishTime(status, finishTime)
+	}
+}
+
+// getWorkingDirectory returns the working directory relative to the root of the sources.
+func getWorkingDirectory() string {
+	wd, err := os.Getwd()
+	if err != nil {
+		return ""
+	}
+	return utils.GetRelativePathFromCiTagsSourceRoot(wd)
+}
@@ -0,0 +1,137 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2024 Datadog, Inc.
+
+package gotestjson
+
+import (
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"gopkg.in/DataDog/dd-trace-go.v1/ddtrace/ext"
+	"gopkg.in/DataDog/dd-trace-go.v1/ddtrace/mocktracer"
+	"gopkg.in/DataDog/dd-trace-go.v1/internal/civisibility"
+	"gopkg.in/DataDog/dd-trace-go.v1/internal/civisibility/constants"
+)
+
+var mt mocktracer.Tracer
+
+func TestMain(m *testing.M) {
+	// Initialize civisibility using the mocktracer for testing
+	mt = civisibility.InitializeCiVisibilityMock()
+	os.Exit(m.Run())
+}
+
+// testOutput is the `go test -json` output of two packages, one of them failing, and of a package without tests.
+const testOutput = `go: downloading github.com/example/dependency v1.0.0
+{"Time":"2024-09-01T10:00:00Z","Action":"start","Package":"example.com/foo"}
+{"Time":"2024-09-01T10:00:01Z","Action":"run","Package":"example.com/foo","Test":"TestPass"}
+{"Time":"2024-09-01T10:00:01Z","Action":"output","Package":"example.com/foo","Test":"TestPass","Output":"=== RUN   TestPass\n"}
+{"Time":"2024-09-01T10:00:02Z","Action":"output","Package":"example.com/foo","Test":"TestPass","Output":"--- PASS: TestPass (1.00s)\n"}
+{"Time":"2024-09-01T10:00:02Z","Action":"pass","Package":"example.com/foo","Test":"TestPass","Elapsed":1}
+{"Time":"2024-09-01T10:00:02Z","Action":"run","Package":"example.com/foo","Test":"TestFail"}
+{"Time":"2024-09-01T10:00:02Z","Action":"output","Package":"example.com/foo","Test":"TestFail","Output":"=== RUN   TestFail\n"}
+{"Time":"2024-09-01T10:00:02Z","Action":"run","Package":"example.com/foo","Test":"TestFail/sub"}
+{"Time":"2024-09-01T10:00:02Z","Action":"output","Package":"example.com/foo","Test":"TestFail/sub","Output":"=== RUN   TestFail/sub\n"}
+{"Time":"2024-09-01T10:00:03Z","Action":"output","Package":"example.com/foo","Test":"TestFail/sub","Output":"    foo_test.go:12: unexpected value\n"}
+{"Time":"2024-09-01T10:00:03Z","Action":"output","Package":"example.com/foo","Test":"TestFail/sub","Output":"    --- FAIL: TestFail/sub (1.00s)\n"}
+{"Time":"2024-09-01T10:00:03Z","Action":"fail","Package":"example.com/foo","Test":"TestFail/sub","Elapsed":1}
+{"Time":"2024-09-01T10:00:03Z","Action":"output","Package":"example.com/foo","Test":"TestFail","Output":"--- FAIL: TestFail (1.00s)\n"}
+{"Time":"2024-09-01T10:00:03Z","Action":"fail","Package":"example.com/foo","Test":"TestFail","Elapsed":1}
+{"Time":"2024-09-01T10:00:03Z","Action":"run","Package":"example.com/foo","Test":"TestSkip"}
+{"Time":"2024-09-01T10:00:03Z","Action":"output","Package":"example.com/foo","Test":"TestSkip","Output":"=== RUN   TestSkip\n"}
+{"Time":"2024-09-01T10:00:03Z","Action":"output","Package":"example.com/foo","Test":"TestSkip","Output":"    foo_test.go:20: not supported\n"}
+{"Time":"2024-09-01T10:00:03Z","Action":"output","Package":"example.com/foo","Test":"TestSkip","Output":"--- SKIP: TestSkip (0.00s)\n"}
+{"Time":"2024-09-01T10:00:03Z","Action":"skip","Package":"example.com/foo","Test":"TestSkip","Elapsed":0}
+{"Time":"2024-09-01T10:00:04Z","Action":"output","Package":"example.com/foo","Output":"FAIL\n"}
+{"Time":"2024-09-01T10:00:04Z","Action":"fail","Package":"example.com/foo","Elapsed":4}
+{"Time":"2024-09-01T10:00:00Z","Action":"start","Package":"example.com/bar"}
+{"Time":"2024-09-01T10:00:01Z","Action":"run","Package":"example.com/bar","Test":"TestBar"}
+{"Time":"2024-09-01T10:00:02Z","Action":"pass","Package":"example.com/bar","Test":"TestBar","Elapsed":1}
+{"Time":"2024-09-01T10:00:02Z","Action":"pass","Package":"example.com/bar","Elapsed":2}
+{"Time":"2024-09-01T10:00:05Z","Action":"start","Package":"example.com/empty"}
+{"Time":"2024-09-01T10:00:05Z","Action":"output","Package":"example.com/empty","Output":"?   \texample.com/empty\t[no test files]\n"}
+{"Time":"2024-09-01T10:00:05Z","Action":"skip","Package":"example.com/empty","Elapsed":0}
+`
+
+func TestImport(t *testing.T) {
+	mt.Reset()
+	exitCode, err := Import(strings.NewReader(testOutput), WithCommand("go test -json ./..."), WithWorkingDirectory("."))
+	assert.NoError(t, err)
+	assert.Equal(t, 1, exitCode)
+
+	spans := map[string]mocktracer.Span{}
+	for _, span := range mt.FinishedSpans() {
+		switch span.Tag(ext.SpanType) {
+		case constants.SpanTypeTest:
+			spans[span.Tag(constants.TestName).(string)] = span
+		case constants.SpanTypeTestModule:
+			spans[span.Tag(constants.TestModule).(string)] = span
+		case constants.SpanTypeTestSuite:
+			spans["suite "+span.Tag(constants.TestSuite).(string)] = span
+		default:
+			spans[span.Tag(ext.SpanType).(string)] = span
+		}
+	}
+	assert.Len(t, spans, 10)
+	at := func(s string) time.Time {
+		v, _ := time.Parse(time.RFC3339, s)
+		return v
+	}
+
+	session := spans[constants.SpanTypeTestSession]
+	assert.Equal(t, "go test -json ./...", session.Tag(constants.TestCommand))
+	assert.Equal(t, 1, session.Tag(constants.TestCommandExitCode))
+	assert.Equal(t, constants.TestStatusFail, session.Tag(constants.TestStatus))
+	assert.Equal(t, at("2024-09-01T10:00:00Z"), session.StartTime())
+	assert.Equal(t, at("2024-09-01T10:00:05Z"), session.FinishTime())
+
+	module := spans["example.com/foo"]
+	assert.Equal(t, testFramework, module.Tag(constants.TestFramework))
+	assert.Equal(t, at("2024-09-01T10:00:00Z"), module.StartTime())
+	assert.Equal(t, at("2024-09-01T10:00:04Z"), module.FinishTime())
+	assert.Equal(t, "example.com/foo", spans["suite example.com/foo"].Tag(constants.TestModule))
+	assert.Equal(t, at("2024-09-01T10:00:02Z"), spans["example.com/bar"].FinishTime())
+
+	pass := spans["TestPass"]
+	assert.Equal(t, constants.TestStatusPass, pass.Tag(constants.TestStatus))
+	assert.Equal(t, at("2024-09-01T10:00:01Z"), pass.StartTime())
+	assert.Equal(t, at("2024-09-01T10:00:02Z"), pass.FinishTime())
+	assert.Nil(t, pass.Tag(constants.TestOutput))
+
+	sub := spans["TestFail/sub"]
+	assert.Equal(t, constants.TestStatusFail, sub.Tag(constants.TestStatus))
+	assert.Equal(t, "unexpected value", sub.Tag(ext.ErrorMsg))
+	assert.Equal(t, "=== RUN   TestFail/sub\n    foo_test.go:12: unexpected value\n    --- FAIL: TestFail/sub (1.00s)\n", sub.Tag(constants.TestOutput))
+	assert.Equal(t, constants.TestStatusFail, spans["TestFail"].Tag(constants.TestStatus))
+
+	skip := spans["TestSkip"]
+	assert.Equal(t, constants.TestStatusSkip, skip.Tag(constants.TestStatus))
+	assert.Equal(t, "not supported", skip.Tag(constants.TestSkipReason))
+}
+
+func TestImportIncompleteOutput(t *testing.T) {
+	mt.Reset()
+	exitCode, err := Import(strings.NewReader(`{"Time":"2024-09-01T10:00:00Z","Action":"start","Package":"example.com/foo"}
+{"Time":"2024-09-01T10:00:01Z","Action":"run","Package":"example.com/foo","Test":"TestTimeout"}
+{"Time":"2024-09-01T10:00:02Z","Action":"output","Package":"example.com/foo","Test":"TestTimeout","Output":"panic: test timed out after 1s\n"}
+`))
+	assert.NoError(t, err)
+	assert.Equal(t, 1, exitCode)
+
+	var test mocktracer.Span
+	for _, span := range mt.FinishedSpans() {
+		if span.Tag(ext.SpanType) == constants.SpanTypeTest {
+			test = span
+		}
+	}
+	if assert.NotNil(t, test) {
+		assert.Equal(t, constants.TestStatusFail, test.Tag(constants.TestStatus))
+		assert.Equal(t, "panic: test timed out after 1s", test.Tag(ext.ErrorMsg))
+		assert.Equal(t, time.Date(2024, 9, 1, 10, 0, 2, 0, time.UTC), test.FinishTime())
+	}
+}
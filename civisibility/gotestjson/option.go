@@ -0,0 +1,34 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2024 Datadog, Inc.
+
+package gotestjson
+
+type config struct {
+	command          string
+	workingDirectory string
+}
+
+// Option represents an option that can be passed to Import.
+type Option func(*config)
+
+func defaults(cfg *config) {
+	cfg.command = "go test -json"
+	cfg.workingDirectory = getWorkingDirectory()
+}
+
+// WithCommand sets the command reported for the test session, "go test -json" by default.
+func WithCommand(command string) Option {
+	return func(cfg *config) {
+		cfg.command = command
+	}
+}
+
+// WithWorkingDirectory sets the working directory reported for the test session, relative to the root
+// of the sources. It defaults to the current working directory.
+func WithWorkingDirectory(workingDirectory string) Option {
+	return func(cfg *config) {
+		cfg.workingDirectory = workingDirectory
+	}
+}
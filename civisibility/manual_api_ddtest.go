@@ -135,7 +135,11 @@ func (t *tslvTest) SetTestFunc(fn *runtime.Func) {
 		return
 	}
 
-	file, line := fn.FileLine(fn.Entry())
+	t.SetTestSource(fn.FileLine(fn.Entry()))
+}
+
+// SetTestSource records the source location of the test, for the tests which aren't Go functions.
+func (t *tslvTest) SetTestSource(file string, line int) {
 	file = utils.GetRelativePathFromCiTagsSourceRoot(file)
 	t.SetTag(constants.TestSourceFile, file)
 	t.SetTag(constants.TestSourceStartLine, line)
@@ -0,0 +1,36 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2024 Datadog, Inc.
+
+package sarama
+
+import (
+	"strings"
+
+	"gopkg.in/DataDog/dd-trace-go.v1/ddtrace"
+	"gopkg.in/DataDog/dd-trace-go.v1/ddtrace/ext"
+	"gopkg.in/DataDog/dd-trace-go.v1/internal/appsec/emitter/messagingsec"
+	"gopkg.in/DataDog/dd-trace-go.v1/internal/appsec/emitter/messagingsec/types"
+
+	"github.com/IBM/sarama"
+)
+
+// monitorMessage monitors the headers and payload of the consumed message with
+// AppSec, and tags the consumer span with the resulting security events.
+func monitorMessage(span ddtrace.Span, msg *sarama.ConsumerMessage) {
+	headers := make(map[string][]string, len(msg.Headers))
+	for _, h := range msg.Headers {
+		if h == nil {
+			continue
+		}
+		k := strings.ToLower(string(h.Key))
+		headers[k] = append(headers[k], string(h.Value))
+	}
+	messagingsec.MonitorConsumedMessage(span, types.ConsumeOperationArgs{
+		System:  ext.MessagingSystemKafka,
+		Topic:   msg.Topic,
+		Headers: headers,
+		Payload: messagingsec.DecodePayload(msg.Value, headers),
+	})
+}
@@ -0,0 +1,77 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2024 Datadog, Inc.
+
+package sarama
+
+import (
+	"testing"
+
+	"gopkg.in/DataDog/dd-trace-go.v1/ddtrace/mocktracer"
+	"gopkg.in/DataDog/dd-trace-go.v1/internal/appsec"
+
+	"github.com/IBM/sarama"
+	"github.com/IBM/sarama/mocks"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAppSec(t *testing.T) {
+	appsec.Start()
+	defer appsec.Stop()
+	if !appsec.Enabled() {
+		t.Skip("AppSec needs to be enabled for this test")
+	}
+
+	for _, tc := range []struct {
+		name    string
+		msg     *sarama.ConsumerMessage
+		matches []string
+	}{
+		{
+			name: "no-attack",
+			msg:  &sarama.ConsumerMessage{Value: []byte(`{"name":"jane"}`)},
+		},
+		{
+			name:    "payload",
+			msg:     &sarama.ConsumerMessage{Value: []byte(`{"comment":"<script>alert(1)</script>"}`)},
+			matches: []string{"crs-941-390", "server.request.body"},
+		},
+		{
+			name: "headers",
+			msg: &sarama.ConsumerMessage{
+				Value:   []byte("hello"),
+				Headers: []*sarama.RecordHeader{{Key: []byte("User-Agent"), Value: []byte("Arachni/v1")}},
+			},
+			matches: []string{"ua0-600-12x", "server.request.headers.no_cookies"},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			mt := mocktracer.Start()
+			defer mt.Stop()
+
+			consumer := mocks.NewConsumer(t, nil)
+			consumer.ExpectConsumePartition("test-topic", 0, 0).YieldMessage(tc.msg)
+			pc, err := WrapConsumer(consumer).ConsumePartition("test-topic", 0, 0)
+			require.NoError(t, err)
+			<-pc.Messages()
+			require.NoError(t, pc.Close())
+			// wait for the channel to be closed
+			<-pc.Messages()
+
+			spans := mt.FinishedSpans()
+			require.Len(t, spans, 1)
+			consume := spans[0]
+			require.Equal(t, "kafka.consume", consume.OperationName())
+			require.Equal(t, 1, consume.Tag("_dd.appsec.enabled"))
+			if len(tc.matches) == 0 {
+				require.Nil(t, consume.Tag("_dd.appsec.json"))
+				return
+			}
+			require.Equal(t, true, consume.Tag("appsec.event"))
+			for _, id := range tc.matches {
+				require.Contains(t, consume.Tag("_dd.appsec.json"), id)
+			}
+		})
+	}
+}
@@ -0,0 +1,33 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2024 Datadog, Inc.
+
+package pubsub
+
+import (
+	"strings"
+
+	"gopkg.in/DataDog/dd-trace-go.v1/ddtrace"
+	"gopkg.in/DataDog/dd-trace-go.v1/ddtrace/ext"
+	"gopkg.in/DataDog/dd-trace-go.v1/internal/appsec/emitter/messagingsec"
+	"gopkg.in/DataDog/dd-trace-go.v1/internal/appsec/emitter/messagingsec/types"
+
+	"cloud.google.com/go/pubsub"
+)
+
+// monitorMessage monitors the attributes and payload of the received message
+// with AppSec, and tags the receive span with the resulting security events.
+func monitorMessage(span ddtrace.Span, s *pubsub.Subscription, msg *pubsub.Message) {
+	attrs := make(map[string][]string, len(msg.Attributes))
+	for k, v := range msg.Attributes {
+		k := strings.ToLower(k)
+		attrs[k] = append(attrs[k], v)
+	}
+	messagingsec.MonitorConsumedMessage(span, types.ConsumeOperationArgs{
+		System:  ext.MessagingSystemGCPPubsub,
+		Topic:   s.String(),
+		Headers: attrs,
+		Payload: messagingsec.DecodePayload(msg.Data, attrs),
+	})
+}
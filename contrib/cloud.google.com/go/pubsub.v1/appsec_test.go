@@ -0,0 +1,71 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2024 Datadog, Inc.
+
+package pubsub
+
+import (
+	"context"
+	"testing"
+
+	"gopkg.in/DataDog/dd-trace-go.v1/internal/appsec"
+
+	"cloud.google.com/go/pubsub"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAppSec(t *testing.T) {
+	appsec.Start()
+	defer appsec.Stop()
+	if !appsec.Enabled() {
+		t.Skip("AppSec needs to be enabled for this test")
+	}
+
+	for _, tc := range []struct {
+		name    string
+		msg     *pubsub.Message
+		matches []string
+	}{
+		{
+			name: "no-attack",
+			msg:  &pubsub.Message{Data: []byte(`{"name":"jane"}`)},
+		},
+		{
+			name:    "payload",
+			msg:     &pubsub.Message{Data: []byte(`{"comment":"<script>alert(1)</script>"}`)},
+			matches: []string{"crs-941-390", "server.request.body"},
+		},
+		{
+			name:    "attributes",
+			msg:     &pubsub.Message{Data: []byte("hello"), Attributes: map[string]string{"User-Agent": "Arachni/v1"}},
+			matches: []string{"ua0-600-12x", "server.request.headers.no_cookies"},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			ctx, cancel, mt, topic, sub := setup(t)
+			_, err := Publish(ctx, topic, tc.msg).Get(ctx)
+			require.NoError(t, err)
+
+			err = sub.Receive(ctx, WrapReceiveHandler(sub, func(_ context.Context, msg *pubsub.Message) {
+				msg.Ack()
+				cancel()
+			}))
+			require.NoError(t, err)
+
+			spans := mt.FinishedSpans()
+			require.Len(t, spans, 2)
+			receive := spans[1]
+			require.Equal(t, "pubsub.receive", receive.OperationName())
+			require.Equal(t, 1, receive.Tag("_dd.appsec.enabled"))
+			if len(tc.matches) == 0 {
+				require.Nil(t, receive.Tag("_dd.appsec.json"))
+				return
+			}
+			require.Equal(t, true, receive.Tag("appsec.event"))
+			for _, id := range tc.matches {
+				require.Contains(t, receive.Tag("_dd.appsec.json"), id)
+			}
+		})
+	}
+}
@@ -6,22 +6,25 @@
 package pubsub
 
 import (
+	"gopkg.in/DataDog/dd-trace-go.v1/internal"
 	"gopkg.in/DataDog/dd-trace-go.v1/internal/namingschema"
 )
 
 type config struct {
-	serviceName     string
-	publishSpanName string
-	receiveSpanName string
-	measured        bool
+	serviceName        string
+	publishSpanName    string
+	receiveSpanName    string
+	measured           bool
+	dataStreamsEnabled bool
 }
 
 func defaultConfig() *config {
 	return &config{
-		serviceName:     namingschema.ServiceNameOverrideV0("", ""),
-		publishSpanName: namingschema.OpName(namingschema.GCPPubSubOutbound),
-		receiveSpanName: namingschema.OpName(namingschema.GCPPubSubInbound),
-		measured:        false,
+		serviceName:        namingschema.ServiceNameOverrideV0("", ""),
+		publishSpanName:    namingschema.OpName(namingschema.GCPPubSubOutbound),
+		receiveSpanName:    namingschema.OpName(namingschema.GCPPubSubInbound),
+		measured:           false,
+		dataStreamsEnabled: internal.BoolEnv("DD_DATA_STREAMS_ENABLED", false),
 	}
 }
 
@@ -44,3 +47,10 @@ func WithMeasured() Option {
 		cfg.measured = true
 	}
 }
+
+// WithDataStreams enables the Data Streams monitoring product features: https://www.datadoghq.com/product/data-streams-monitoring/
+func WithDataStreams() Option {
+	return func(cfg *config) {
+		cfg.dataStreamsEnabled = true
+	}
+}
@@ -10,9 +10,12 @@ import (
 	"context"
 	"sync"
 
+	"gopkg.in/DataDog/dd-trace-go.v1/datastreams"
+	"gopkg.in/DataDog/dd-trace-go.v1/datastreams/options"
 	"gopkg.in/DataDog/dd-trace-go.v1/ddtrace"
 	"gopkg.in/DataDog/dd-trace-go.v1/ddtrace/ext"
 	"gopkg.in/DataDog/dd-trace-go.v1/ddtrace/tracer"
+	"gopkg.in/DataDog/dd-trace-go.v1/internal/appsec"
 	"gopkg.in/DataDog/dd-trace-go.v1/internal/log"
 	"gopkg.in/DataDog/dd-trace-go.v1/internal/telemetry"
 
@@ -63,6 +66,7 @@ func Publish(ctx context.Context, t *pubsub.Topic, msg *pubsub.Message, opts ...
 	if err := tracer.Inject(span.Context(), tracer.TextMapCarrier(msg.Attributes)); err != nil {
 		log.Debug("contrib/cloud.google.com/go/pubsub.v1/: failed injecting tracing attributes: %v", err)
 	}
+	setProduceCheckpoint(ctx, cfg.dataStreamsEnabled, t, msg)
 	span.SetTag("num_attributes", len(msg.Attributes))
 	return &PublishResult{
 		PublishResult: t.Publish(ctx, msg),
@@ -123,6 +127,39 @@ func WrapReceiveHandler(s *pubsub.Subscription, f func(context.Context, *pubsub.
 			span.SetTag("delivery_attempt", *msg.DeliveryAttempt)
 		}
 		defer span.Finish()
-		f(ctx, msg)
+		if appsec.Enabled() {
+			monitorMessage(span, s, msg)
+		}
+		f(setConsumeCheckpoint(ctx, cfg.dataStreamsEnabled, s, msg), msg)
+	}
+}
+
+func setProduceCheckpoint(ctx context.Context, enabled bool, t *pubsub.Topic, msg *pubsub.Message) {
+	if !enabled {
+		return
+	}
+	edges := []string{"direction:out", "topic:" + t.String(), "type:google-pubsub"}
+	carrier := tracer.TextMapCarrier(msg.Attributes)
+	ctx, ok := tracer.SetDataStreamsCheckpointWithParams(datastreams.ExtractFromBase64Carrier(ctx, carrier), options.CheckpointParams{PayloadSize: getMsgSize(msg)}, edges...)
+	if !ok {
+		return
+	}
+	datastreams.InjectToBase64Carrier(ctx, carrier)
+}
+
+func setConsumeCheckpoint(ctx context.Context, enabled bool, s *pubsub.Subscription, msg *pubsub.Message) context.Context {
+	if !enabled {
+		return ctx
+	}
+	edges := []string{"direction:in", "subscription:" + s.String(), "type:google-pubsub"}
+	carrier := tracer.TextMapCarrier(msg.Attributes)
+	ctx, _ = tracer.SetDataStreamsCheckpointWithParams(datastreams.ExtractFromBase64Carrier(ctx, carrier), options.CheckpointParams{PayloadSize: getMsgSize(msg)}, edges...)
+	return ctx
+}
+
+func getMsgSize(msg *pubsub.Message) (size int64) {
+	for k, v := range msg.Attributes {
+		size += int64(len(k) + len(v))
 	}
+	return size + int64(len(msg.Data))
 }
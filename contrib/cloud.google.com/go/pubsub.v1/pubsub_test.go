@@ -11,6 +11,7 @@ import (
 	"time"
 
 	"gopkg.in/DataDog/dd-trace-go.v1/contrib/internal/namingschematest"
+	"gopkg.in/DataDog/dd-trace-go.v1/datastreams"
 	"gopkg.in/DataDog/dd-trace-go.v1/ddtrace/ext"
 	"gopkg.in/DataDog/dd-trace-go.v1/ddtrace/mocktracer"
 	"gopkg.in/DataDog/dd-trace-go.v1/ddtrace/tracer"
@@ -238,6 +239,56 @@ func TestPropagationNoPublisherSpan(t *testing.T) {
 	}, spans[0].Tags())
 }
 
+func TestDataStreams(t *testing.T) {
+	assert := assert.New(t)
+	ctx, cancel, _, topic, sub := setup(t)
+
+	// Publisher
+	msg := &pubsub.Message{Data: []byte("hello")}
+	_, err := Publish(ctx, topic, msg, WithDataStreams()).Get(ctx)
+	assert.NoError(err)
+
+	p, ok := datastreams.PathwayFromContext(datastreams.ExtractFromBase64Carrier(context.Background(), tracer.TextMapCarrier(msg.Attributes)))
+	assert.True(ok)
+	expectedCtx, _ := tracer.SetDataStreamsCheckpoint(context.Background(), "direction:out", "topic:projects/project/topics/topic", "type:google-pubsub")
+	expected, _ := datastreams.PathwayFromContext(expectedCtx)
+	assert.NotEqual(expected.GetHash(), 0)
+	assert.Equal(expected.GetHash(), p.GetHash())
+
+	// Subscriber
+	var called bool
+	err = sub.Receive(ctx, WrapReceiveHandler(sub, func(ctx context.Context, msg *pubsub.Message) {
+		p, ok := datastreams.PathwayFromContext(ctx)
+		assert.True(ok)
+		expectedCtx, _ = tracer.SetDataStreamsCheckpoint(expectedCtx, "direction:in", "subscription:projects/project/subscriptions/subscription", "type:google-pubsub")
+		expected, _ := datastreams.PathwayFromContext(expectedCtx)
+		assert.NotEqual(expected.GetHash(), 0)
+		assert.Equal(expected.GetHash(), p.GetHash())
+		msg.Ack()
+		called = true
+		cancel()
+	}, WithDataStreams()))
+	assert.True(called, "callback not called")
+	assert.NoError(err)
+}
+
+func TestDataStreamsActivation(t *testing.T) {
+	t.Run("default", func(t *testing.T) {
+		cfg := defaultConfig()
+		assert.False(t, cfg.dataStreamsEnabled)
+	})
+	t.Run("withOption", func(t *testing.T) {
+		cfg := defaultConfig()
+		WithDataStreams()(cfg)
+		assert.True(t, cfg.dataStreamsEnabled)
+	})
+	t.Run("withEnv", func(t *testing.T) {
+		t.Setenv("DD_DATA_STREAMS_ENABLED", "true")
+		cfg := defaultConfig()
+		assert.True(t, cfg.dataStreamsEnabled)
+	})
+}
+
 func TestNamingSchema(t *testing.T) {
 	genSpans := namingschematest.GenSpansFn(func(t *testing.T, serviceOverride string) []mocktracer.Span {
 		var opts []Option
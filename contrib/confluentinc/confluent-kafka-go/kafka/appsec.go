@@ -0,0 +1,37 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2024 Datadog, Inc.
+
+package kafka
+
+import (
+	"strings"
+
+	"gopkg.in/DataDog/dd-trace-go.v1/ddtrace"
+	"gopkg.in/DataDog/dd-trace-go.v1/ddtrace/ext"
+	"gopkg.in/DataDog/dd-trace-go.v1/internal/appsec/emitter/messagingsec"
+	"gopkg.in/DataDog/dd-trace-go.v1/internal/appsec/emitter/messagingsec/types"
+
+	"github.com/confluentinc/confluent-kafka-go/kafka"
+)
+
+// monitorMessage monitors the headers and payload of the consumed message with
+// AppSec, and tags the consumer span with the resulting security events.
+func monitorMessage(span ddtrace.Span, msg *kafka.Message) {
+	headers := make(map[string][]string, len(msg.Headers))
+	for _, h := range msg.Headers {
+		k := strings.ToLower(h.Key)
+		headers[k] = append(headers[k], string(h.Value))
+	}
+	var topic string
+	if msg.TopicPartition.Topic != nil {
+		topic = *msg.TopicPartition.Topic
+	}
+	messagingsec.MonitorConsumedMessage(span, types.ConsumeOperationArgs{
+		System:  ext.MessagingSystemKafka,
+		Topic:   topic,
+		Headers: headers,
+		Payload: messagingsec.DecodePayload(msg.Value, headers),
+	})
+}
@@ -15,6 +15,8 @@ import (
 	"gopkg.in/DataDog/dd-trace-go.v1/ddtrace"
 	"gopkg.in/DataDog/dd-trace-go.v1/ddtrace/ext"
 	"gopkg.in/DataDog/dd-trace-go.v1/ddtrace/tracer"
+	"gopkg.in/DataDog/dd-trace-go.v1/internal/appsec"
+	"gopkg.in/DataDog/dd-trace-go.v1/internal/appsec/emitter/sqlsec"
 	"gopkg.in/DataDog/dd-trace-go.v1/internal/log"
 )
 
@@ -52,6 +54,10 @@ const (
 type TracedConn struct {
 	driver.Conn
 	*traceParams
+
+	// skippedQuery is the last query checked for SQL injections whose execution was skipped by
+	// the driver, which database/sql prepares next on the same connection without a new check.
+	skippedQuery string
 }
 
 // WrappedConn returns the wrapped connection object.
@@ -106,6 +112,12 @@ func (tc *TracedConn) PrepareContext(ctx context.Context, query string) (stmt dr
 		// no context other than service in prepared statements
 		mode = tracer.DBMPropagationModeService
 	}
+	if query == "" || query != tc.skippedQuery {
+		if err := tc.protectQuery(ctx, query); err != nil {
+			return nil, err
+		}
+	}
+	tc.skippedQuery = ""
 	cquery, spanID := tc.injectComments(ctx, query, mode)
 	if connPrepareCtx, ok := tc.Conn.(driver.ConnPrepareContext); ok {
 		ctx, end := startTraceTask(ctx, QueryTypePrepare)
@@ -132,11 +144,15 @@ func (tc *TracedConn) PrepareContext(ctx context.Context, query string) (stmt dr
 func (tc *TracedConn) ExecContext(ctx context.Context, query string, args []driver.NamedValue) (r driver.Result, err error) {
 	start := time.Now()
 	if execContext, ok := tc.Conn.(driver.ExecerContext); ok {
+		if err := tc.protectQuery(ctx, query); err != nil {
+			return nil, err
+		}
 		cquery, spanID := tc.injectComments(ctx, query, tc.cfg.dbmPropagationMode)
 		ctx, end := startTraceTask(ctx, QueryTypeExec)
 		defer end()
 		r, err := execContext.ExecContext(ctx, cquery, args)
 		tc.tryTrace(ctx, QueryTypeExec, query, start, err, append(withDBMTraceInjectedTag(tc.cfg.dbmPropagationMode), tracer.WithSpanID(spanID))...)
+		tc.skipQuery(query, err)
 		return r, err
 	}
 	if execer, ok := tc.Conn.(driver.Execer); ok {
@@ -149,11 +165,15 @@ func (tc *TracedConn) ExecContext(ctx context.Context, query string, args []driv
 			return nil, ctx.Err()
 		default:
 		}
+		if err := tc.protectQuery(ctx, query); err != nil {
+			return nil, err
+		}
 		cquery, spanID := tc.injectComments(ctx, query, tc.cfg.dbmPropagationMode)
 		ctx, end := startTraceTask(ctx, QueryTypeExec)
 		defer end()
 		r, err = execer.Exec(cquery, dargs)
 		tc.tryTrace(ctx, QueryTypeExec, query, start, err, append(withDBMTraceInjectedTag(tc.cfg.dbmPropagationMode), tracer.WithSpanID(spanID))...)
+		tc.skipQuery(query, err)
 		return r, err
 	}
 	return nil, driver.ErrSkip
@@ -176,11 +196,15 @@ func (tc *TracedConn) Ping(ctx context.Context) (err error) {
 func (tc *TracedConn) QueryContext(ctx context.Context, query string, args []driver.NamedValue) (rows driver.Rows, err error) {
 	start := time.Now()
 	if queryerContext, ok := tc.Conn.(driver.QueryerContext); ok {
+		if err := tc.protectQuery(ctx, query); err != nil {
+			return nil, err
+		}
 		cquery, spanID := tc.injectComments(ctx, query, tc.cfg.dbmPropagationMode)
 		ctx, end := startTraceTask(ctx, QueryTypeQuery)
 		defer end()
 		rows, err := queryerContext.QueryContext(ctx, cquery, args)
 		tc.tryTrace(ctx, QueryTypeQuery, query, start, err, append(withDBMTraceInjectedTag(tc.cfg.dbmPropagationMode), tracer.WithSpanID(spanID))...)
+		tc.skipQuery(query, err)
 		return rows, err
 	}
 	if queryer, ok := tc.Conn.(driver.Queryer); ok {
@@ -193,11 +217,15 @@ func (tc *TracedConn) QueryContext(ctx context.Context, query string, args []dri
 			return nil, ctx.Err()
 		default:
 		}
+		if err := tc.protectQuery(ctx, query); err != nil {
+			return nil, err
+		}
 		cquery, spanID := tc.injectComments(ctx, query, tc.cfg.dbmPropagationMode)
 		ctx, end := startTraceTask(ctx, QueryTypeQuery)
 		defer end()
 		rows, err = queryer.Query(cquery, dargs)
 		tc.tryTrace(ctx, QueryTypeQuery, query, start, err, append(withDBMTraceInjectedTag(tc.cfg.dbmPropagationMode), tracer.WithSpanID(spanID))...)
+		tc.skipQuery(query, err)
 		return rows, err
 	}
 	return nil, driver.ErrSkip
@@ -292,6 +320,24 @@ func withDBMTraceInjectedTag(mode tracer.DBMPropagationMode) []tracer.StartSpanO
 	return nil
 }
 
+// protectQuery checks the given query for SQL injections when RASP is enabled. A *events.BlockingSecurityEvent
+// error is returned when the query must be blocked, in which case it must not be sent to the driver.
+func (tp *traceParams) protectQuery(ctx context.Context, query string) error {
+	if !appsec.RASPEnabled() {
+		return nil
+	}
+	dbSystem, _ := normalizeDBSystem(tp.driverName)
+	return sqlsec.ProtectSQLOperation(ctx, query, dbSystem)
+}
+
+// skipQuery records the given query as skipped when its execution failed with driver.ErrSkip, as
+// database/sql then prepares it after it has already been checked for SQL injections.
+func (tc *TracedConn) skipQuery(query string, err error) {
+	if err == driver.ErrSkip {
+		tc.skippedQuery = query
+	}
+}
+
 // tryTrace will create a span using the given arguments, but will act as a no-op when err is driver.ErrSkip.
 func (tp *traceParams) tryTrace(ctx context.Context, qtype QueryType, query string, startTime time.Time, err error, spanOpts ...ddtrace.StartSpanOption) {
 	if err == driver.ErrSkip {
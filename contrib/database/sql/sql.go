@@ -164,7 +164,7 @@ func (t *tracedConnector) Connect(ctx context.Context) (driver.Conn, error) {
 	if err != nil {
 		return nil, err
 	}
-	return &TracedConn{conn, tp}, err
+	return &TracedConn{Conn: conn, traceParams: tp}, err
 }
 
 func (t *tracedConnector) Driver() driver.Driver {
@@ -26,9 +26,31 @@ func useAppSec(c *gin.Context, span tracer.Span) {
 	}
 	httpWrapper := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		c.Request = r
+		// The response body is written through c.Writer rather than w
+		if body := httpsec.ResponseBodyRecorder(r.Context()); body != nil {
+			writer := c.Writer
+			c.Writer = &responseBodyWriter{ResponseWriter: writer, body: httpsec.NewResponseBodyWriter(writer, body)}
+			defer func() { c.Writer = writer }()
+		}
 		c.Next()
 	})
 	httpsec.WrapHandler(httpWrapper, span, params, &httpsec.Config{
 		OnBlock: []func(){func() { c.Abort() }},
+		Route:   c.FullPath(),
 	}).ServeHTTP(c.Writer, c.Request)
 }
+
+// responseBodyWriter tees the response body to the AppSec response body
+// recorder.
+type responseBodyWriter struct {
+	gin.ResponseWriter
+	body *httpsec.ResponseBodyWriter
+}
+
+func (w *responseBodyWriter) Write(b []byte) (int, error) {
+	return w.body.Write(b)
+}
+
+func (w *responseBodyWriter) WriteString(s string) (int, error) {
+	return w.body.WriteString(s)
+}
@@ -434,3 +434,39 @@ func TestBlocking(t *testing.T) {
 		}
 	})
 }
+
+// Test that API Security extracts the schema of the JSON response bodies
+// written by gin handlers
+func TestAPISecurityResponseBody(t *testing.T) {
+	t.Setenv("DD_API_SECURITY_ENABLED", "true")
+	t.Setenv("DD_API_SECURITY_REQUEST_SAMPLE_RATE", "1.0")
+	t.Setenv("DD_API_SECURITY_RESPONSE_BODY_ENABLED", "true")
+	// The WAF runs of the first request can exceed the default WAF timeout
+	t.Setenv("DD_APPSEC_WAF_TIMEOUT", "1s")
+	appsec.Start()
+	defer appsec.Stop()
+	if !appsec.Enabled() {
+		t.Skip("appsec disabled")
+	}
+
+	r := gin.New()
+	r.Use(Middleware("appsec"))
+	r.GET("/cars/:id", func(c *gin.Context) {
+		c.JSON(200, gin.H{"vin": "AAAAAAAAAAAAAAAAA"})
+	})
+	srv := httptest.NewServer(r)
+	defer srv.Close()
+
+	mt := mocktracer.Start()
+	defer mt.Stop()
+	res, err := srv.Client().Get(srv.URL + "/cars/1")
+	require.NoError(t, err)
+	defer res.Body.Close()
+	body, err := io.ReadAll(res.Body)
+	require.NoError(t, err)
+	require.Equal(t, `{"vin":"AAAAAAAAAAAAAAAAA"}`, string(body))
+
+	spans := mt.FinishedSpans()
+	require.Len(t, spans, 1)
+	require.Equal(t, `[{"vin":[8,{"category":"pii","type":"vin"}]}]`, spans[0].Tag("_dd.appsec.s.res.body"))
+}
@@ -0,0 +1,39 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2024 Datadog, Inc.
+
+package zap_test
+
+import (
+	"context"
+
+	zaptrace "gopkg.in/DataDog/dd-trace-go.v1/contrib/go.uber.org/zap"
+	"gopkg.in/DataDog/dd-trace-go.v1/ddtrace/tracer"
+
+	"go.uber.org/zap"
+)
+
+func ExampleWrapCore() {
+	// Ensure your tracer is started and stopped
+	// Setup zap, do this once at the beginning of your program
+	logger := zap.Must(zap.NewProduction(zap.WrapCore(zaptrace.WrapCore)))
+	defer logger.Sync()
+
+	span, ctx := tracer.StartSpanFromContext(context.Background(), "mySpan")
+	defer span.Finish()
+
+	// Pass the current span context to the logger
+	logger.Info("Completed some work!", zaptrace.Context(ctx))
+}
+
+func ExampleTraceFields() {
+	logger := zap.Must(zap.NewProduction())
+	defer logger.Sync()
+
+	span, ctx := tracer.StartSpanFromContext(context.Background(), "mySpan")
+	defer span.Finish()
+
+	// Add the correlation fields to the logger without wrapping its core
+	logger.With(zaptrace.TraceFields(ctx)...).Info("Completed some work!")
+}
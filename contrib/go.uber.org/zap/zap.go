@@ -0,0 +1,135 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2024 Datadog, Inc.
+
+// Package zap provides a log/span correlation core and fields for the go.uber.org/zap package (https://github.com/uber-go/zap).
+package zap
+
+import (
+	"context"
+
+	"gopkg.in/DataDog/dd-trace-go.v1/contrib/internal/logcorrelation"
+	"gopkg.in/DataDog/dd-trace-go.v1/ddtrace/ext"
+	"gopkg.in/DataDog/dd-trace-go.v1/ddtrace/tracer"
+	"gopkg.in/DataDog/dd-trace-go.v1/internal/telemetry"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+const componentName = "go.uber.org/zap"
+
+func init() {
+	telemetry.LoadIntegration(componentName)
+	tracer.MarkIntegrationImported("go.uber.org/zap")
+}
+
+// contextKey is the key of the fields returned by Context.
+const contextKey = "dd.context"
+
+// Context returns a field carrying ctx, replaced in the logs by the fields correlating them with the trace of
+// the span in ctx when the core of the logger is wrapped with WrapCore. The cores not wrapped skip the field.
+func Context(ctx context.Context) zap.Field {
+	return zap.Field{Key: contextKey, Type: zapcore.SkipType, Interface: ctx}
+}
+
+// TraceFields returns the fields correlating a log with the trace of the span in ctx: dd.trace_id, dd.span_id,
+// dd.service, dd.env and dd.version. The trace ID is the 128-bit trace ID when DD_TRACE_128_BIT_TRACEID_LOGGING_ENABLED
+// is set and the trace has one. It returns no fields when there is no span in ctx.
+func TraceFields(ctx context.Context) []zap.Field {
+	return traceFields(ctx, logcorrelation.Use128BitTraceID(), nil)
+}
+
+// WrapCore returns a core wrapping core, which replaces the Context fields of the logs, or of the loggers
+// they are written with, by the fields correlating the logs with the trace of the span in the context, see
+// TraceFields. The logs without Context fields are written by core as they are, without allocations.
+func WrapCore(core zapcore.Core) zapcore.Core {
+	return &tracedCore{Core: core, use128BitTraceID: logcorrelation.Use128BitTraceID()}
+}
+
+// tracedCore is a zapcore.Core correlating the logs with the traces.
+type tracedCore struct {
+	zapcore.Core
+
+	// use128BitTraceID is true when the logs have the 128-bit trace IDs.
+	use128BitTraceID bool
+}
+
+// With adds fields to the core, with the Context fields replaced.
+func (c *tracedCore) With(fields []zapcore.Field) zapcore.Core {
+	return &tracedCore{Core: c.Core.With(c.replaceContext(fields)), use128BitTraceID: c.use128BitTraceID}
+}
+
+// Check adds the core to the checked entry when the level of the entry is enabled.
+func (c *tracedCore) Check(ent zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if c.Enabled(ent.Level) {
+		return ce.AddCore(ent, c)
+	}
+	return ce
+}
+
+// Write writes the entry, with the Context fields replaced.
+func (c *tracedCore) Write(ent zapcore.Entry, fields []zapcore.Field) error {
+	return c.Core.Write(ent, c.replaceContext(fields))
+}
+
+// replaceContext returns the fields with the Context fields replaced by the fields correlating the log
+// with the trace of the span in the context. It returns the fields as they are when there is no Context field.
+func (c *tracedCore) replaceContext(fields []zapcore.Field) []zapcore.Field {
+	idx := contextIndex(fields)
+	if idx < 0 {
+		return fields
+	}
+	replaced := make([]zapcore.Field, 0, len(fields)+4)
+	for _, f := range fields[idx:] {
+		if ctx, ok := contextOf(f); ok {
+			replaced = traceFields(ctx, c.use128BitTraceID, replaced)
+		} else {
+			replaced = append(replaced, f)
+		}
+	}
+	return append(fields[:idx:idx], replaced...)
+}
+
+// contextIndex returns the index of the first Context field, or -1.
+func contextIndex(fields []zapcore.Field) int {
+	for i, f := range fields {
+		if _, ok := contextOf(f); ok {
+			return i
+		}
+	}
+	return -1
+}
+
+// contextOf returns the context carried by a Context field.
+func contextOf(f zapcore.Field) (context.Context, bool) {
+	if f.Type != zapcore.SkipType || f.Key != contextKey {
+		return nil, false
+	}
+	ctx, ok := f.Interface.(context.Context)
+	return ctx, ok
+}
+
+// traceFields appends to fields the fields correlating a log with the trace of the span in ctx.
+func traceFields(ctx context.Context, use128BitTraceID bool, fields []zap.Field) []zap.Field {
+	span, ok := tracer.SpanFromContext(ctx)
+	if !ok {
+		return fields
+	}
+	corr := logcorrelation.SpanFields(span.Context(), use128BitTraceID)
+	fields = append(fields,
+		zap.String(ext.LogKeyTraceID, corr.TraceID),
+		zap.String(ext.LogKeySpanID, corr.SpanID),
+	)
+	if corr.Service != "" {
+		fields = append(fields, zap.String(ext.LogKeyService, corr.Service))
+	}
+	if corr.Env != "" {
+		fields = append(fields, zap.String(ext.LogKeyEnv, corr.Env))
+	}
+	if corr.Version != "" {
+		fields = append(fields, zap.String(ext.LogKeyVersion, corr.Version))
+	}
+	return fields
+}
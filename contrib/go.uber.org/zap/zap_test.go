@@ -0,0 +1,103 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2024 Datadog, Inc.
+
+package zap
+
+import (
+	"bytes"
+	"context"
+	"strconv"
+	"testing"
+
+	"gopkg.in/DataDog/dd-trace-go.v1/ddtrace"
+	"gopkg.in/DataDog/dd-trace-go.v1/ddtrace/tracer"
+	"gopkg.in/DataDog/dd-trace-go.v1/internal/globalconfig"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+func startTracer(t *testing.T) {
+	tracer.Start(tracer.WithService("test-service"), tracer.WithEnv("test-env"), tracer.WithServiceVersion("1.2.3"))
+	t.Cleanup(func() {
+		tracer.Stop()
+		globalconfig.SetServiceName("")
+		globalconfig.SetEnv("")
+		globalconfig.SetVersion("")
+	})
+}
+
+func TestWrapCore(t *testing.T) {
+	startTracer(t)
+	span, ctx := tracer.StartSpanFromContext(context.Background(), "testSpan", tracer.WithSpanID(1234))
+	defer span.Finish()
+	traceID := strconv.FormatUint(span.Context().TraceID(), 10)
+
+	core, logs := observer.New(zapcore.InfoLevel)
+	logger := zap.New(WrapCore(core))
+	logger.Info("in span", zap.String("key", "value"), Context(ctx))
+	logger.With(Context(ctx)).Info("logger in span")
+	logger.Info("without span", Context(context.Background()))
+	logger.Info("without context")
+
+	entries := logs.AllUntimed()
+	require.Len(t, entries, 4)
+	expected := map[string]interface{}{
+		"dd.trace_id": traceID,
+		"dd.span_id":  "1234",
+		"dd.service":  "test-service",
+		"dd.env":      "test-env",
+		"dd.version":  "1.2.3",
+	}
+	fields := entries[0].ContextMap()
+	assert.Equal(t, "value", fields["key"])
+	delete(fields, "key")
+	assert.Equal(t, expected, fields)
+	assert.Equal(t, expected, entries[1].ContextMap())
+	assert.Empty(t, entries[2].ContextMap())
+	assert.Empty(t, entries[3].ContextMap())
+}
+
+func TestContextNotWrapped(t *testing.T) {
+	startTracer(t)
+	span, ctx := tracer.StartSpanFromContext(context.Background(), "testSpan")
+	defer span.Finish()
+
+	// The cores which aren't wrapped skip the Context fields.
+	var buf bytes.Buffer
+	logger := zap.New(zapcore.NewCore(zapcore.NewJSONEncoder(zapcore.EncoderConfig{MessageKey: "msg"}), zapcore.AddSync(&buf), zapcore.InfoLevel))
+	logger.Info("in span", Context(ctx))
+	assert.Equal(t, `{"msg":"in span"}`+"\n", buf.String())
+}
+
+func TestTraceFields(t *testing.T) {
+	t.Setenv("DD_TRACE_128_BIT_TRACEID_LOGGING_ENABLED", "true")
+	startTracer(t)
+	span, ctx := tracer.StartSpanFromContext(context.Background(), "testSpan", tracer.WithSpanID(1234))
+	defer span.Finish()
+
+	traceID := span.Context().(ddtrace.SpanContextW3C).TraceID128()
+	assert.Len(t, traceID, 32)
+	assert.Equal(t, []zap.Field{
+		zap.String("dd.trace_id", traceID),
+		zap.String("dd.span_id", "1234"),
+		zap.String("dd.service", "test-service"),
+		zap.String("dd.env", "test-env"),
+		zap.String("dd.version", "1.2.3"),
+	}, TraceFields(ctx))
+	assert.Empty(t, TraceFields(context.Background()))
+}
+
+func TestWriteWithoutContextAllocations(t *testing.T) {
+	core := WrapCore(zapcore.NewNopCore()).(*tracedCore)
+	fields := []zapcore.Field{zap.String("key", "value"), zap.Int("n", 1)}
+	allocs := testing.AllocsPerRun(100, func() {
+		_ = core.Write(zapcore.Entry{Message: "without context"}, fields)
+	})
+	assert.Zero(t, allocs)
+}
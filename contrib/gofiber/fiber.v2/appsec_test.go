@@ -0,0 +1,197 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2024 Datadog, Inc.
+
+package fiber
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	pappsec "gopkg.in/DataDog/dd-trace-go.v1/appsec"
+	"gopkg.in/DataDog/dd-trace-go.v1/ddtrace/mocktracer"
+	"gopkg.in/DataDog/dd-trace-go.v1/internal/appsec"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAppSec(t *testing.T) {
+	appsec.Start()
+	defer appsec.Stop()
+	if !appsec.Enabled() {
+		t.Skip("appsec disabled")
+	}
+
+	router := fiber.New()
+	router.Use(Middleware())
+	router.Get("/path0.0/:myPathParam0/path0.1/:myPathParam1/path0.2/:myPathParam2/path0.3/*", func(c *fiber.Ctx) error {
+		return c.SendString("Hello World!\n")
+	})
+	router.Get("/*", func(c *fiber.Ctx) error {
+		return c.SendString("Hello World!\n")
+	})
+
+	t.Run("request-uri", func(t *testing.T) {
+		mt := mocktracer.Start()
+		defer mt.Stop()
+		// Send an LFI attack (according to appsec rule id crs-930-110)
+		r := httptest.NewRequest("GET", "/../../../secret.txt", nil)
+		res, err := router.Test(r)
+		require.NoError(t, err)
+		defer res.Body.Close()
+		spans := mt.FinishedSpans()
+		require.Len(t, spans, 1)
+		event, _ := spans[0].Tag("_dd.appsec.json").(string)
+		require.Contains(t, event, "crs-930-110")
+		require.Contains(t, event, "server.request.uri.raw")
+	})
+
+	t.Run("query", func(t *testing.T) {
+		mt := mocktracer.Start()
+		defer mt.Stop()
+		// Send a security scanner attack (according to appsec rule id crs-913-120)
+		r := httptest.NewRequest("GET", "/?attack=appscan_fingerprint", nil)
+		res, err := router.Test(r)
+		require.NoError(t, err)
+		defer res.Body.Close()
+		require.Equal(t, http.StatusOK, res.StatusCode)
+		spans := mt.FinishedSpans()
+		require.Len(t, spans, 1)
+		event, _ := spans[0].Tag("_dd.appsec.json").(string)
+		require.Contains(t, event, "crs-913-120")
+		require.Contains(t, event, "server.request.query")
+	})
+
+	// Test a security scanner attack via path parameters
+	t.Run("path-params", func(t *testing.T) {
+		mt := mocktracer.Start()
+		defer mt.Stop()
+		// Send a security scanner attack (according to appsec rule id crs-913-120)
+		r := httptest.NewRequest("GET", "/path0.0/param0/path0.1/param1/path0.2/appscan_fingerprint/path0.3/param3", nil)
+		res, err := router.Test(r)
+		require.NoError(t, err)
+		defer res.Body.Close()
+		// Check that the handler was properly called
+		b, err := io.ReadAll(res.Body)
+		require.NoError(t, err)
+		require.Equal(t, "Hello World!\n", string(b))
+		require.Equal(t, http.StatusOK, res.StatusCode)
+		// The span should contain the security event
+		spans := mt.FinishedSpans()
+		require.Len(t, spans, 1)
+		event, _ := spans[0].Tag("_dd.appsec.json").(string)
+		require.Contains(t, event, "crs-913-120")
+		require.Contains(t, event, "myPathParam2")
+		require.Contains(t, event, "server.request.path_params")
+	})
+}
+
+// Test that IP, user and body blocking work by using custom rules/rules data
+func TestBlocking(t *testing.T) {
+	t.Setenv("DD_APPSEC_RULES", "../../../internal/appsec/testdata/blocking.json")
+	appsec.Start()
+	defer appsec.Stop()
+	if !appsec.Enabled() {
+		t.Skip("AppSec needs to be enabled for this test")
+	}
+
+	router := fiber.New()
+	router.Use(Middleware())
+	router.Post("/", func(c *fiber.Ctx) error {
+		if userID := c.Get("user-id"); userID != "" {
+			if err := pappsec.SetUser(c.UserContext(), userID); err != nil {
+				return err
+			}
+		}
+		return c.SendString("Hello World!\n")
+	})
+	router.Post("/error", func(c *fiber.Ctx) error {
+		return errors.New("oops")
+	})
+
+	for _, tc := range []struct {
+		name        string
+		endpoint    string
+		headers     map[string]string
+		body        string
+		status      int
+		shouldBlock bool
+	}{
+		{
+			name:        "ip/block",
+			headers:     map[string]string{"x-forwarded-for": "1.2.3.4"},
+			shouldBlock: true,
+		},
+		{
+			name:    "ip/no-block",
+			headers: map[string]string{"x-forwarded-for": "1.2.3.5"},
+		},
+		{
+			name:        "user/block",
+			headers:     map[string]string{"user-id": "blocked-user-1"},
+			shouldBlock: true,
+		},
+		{
+			name:    "user/no-block",
+			headers: map[string]string{"user-id": "legit-user-1"},
+		},
+		{
+			name:        "body/block",
+			headers:     map[string]string{"content-type": "application/x-www-form-urlencoded"},
+			body:        "name=$globals",
+			shouldBlock: true,
+		},
+		{
+			name:    "body/no-block",
+			headers: map[string]string{"content-type": "application/x-www-form-urlencoded"},
+			body:    "name=legit",
+		},
+		{
+			name:     "error/no-block",
+			endpoint: "/error",
+			status:   http.StatusInternalServerError,
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			mt := mocktracer.Start()
+			defer mt.Stop()
+
+			r := httptest.NewRequest("POST", "/"+strings.TrimPrefix(tc.endpoint, "/"), strings.NewReader(tc.body))
+			for k, v := range tc.headers {
+				r.Header.Set(k, v)
+			}
+			res, err := router.Test(r)
+			require.NoError(t, err)
+			defer res.Body.Close()
+			b, err := io.ReadAll(res.Body)
+			require.NoError(t, err)
+
+			spans := mt.FinishedSpans()
+			require.Len(t, spans, 1)
+			switch {
+			case tc.shouldBlock:
+				require.Equal(t, http.StatusForbidden, res.StatusCode)
+				require.NotContains(t, string(b), "Hello World!")
+				require.Equal(t, true, spans[0].Tag("appsec.blocked"))
+			case tc.status != 0:
+				require.Equal(t, tc.status, res.StatusCode)
+				require.NotContains(t, spans[0].Tags(), "appsec.blocked")
+			default:
+				require.Equal(t, http.StatusOK, res.StatusCode)
+				require.Equal(t, "Hello World!\n", string(b))
+				require.NotContains(t, spans[0].Tags(), "appsec.blocked")
+			}
+			if tc.status == 0 {
+				// The status code of errors is tagged before the fiber error handler writes it
+				require.Equal(t, fmt.Sprintf("%d", res.StatusCode), spans[0].Tag("http.status_code"))
+			}
+		})
+	}
+}
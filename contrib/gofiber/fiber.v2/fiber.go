@@ -7,14 +7,18 @@
 package fiber // import "gopkg.in/DataDog/dd-trace-go.v1/contrib/gofiber/fiber.v2"
 
 import (
+	"context"
 	"fmt"
 	"math"
 	"net/http"
 	"strconv"
 
+	"gopkg.in/DataDog/dd-trace-go.v1/contrib/internal/fasthttptrace"
 	"gopkg.in/DataDog/dd-trace-go.v1/ddtrace"
 	"gopkg.in/DataDog/dd-trace-go.v1/ddtrace/ext"
 	"gopkg.in/DataDog/dd-trace-go.v1/ddtrace/tracer"
+	"gopkg.in/DataDog/dd-trace-go.v1/internal/appsec"
+	"gopkg.in/DataDog/dd-trace-go.v1/internal/appsec/emitter/httpsec"
 	"gopkg.in/DataDog/dd-trace-go.v1/internal/log"
 	"gopkg.in/DataDog/dd-trace-go.v1/internal/telemetry"
 
@@ -77,7 +81,33 @@ func Middleware(opts ...Option) func(c *fiber.Ctx) error {
 		c.SetUserContext(ctx)
 
 		// pass the execution down the line
-		err := c.Next()
+		var err error
+		if appsec.Enabled() {
+			// The path parameters are only known here when the middleware is
+			// registered on the route itself. Otherwise, they are monitored once
+			// fiber routed the request, and must not be given empty to the WAF
+			// beforehand, which keeps the first value of an address.
+			var params map[string]string
+			if p := c.AllParams(); len(p) > 0 {
+				params = p
+			}
+			blocked := fasthttptrace.WithAppSec(ctx, c.Context(), span, params, func(ctx context.Context) {
+				c.SetUserContext(ctx)
+				route := c.Route()
+				err = c.Next()
+				// The request was routed to another route than the middleware's one.
+				if params := c.AllParams(); c.Route() != route && len(params) > 0 {
+					_ = httpsec.MonitorPathParams(ctx, params)
+				}
+			})
+			if blocked {
+				// The blocking response was written by AppSec and must not be
+				// overwritten by the fiber error handler.
+				err = nil
+			}
+		} else {
+			err = c.Next()
+		}
 
 		span.SetTag(ext.ResourceName, cfg.resourceNamer(c))
 		span.SetTag(ext.HTTPRoute, c.Route().Path)
@@ -7,12 +7,14 @@ package grpc
 
 import (
 	"context"
+	"sync"
 
 	"gopkg.in/DataDog/dd-trace-go.v1/ddtrace"
 	"gopkg.in/DataDog/dd-trace-go.v1/internal/appsec/dyngo"
 	"gopkg.in/DataDog/dd-trace-go.v1/internal/appsec/emitter/grpcsec"
 	"gopkg.in/DataDog/dd-trace-go.v1/internal/appsec/emitter/grpcsec/types"
 	"gopkg.in/DataDog/dd-trace-go.v1/internal/appsec/emitter/sharedsec"
+	"gopkg.in/DataDog/dd-trace-go.v1/internal/appsec/eventsink"
 	"gopkg.in/DataDog/dd-trace-go.v1/internal/appsec/trace"
 	"gopkg.in/DataDog/dd-trace-go.v1/internal/appsec/trace/grpctrace"
 	"gopkg.in/DataDog/dd-trace-go.v1/internal/appsec/trace/httptrace"
@@ -27,11 +29,14 @@ import (
 )
 
 // UnaryHandler wrapper to use when AppSec is enabled to monitor its execution.
+// The request message is inspected before calling the handler, which is not
+// called when the request gets blocked, and the response message is inspected
+// once the handler returned, the response being replaced by the blocking error
+// when it gets blocked.
 func appsecUnaryHandlerMiddleware(method string, span ddtrace.Span, handler grpc.UnaryHandler) grpc.UnaryHandler {
 	trace.SetAppSecEnabledTags(span)
 	return func(ctx context.Context, req interface{}) (interface{}, error) {
-		var err error
-		var blocked bool
+		var blocking appsecBlocking
 		md, _ := metadata.FromIncomingContext(ctx)
 		clientIP := setClientIP(ctx, span, md)
 		args := types.HandlerOperationArgs{
@@ -39,16 +44,15 @@ func appsecUnaryHandlerMiddleware(method string, span ddtrace.Span, handler grpc
 			Metadata: md,
 			ClientIP: clientIP,
 		}
+		var sinkEvents *eventsink.Collector
 		ctx, op := grpcsec.StartHandlerOperation(ctx, args, nil, func(op *types.HandlerOperation) {
-			dyngo.OnData(op, func(a *sharedsec.GRPCAction) {
-				code, e := a.GRPCWrapper(md)
-				blocked = a.Blocking()
-				err = status.Error(codes.Code(code), e.Error())
-			})
+			sinkEvents = eventsink.NewCollector(op)
+			dyngo.OnData(op, func(a *sharedsec.GRPCAction) { blocking.set(a, md) })
 		})
+		ctx, resMD := withResponseMetadata(ctx)
 		defer func() {
-			events := op.Finish(types.HandlerOperationRes{})
-			if blocked {
+			events := op.Finish(resMD.result())
+			if blocking.blocked() {
 				op.SetTag(trace.BlockedRequestTag, true)
 			}
 			grpctrace.SetRequestMetadataTags(span, md)
@@ -56,17 +60,34 @@ func appsecUnaryHandlerMiddleware(method string, span ddtrace.Span, handler grpc
 			if len(events) > 0 {
 				grpctrace.SetSecurityEventsTags(span, events)
 			}
+			sinkEvents.Export(span, clientIP, blocking.blocked())
 		}()
 
-		if err != nil {
+		if err := blocking.err(); err != nil {
+			return nil, err
+		}
+		grpcsec.StartReceiveOperation(types.ReceiveOperationArgs{}, op).Finish(types.ReceiveOperationRes{Message: req})
+		if err := blocking.err(); err != nil {
 			return nil, err
 		}
-		defer grpcsec.StartReceiveOperation(types.ReceiveOperationArgs{}, op).Finish(types.ReceiveOperationRes{Message: req})
+
 		rv, err := handler(ctx, req)
 		if e, ok := err.(*types.MonitoringError); ok {
 			err = status.Error(codes.Code(e.GRPCStatus()), e.Error())
 		}
-		return rv, err
+		if bErr := blocking.err(); bErr != nil {
+			// The handler was blocked while executing, e.g. by RASP
+			return nil, bErr
+		}
+		if err != nil {
+			return rv, err
+		}
+
+		grpcsec.StartSendOperation(types.SendOperationArgs{}, op).Finish(types.SendOperationRes{Message: rv})
+		if err := blocking.err(); err != nil {
+			return nil, err
+		}
+		return rv, nil
 	}
 }
 
@@ -74,8 +95,7 @@ func appsecUnaryHandlerMiddleware(method string, span ddtrace.Span, handler grpc
 func appsecStreamHandlerMiddleware(method string, span ddtrace.Span, handler grpc.StreamHandler) grpc.StreamHandler {
 	trace.SetAppSecEnabledTags(span)
 	return func(srv interface{}, stream grpc.ServerStream) error {
-		var err error
-		var blocked bool
+		var blocking appsecBlocking
 		ctx := stream.Context()
 		md, _ := metadata.FromIncomingContext(ctx)
 		clientIP := setClientIP(ctx, span, md)
@@ -86,61 +106,254 @@ func appsecStreamHandlerMiddleware(method string, span ddtrace.Span, handler grp
 			Metadata: md,
 			ClientIP: clientIP,
 		}
+		var sinkEvents *eventsink.Collector
 		ctx, op := grpcsec.StartHandlerOperation(ctx, args, nil, func(op *types.HandlerOperation) {
-			dyngo.OnData(op, func(a *sharedsec.GRPCAction) {
-				code, e := a.GRPCWrapper(md)
-				blocked = a.Blocking()
-				err = status.Error(codes.Code(code), e.Error())
-			})
+			sinkEvents = eventsink.NewCollector(op)
+			dyngo.OnData(op, func(a *sharedsec.GRPCAction) { blocking.set(a, md) })
 		})
+		ctx, resMD := withResponseMetadata(ctx)
 		stream = appsecServerStream{
 			ServerStream:     stream,
 			handlerOperation: op,
 			ctx:              ctx,
+			blocking:         &blocking,
+			md:               resMD,
 		}
 		defer func() {
-			events := op.Finish(types.HandlerOperationRes{})
-			if blocked {
+			events := op.Finish(resMD.result())
+			if blocking.blocked() {
 				op.SetTag(trace.BlockedRequestTag, true)
 			}
 			trace.SetTags(span, op.Tags())
 			if len(events) > 0 {
 				grpctrace.SetSecurityEventsTags(span, events)
 			}
+			sinkEvents.Export(span, clientIP, blocking.blocked())
 		}()
 
-		if err != nil {
+		if err := blocking.err(); err != nil {
 			return err
 		}
 
-		err = handler(srv, stream)
+		err := handler(srv, stream)
 		if e, ok := err.(*types.MonitoringError); ok {
 			err = status.Error(codes.Code(e.GRPCStatus()), e.Error())
 		}
+		if bErr := blocking.err(); bErr != nil {
+			return bErr
+		}
 		return err
 	}
 }
 
+// appsecBlocking holds the gRPC status error of the blocking action that was
+// emitted by the AppSec listeners, if any. It is safe for concurrent use since
+// stream messages can be received and sent concurrently.
+type appsecBlocking struct {
+	mu     sync.Mutex
+	status error
+}
+
+func (b *appsecBlocking) set(a *sharedsec.GRPCAction, md metadata.MD) {
+	code, e := a.GRPCWrapper(md)
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.status == nil {
+		b.status = status.Error(codes.Code(code), e.Error())
+	}
+}
+
+func (b *appsecBlocking) err() error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.status
+}
+
+func (b *appsecBlocking) blocked() bool {
+	return b.err() != nil
+}
+
 type appsecServerStream struct {
 	grpc.ServerStream
 	handlerOperation *types.HandlerOperation
 	ctx              context.Context
+	blocking         *appsecBlocking
+	md               *responseMetadata
 }
 
 // RecvMsg implements grpc.ServerStream interface method to monitor its
 // execution with AppSec.
 func (ss appsecServerStream) RecvMsg(m interface{}) error {
-	op := grpcsec.StartReceiveOperation(types.ReceiveOperationArgs{}, ss.handlerOperation)
-	defer func() {
-		op.Finish(types.ReceiveOperationRes{Message: m})
-	}()
-	return ss.ServerStream.RecvMsg(m)
+	if err := ss.ServerStream.RecvMsg(m); err != nil {
+		return err
+	}
+	grpcsec.StartReceiveOperation(types.ReceiveOperationArgs{}, ss.handlerOperation).Finish(types.ReceiveOperationRes{Message: m})
+	return ss.blocking.err()
+}
+
+// SendMsg implements grpc.ServerStream interface method to monitor its
+// execution with AppSec. The message is not sent when it gets blocked.
+func (ss appsecServerStream) SendMsg(m interface{}) error {
+	grpcsec.StartSendOperation(types.SendOperationArgs{}, ss.handlerOperation).Finish(types.SendOperationRes{Message: m})
+	if err := ss.blocking.err(); err != nil {
+		return err
+	}
+	return ss.ServerStream.SendMsg(m)
+}
+
+// SetHeader implements grpc.ServerStream interface method to collect the
+// response headers.
+func (ss appsecServerStream) SetHeader(md metadata.MD) error {
+	ss.md.addHeaders(md)
+	return ss.ServerStream.SetHeader(md)
+}
+
+// SendHeader implements grpc.ServerStream interface method to collect the
+// response headers.
+func (ss appsecServerStream) SendHeader(md metadata.MD) error {
+	ss.md.addHeaders(md)
+	return ss.ServerStream.SendHeader(md)
+}
+
+// SetTrailer implements grpc.ServerStream interface method to collect the
+// response trailers.
+func (ss appsecServerStream) SetTrailer(md metadata.MD) {
+	ss.md.addTrailers(md)
+	ss.ServerStream.SetTrailer(md)
 }
 
 func (ss appsecServerStream) Context() context.Context {
 	return ss.ctx
 }
 
+// responseMetadata collects the response metadata set by the gRPC handler.
+type responseMetadata struct {
+	mu       sync.Mutex
+	headers  metadata.MD
+	trailers metadata.MD
+}
+
+func (r *responseMetadata) addHeaders(md metadata.MD) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.headers = metadata.Join(r.headers, md)
+}
+
+func (r *responseMetadata) addTrailers(md metadata.MD) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.trailers = metadata.Join(r.trailers, md)
+}
+
+func (r *responseMetadata) result() types.HandlerOperationRes {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return types.HandlerOperationRes{Headers: r.headers, Trailers: r.trailers}
+}
+
+// withResponseMetadata returns a context whose server transport stream
+// collects the response metadata set with grpc.SetHeader(), grpc.SendHeader()
+// and grpc.SetTrailer().
+func withResponseMetadata(ctx context.Context) (context.Context, *responseMetadata) {
+	md := new(responseMetadata)
+	sts := grpc.ServerTransportStreamFromContext(ctx)
+	if sts == nil {
+		return ctx, md
+	}
+	return grpc.NewContextWithServerTransportStream(ctx, appsecServerTransportStream{ServerTransportStream: sts, md: md}), md
+}
+
+type appsecServerTransportStream struct {
+	grpc.ServerTransportStream
+	md *responseMetadata
+}
+
+func (s appsecServerTransportStream) SetHeader(md metadata.MD) error {
+	s.md.addHeaders(md)
+	return s.ServerTransportStream.SetHeader(md)
+}
+
+func (s appsecServerTransportStream) SendHeader(md metadata.MD) error {
+	s.md.addHeaders(md)
+	return s.ServerTransportStream.SendHeader(md)
+}
+
+func (s appsecServerTransportStream) SetTrailer(md metadata.MD) error {
+	s.md.addTrailers(md)
+	return s.ServerTransportStream.SetTrailer(md)
+}
+
+// appsecUnaryClientMiddleware wraps the given invoker of outgoing unary calls
+// so that the calls made while serving a monitored request are inspected. The
+// call and its request message are inspected before calling the invoker, which
+// is not called when they get blocked, and the reply message is inspected once
+// the invoker returned, the blocking error being returned instead when it gets
+// blocked.
+func appsecUnaryClientMiddleware(invoker grpc.UnaryInvoker) grpc.UnaryInvoker {
+	return func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, opts ...grpc.CallOption) error {
+		if err := grpcsec.ProtectClientCall(ctx, makeClientCallOperationArgs(cc, method)); err != nil {
+			return err
+		}
+		if err := grpcsec.ProtectClientMessage(ctx, types.ClientMessageOperationArgs{Message: req}); err != nil {
+			return err
+		}
+		if err := invoker(ctx, method, req, reply, cc, opts...); err != nil {
+			return err
+		}
+		return grpcsec.ProtectClientMessage(ctx, types.ClientMessageOperationArgs{Message: reply, Received: true})
+	}
+}
+
+// appsecStreamClientMiddleware wraps the given streamer of outgoing streams so
+// that the streams opened while serving a monitored request are inspected,
+// along with the messages they send and receive.
+func appsecStreamClientMiddleware(streamer grpc.Streamer) grpc.Streamer {
+	return func(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+		if err := grpcsec.ProtectClientCall(ctx, makeClientCallOperationArgs(cc, method)); err != nil {
+			return nil, err
+		}
+		stream, err := streamer(ctx, desc, cc, method, opts...)
+		if err != nil {
+			return nil, err
+		}
+		return appsecClientStream{ClientStream: stream, ctx: ctx}, nil
+	}
+}
+
+func makeClientCallOperationArgs(cc *grpc.ClientConn, method string) types.ClientCallOperationArgs {
+	args := types.ClientCallOperationArgs{Method: method}
+	if cc != nil {
+		args.Target = cc.Target()
+	}
+	return args
+}
+
+// appsecClientStream inspects the messages sent and received by an outgoing
+// stream. A message is not sent when it gets blocked, and the blocking error is
+// returned instead of a received message when it gets blocked.
+type appsecClientStream struct {
+	grpc.ClientStream
+	ctx context.Context
+}
+
+// SendMsg implements grpc.ClientStream interface method to inspect the sent
+// messages.
+func (cs appsecClientStream) SendMsg(m interface{}) error {
+	if err := grpcsec.ProtectClientMessage(cs.ctx, types.ClientMessageOperationArgs{Message: m}); err != nil {
+		return err
+	}
+	return cs.ClientStream.SendMsg(m)
+}
+
+// RecvMsg implements grpc.ClientStream interface method to inspect the
+// received messages.
+func (cs appsecClientStream) RecvMsg(m interface{}) error {
+	if err := cs.ClientStream.RecvMsg(m); err != nil {
+		return err
+	}
+	return grpcsec.ProtectClientMessage(cs.ctx, types.ClientMessageOperationArgs{Message: m, Received: true})
+}
+
 func setClientIP(ctx context.Context, span ddtrace.Span, md metadata.MD) netip.Addr {
 	var remoteAddr string
 	if p, ok := peer.FromContext(ctx); ok {
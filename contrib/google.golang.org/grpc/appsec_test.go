@@ -275,6 +275,26 @@ func TestUserBlocking(t *testing.T) {
 		require.Equal(t, codes.OK, status.Code(err))
 	})
 
+	t.Run("unary-request-message-block", func(t *testing.T) {
+		client, mt, cleanup := setup()
+		defer cleanup()
+
+		// The request message is inspected before calling the handler, which
+		// would otherwise block the user too.
+		ctx := metadata.NewOutgoingContext(context.Background(), metadata.Pairs("user-id", "blocked-user-1"))
+		reply, err := client.Ping(ctx, &FixtureRequest{Name: "$globals"})
+
+		require.Nil(t, reply)
+		require.Equal(t, codes.Aborted, status.Code(err))
+
+		finished := mt.FinishedSpans()
+		require.Len(t, finished, 1)
+		event, _ := finished[0].Tag("_dd.appsec.json").(string)
+		require.Contains(t, event, "crs-933-130-block")
+		require.NotContains(t, event, "blk-001-002")
+		require.Equal(t, true, finished[0].Tag("appsec.blocked"))
+	})
+
 	// This test checks that IP blocking happens BEFORE user blocking, since user blocking needs the request handler
 	// to be invoked while IP blocking doesn't
 	t.Run("unary-mixed-block", func(t *testing.T) {
@@ -355,6 +375,92 @@ func TestUserBlocking(t *testing.T) {
 	})
 }
 
+// Test that the response messages and metadata are inspected by using custom rules
+func TestResponseInspection(t *testing.T) {
+	t.Setenv("DD_APPSEC_RULES", "../../../internal/appsec/testdata/blocking.json")
+	appsec.Start()
+	defer appsec.Stop()
+	if !appsec.Enabled() {
+		t.Skip("appsec disabled")
+	}
+
+	setup := func() (FixtureClient, mocktracer.Tracer, func()) {
+		rig, err := newAppsecRig(false)
+		require.NoError(t, err)
+
+		mt := mocktracer.Start()
+
+		return rig.client, mt, func() {
+			rig.Close()
+			mt.Stop()
+		}
+	}
+
+	t.Run("unary-message-block", func(t *testing.T) {
+		client, mt, cleanup := setup()
+		defer cleanup()
+
+		ctx := metadata.NewOutgoingContext(context.Background(), metadata.Pairs("user-id", "legit user"))
+		reply, err := client.Ping(ctx, &FixtureRequest{Name: "leak-message"})
+
+		require.Nil(t, reply)
+		require.Equal(t, codes.Aborted, status.Code(err))
+
+		finished := mt.FinishedSpans()
+		require.Len(t, finished, 1)
+		event, _ := finished[0].Tag("_dd.appsec.json").(string)
+		require.Contains(t, event, "grpc-res-001")
+		require.Equal(t, true, finished[0].Tag("appsec.blocked"))
+	})
+
+	t.Run("stream-message-block", func(t *testing.T) {
+		client, mt, cleanup := setup()
+		defer cleanup()
+
+		ctx := metadata.NewOutgoingContext(context.Background(), metadata.Pairs("user-id", "legit user"))
+		stream, err := client.StreamPing(ctx)
+		require.NoError(t, err)
+
+		// A regular message goes through
+		require.NoError(t, stream.Send(&FixtureRequest{Name: "hello"}))
+		reply, err := stream.Recv()
+		require.NoError(t, err)
+		require.Equal(t, "passed", reply.Message)
+
+		// The leaking response message is blocked
+		require.NoError(t, stream.Send(&FixtureRequest{Name: "leak-message"}))
+		reply, err = stream.Recv()
+		require.Nil(t, reply)
+		require.Equal(t, codes.Aborted, status.Code(err))
+
+		// Flush the spans
+		stream.CloseSend()
+		stream.Recv()
+
+		finished := mt.FinishedSpans()
+		require.NotEmpty(t, finished)
+		event, _ := finished[len(finished)-1].Tag("_dd.appsec.json").(string)
+		require.Contains(t, event, "grpc-res-001")
+	})
+
+	t.Run("unary-metadata", func(t *testing.T) {
+		client, mt, cleanup := setup()
+		defer cleanup()
+
+		ctx := metadata.NewOutgoingContext(context.Background(), metadata.Pairs("user-id", "legit user"))
+		reply, err := client.Ping(ctx, &FixtureRequest{Name: "leak-metadata"})
+
+		// Response metadata are inspected once the response was sent and can't be blocked
+		require.NoError(t, err)
+		require.Equal(t, "passed", reply.Message)
+
+		finished := mt.FinishedSpans()
+		require.Len(t, finished, 1)
+		event, _ := finished[0].Tag("_dd.appsec.json").(string)
+		require.Contains(t, event, "grpc-res-002")
+	})
+}
+
 func TestPasslist(t *testing.T) {
 	// This custom rule file includes two rules detecting the same sec event, a grpc metadata value containing "zouzou",
 	// but only one of them is passlisted (custom-1 is passlisted, custom-2 is not and must trigger).
@@ -436,6 +542,88 @@ func TestPasslist(t *testing.T) {
 	})
 }
 
+// Test that the outgoing gRPC calls made while serving a request are inspected
+// by using custom rules
+func TestClientInspection(t *testing.T) {
+	t.Setenv("DD_APPSEC_RULES", "../../../internal/appsec/testdata/blocking.json")
+	appsec.Start()
+	defer appsec.Stop()
+	if !appsec.Enabled() {
+		t.Skip("appsec disabled")
+	}
+
+	setup := func() (FixtureClient, mocktracer.Tracer, func()) {
+		rig, err := newAppsecRig(true)
+		require.NoError(t, err)
+		// The fixture server calls itself as its downstream service
+		rig.fixtureServer.downstream = rig.client
+
+		mt := mocktracer.Start()
+
+		return rig.client, mt, func() {
+			rig.Close()
+			mt.Stop()
+		}
+	}
+
+	// securityEvents returns the security events of the finished spans
+	securityEvents := func(mt mocktracer.Tracer) string {
+		var events []string
+		for _, s := range mt.FinishedSpans() {
+			if event, ok := s.Tag("_dd.appsec.json").(string); ok {
+				events = append(events, event)
+			}
+		}
+		return strings.Join(events, "\n")
+	}
+
+	for _, tc := range []struct {
+		name    string
+		message string
+		ruleID  string
+	}{
+		{
+			name:    "method-block",
+			message: "downstream-stream",
+			ruleID:  "grpc-client-001",
+		},
+		{
+			name:    "request-message-block",
+			message: "downstream:dd-downstream-attack",
+			ruleID:  "grpc-client-002",
+		},
+		{
+			name:    "response-message-block",
+			message: "downstream:leak-downstream-message",
+			ruleID:  "grpc-client-003",
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			client, mt, cleanup := setup()
+			defer cleanup()
+
+			ctx := metadata.NewOutgoingContext(context.Background(), metadata.Pairs("user-id", "legit-user-1"))
+			reply, err := client.Ping(ctx, &FixtureRequest{Name: tc.message})
+
+			require.Nil(t, reply)
+			require.Equal(t, codes.Aborted, status.Code(err))
+			require.Contains(t, securityEvents(mt), tc.ruleID)
+		})
+	}
+
+	t.Run("no-block", func(t *testing.T) {
+		client, mt, cleanup := setup()
+		defer cleanup()
+
+		ctx := metadata.NewOutgoingContext(context.Background(), metadata.Pairs("user-id", "legit-user-1"))
+		reply, err := client.Ping(ctx, &FixtureRequest{Name: "downstream:hello"})
+
+		require.NoError(t, err)
+		require.Equal(t, "passed", reply.Message)
+		require.NotContains(t, securityEvents(mt), "grpc-client-")
+	})
+}
+
 func newAppsecRig(traceClient bool, interceptorOpts ...Option) (*appsecRig, error) {
 	interceptorOpts = append([]InterceptorOption{WithServiceName("grpc")}, interceptorOpts...)
 
@@ -495,6 +683,9 @@ func (r *appsecRig) Close() {
 type appsecFixtureServer struct {
 	UnimplementedFixtureServer
 	s fixtureServer
+	// downstream is the client used by the requests calling a downstream
+	// service while being served.
+	downstream FixtureClient
 }
 
 func (s *appsecFixtureServer) StreamPing(stream Fixture_StreamPingServer) (err error) {
@@ -504,14 +695,51 @@ func (s *appsecFixtureServer) StreamPing(stream Fixture_StreamPingServer) (err e
 	if err := pappsec.SetUser(ctx, ids[0]); err != nil {
 		return err
 	}
-	return s.s.StreamPing(stream)
+	for {
+		msg, err := stream.Recv()
+		if err != nil {
+			return err
+		}
+		reply, err := s.reply(ctx, msg)
+		if err != nil {
+			return err
+		}
+		if err := stream.Send(reply); err != nil {
+			return err
+		}
+	}
 }
+
 func (s *appsecFixtureServer) Ping(ctx context.Context, in *FixtureRequest) (*FixtureReply, error) {
 	md, _ := metadata.FromIncomingContext(ctx)
 	ids := md.Get("user-id")
 	if err := pappsec.SetUser(ctx, ids[0]); err != nil {
 		return nil, err
 	}
+	return s.reply(ctx, in)
+}
 
+func (s *appsecFixtureServer) reply(ctx context.Context, in *FixtureRequest) (*FixtureReply, error) {
+	if name, ok := strings.CutPrefix(in.Name, "downstream:"); ok {
+		ctx = metadata.NewOutgoingContext(ctx, metadata.Pairs("user-id", "downstream"))
+		return s.downstream.Ping(ctx, &FixtureRequest{Name: name})
+	}
+	switch in.Name {
+	case "downstream-stream":
+		ctx = metadata.NewOutgoingContext(ctx, metadata.Pairs("user-id", "downstream"))
+		stream, err := s.downstream.StreamPing(ctx)
+		if err != nil {
+			return nil, err
+		}
+		return &FixtureReply{Message: "passed"}, stream.CloseSend()
+	case "leak-message":
+		return &FixtureReply{Message: "dd-secret-message"}, nil
+	case "leak-downstream-message":
+		return &FixtureReply{Message: "dd-downstream-secret"}, nil
+	case "leak-metadata":
+		if err := grpc.SetHeader(ctx, metadata.Pairs("x-leak", "dd-secret-metadata")); err != nil {
+			return nil, err
+		}
+	}
 	return s.s.Ping(ctx, in)
 }
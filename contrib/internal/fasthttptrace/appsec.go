@@ -0,0 +1,171 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2024 Datadog, Inc.
+
+package fasthttptrace
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	"gopkg.in/DataDog/dd-trace-go.v1/ddtrace/tracer"
+	"gopkg.in/DataDog/dd-trace-go.v1/internal/appsec/emitter/httpsec"
+	"gopkg.in/DataDog/dd-trace-go.v1/internal/appsec/emitter/httpsec/types"
+	"gopkg.in/DataDog/dd-trace-go.v1/internal/appsec/listener"
+	"gopkg.in/DataDog/dd-trace-go.v1/internal/appsec/trace"
+	"gopkg.in/DataDog/dd-trace-go.v1/internal/appsec/trace/httptrace"
+	"gopkg.in/DataDog/dd-trace-go.v1/internal/log"
+
+	"github.com/DataDog/appsec-internal-go/netip"
+	"github.com/valyala/fasthttp"
+	"github.com/valyala/fasthttp/fasthttpadaptor"
+)
+
+// WithAppSec monitors the request of fctx with AppSec while calling next,
+// which is expected to call the request handler with the given context. The
+// request context ctx is the one next is expected to use and gets augmented
+// with the AppSec request operation, which is also stored in fctx so that fctx
+// can be used as context of the AppSec SDK functions.
+// The request handler is not called when the request gets blocked before it,
+// and the blocking response replaces the response of fctx when the request
+// gets blocked. The returned boolean is true when the request got blocked.
+func WithAppSec(ctx context.Context, fctx *fasthttp.RequestCtx, span tracer.Span, pathParams map[string]string, next func(context.Context)) (blocked bool) {
+	headers := makeRequestHeaders(&fctx.Request.Header)
+	ipTags, clientIP := httptrace.ClientIPTags(headers, true, fctx.RemoteAddr().String())
+	log.Debug("appsec: http client ip detection returned `%s` given the http headers `%v`", clientIP, headers)
+	trace.SetTags(span, ipTags)
+
+	args := makeHandlerOperationArgs(fctx, headers, clientIP, pathParams)
+	ctx, m := httpsec.StartRequestMonitor(ctx, span, args)
+	fctx.SetUserValue(listener.ContextKey{}, ctx.Value(listener.ContextKey{}))
+	defer func() {
+		m.Finish(makeHandlerOperationRes(fctx))
+		if h := m.BlockingHandler(); h != nil {
+			serveBlockingHandler(fctx, h)
+		}
+		blocked = m.Blocked()
+	}()
+
+	// The request can be blocked before calling the request handler, e.g. by
+	// its client IP or its body.
+	if h := m.BlockingHandler(); h != nil {
+		serveBlockingHandler(fctx, h)
+		return
+	}
+	if body := parseBody(&fctx.Request); body != nil {
+		// The returned error can be ignored as it comes along with the blocking
+		// handler checked right after.
+		_ = httpsec.MonitorParsedBody(ctx, body)
+		if h := m.BlockingHandler(); h != nil {
+			serveBlockingHandler(fctx, h)
+			return
+		}
+	}
+	next(ctx)
+	return
+}
+
+// serveBlockingHandler replaces the response of fctx with the blocking
+// response written by the given net/http handler.
+func serveBlockingHandler(fctx *fasthttp.RequestCtx, h http.Handler) {
+	fctx.Response.Reset()
+	fasthttpadaptor.NewFastHTTPHandler(h)(fctx)
+}
+
+// makeRequestHeaders returns the request headers as canonical net/http
+// headers.
+func makeRequestHeaders(h *fasthttp.RequestHeader) http.Header {
+	headers := make(http.Header)
+	h.VisitAll(func(k, v []byte) {
+		headers.Add(string(k), string(v))
+	})
+	return headers
+}
+
+// makeHandlerOperationArgs creates the HandlerOperationArgs value out of
+// fctx, following the same specification as httpsec.MakeHandlerOperationArgs
+// does with net/http requests.
+func makeHandlerOperationArgs(fctx *fasthttp.RequestCtx, headers http.Header, clientIP netip.Addr, pathParams map[string]string) types.HandlerOperationArgs {
+	headersNoCookies := make(map[string][]string, len(headers)+1)
+	for k, v := range headers {
+		k := strings.ToLower(k)
+		if k == "cookie" {
+			continue
+		}
+		headersNoCookies[k] = v
+	}
+	headersNoCookies["host"] = []string{string(fctx.Host())}
+
+	var cookies map[string][]string
+	fctx.Request.Header.VisitAllCookie(func(k, v []byte) {
+		if cookies == nil {
+			cookies = make(map[string][]string)
+		}
+		cookies[string(k)] = append(cookies[string(k)], string(v))
+	})
+
+	query := make(map[string][]string)
+	fctx.QueryArgs().VisitAll(func(k, v []byte) {
+		query[string(k)] = append(query[string(k)], string(v))
+	})
+
+	return types.HandlerOperationArgs{
+		Method:     string(fctx.Method()),
+		RequestURI: string(fctx.RequestURI()),
+		Headers:    headersNoCookies,
+		Cookies:    cookies,
+		Query:      query,
+		PathParams: pathParams,
+		ClientIP:   clientIP,
+	}
+}
+
+// makeHandlerOperationRes creates the HandlerOperationRes value out of the
+// response of fctx.
+func makeHandlerOperationRes(fctx *fasthttp.RequestCtx) types.HandlerOperationRes {
+	headers := make(map[string][]string)
+	fctx.Response.Header.VisitAll(func(k, v []byte) {
+		k = bytes.ToLower(k)
+		if string(k) == "cookie" {
+			return
+		}
+		headers[string(k)] = append(headers[string(k)], string(v))
+	})
+	return types.HandlerOperationRes{Status: fctx.Response.StatusCode(), Headers: headers}
+}
+
+// parseBody returns the parsed request body when its content type is
+// supported, i.e. JSON and form bodies, and nil otherwise.
+func parseBody(req *fasthttp.Request) any {
+	if len(req.Body()) == 0 {
+		return nil
+	}
+	contentType := string(req.Header.ContentType())
+	switch {
+	case strings.HasPrefix(contentType, "application/json"):
+		var body any
+		if err := json.Unmarshal(req.Body(), &body); err != nil {
+			log.Debug("appsec: could not parse the json request body: %v", err)
+			return nil
+		}
+		return body
+	case strings.HasPrefix(contentType, "application/x-www-form-urlencoded"):
+		body := make(map[string][]string)
+		req.PostArgs().VisitAll(func(k, v []byte) {
+			body[string(k)] = append(body[string(k)], string(v))
+		})
+		return body
+	case strings.HasPrefix(contentType, "multipart/form-data"):
+		form, err := req.MultipartForm()
+		if err != nil {
+			log.Debug("appsec: could not parse the multipart request body: %v", err)
+			return nil
+		}
+		return form.Value
+	}
+	return nil
+}
@@ -0,0 +1,65 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2024 Datadog, Inc.
+
+// Package logcorrelation provides the values of the fields correlating the logs of the logging
+// integrations with the traces.
+package logcorrelation
+
+import (
+	"strconv"
+
+	"gopkg.in/DataDog/dd-trace-go.v1/ddtrace"
+	"gopkg.in/DataDog/dd-trace-go.v1/internal"
+	"gopkg.in/DataDog/dd-trace-go.v1/internal/globalconfig"
+)
+
+// Fields holds the values of the fields correlating a log with the span it was written in, the empty
+// ones being left out of the log.
+type Fields struct {
+	TraceID string // Trace ID, see ext.LogKeyTraceID.
+	SpanID  string // Span ID, see ext.LogKeySpanID.
+	Service string // Service of the application, see ext.LogKeyService.
+	Env     string // Environment of the application, see ext.LogKeyEnv.
+	Version string // Version of the application, see ext.LogKeyVersion.
+}
+
+// Use128BitTraceID returns true when the logs have the 128-bit trace IDs, rather than their lower 64 bits,
+// as configured with DD_TRACE_128_BIT_TRACEID_LOGGING_ENABLED.
+func Use128BitTraceID() bool {
+	return internal.BoolEnv("DD_TRACE_128_BIT_TRACEID_LOGGING_ENABLED", false)
+}
+
+// SpanFields returns the fields correlating a log with the span of the context ctx. The trace ID is the
+// hex-encoded 128-bit trace ID when use128BitTraceID is true and the trace has one, the decimal lower 64
+// bits otherwise, like the span ID.
+func SpanFields(ctx ddtrace.SpanContext, use128BitTraceID bool) Fields {
+	return Fields{
+		TraceID: traceID(ctx, use128BitTraceID),
+		SpanID:  strconv.FormatUint(ctx.SpanID(), 10),
+		Service: globalconfig.ServiceName(),
+		Env:     globalconfig.Env(),
+		Version: globalconfig.Version(),
+	}
+}
+
+// traceID returns the trace ID of ctx as logged.
+func traceID(ctx ddtrace.SpanContext, use128Bit bool) string {
+	if use128Bit {
+		if w3cCtx, ok := ctx.(ddtrace.SpanContextW3C); ok && hasUpper(w3cCtx.TraceID128Bytes()) {
+			return w3cCtx.TraceID128()
+		}
+	}
+	return strconv.FormatUint(ctx.TraceID(), 10)
+}
+
+// hasUpper returns true when the upper 64 bits of a 128-bit trace ID are set.
+func hasUpper(traceID [16]byte) bool {
+	for _, b := range traceID[:8] {
+		if b != 0 {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,91 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2024 Datadog, Inc.
+
+package pgx
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"gopkg.in/DataDog/dd-trace-go.v1/appsec/events"
+	httptrace "gopkg.in/DataDog/dd-trace-go.v1/contrib/net/http"
+	"gopkg.in/DataDog/dd-trace-go.v1/ddtrace/mocktracer"
+	"gopkg.in/DataDog/dd-trace-go.v1/internal/appsec"
+	appsecconfig "gopkg.in/DataDog/dd-trace-go.v1/internal/appsec/config"
+
+	"github.com/stretchr/testify/require"
+)
+
+// Test that the queries built out of user input are blocked by RASP before
+// being sent to the database.
+func TestRASPSQLi(t *testing.T) {
+	t.Setenv("DD_APPSEC_RULES", "../../../internal/appsec/testdata/rasp.json")
+	t.Setenv(appsecconfig.EnvRASPEnabled, "true")
+	appsec.Start()
+	defer appsec.Stop()
+	if !appsec.RASPEnabled() {
+		t.Skip("RASP needs to be enabled for this test")
+	}
+
+	conn, err := Connect(context.Background(), postgresDSN)
+	require.NoError(t, err)
+	defer conn.Close(context.Background())
+
+	// Start and trace an HTTP server looking up names out of the query string
+	var queryErr error
+	mux := httptrace.NewServeMux()
+	mux.HandleFunc("/user", func(w http.ResponseWriter, r *http.Request) {
+		rows, err := conn.Query(r.Context(), "SELECT name FROM "+tableName+" WHERE name = '"+r.URL.Query().Get("name")+"'")
+		if err == nil {
+			rows.Close()
+			err = rows.Err()
+		}
+		if queryErr = err; events.IsSecurityError(err) {
+			return
+		}
+		w.Write([]byte("Hello World!\n"))
+	})
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	for _, tc := range []struct {
+		name    string
+		query   string
+		status  int
+		blocked bool
+	}{
+		{
+			name:   "no-sqli",
+			query:  "name=alice",
+			status: 200,
+		},
+		{
+			name:    "sqli",
+			query:   "name=" + url.QueryEscape("1' OR '1'='1"),
+			status:  403,
+			blocked: true,
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			mt := mocktracer.Start()
+			defer mt.Stop()
+			res, err := srv.Client().Get(srv.URL + "/user?" + tc.query)
+			require.NoError(t, err)
+			defer res.Body.Close()
+			require.Equal(t, tc.status, res.StatusCode)
+			if !tc.blocked {
+				require.NoError(t, queryErr)
+				return
+			}
+			// The query is blocked by canceling its context
+			require.True(t, events.IsSecurityError(queryErr))
+			require.True(t, errors.Is(queryErr, context.Canceled))
+		})
+	}
+}
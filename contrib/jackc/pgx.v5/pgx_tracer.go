@@ -7,9 +7,13 @@ package pgx
 
 import (
 	"context"
+	"time"
+
 	"gopkg.in/DataDog/dd-trace-go.v1/ddtrace"
 	"gopkg.in/DataDog/dd-trace-go.v1/ddtrace/ext"
 	"gopkg.in/DataDog/dd-trace-go.v1/ddtrace/tracer"
+	"gopkg.in/DataDog/dd-trace-go.v1/internal/appsec"
+	"gopkg.in/DataDog/dd-trace-go.v1/internal/appsec/emitter/sqlsec"
 
 	"github.com/jackc/pgx/v5"
 )
@@ -63,6 +67,7 @@ func newPgxTracer(opts ...Option) *pgxTracer {
 }
 
 func (t *pgxTracer) TraceQueryStart(ctx context.Context, conn *pgx.Conn, data pgx.TraceQueryStartData) context.Context {
+	ctx = protectQuery(ctx, data.SQL)
 	if !t.cfg.traceQuery {
 		return ctx
 	}
@@ -83,6 +88,11 @@ func (t *pgxTracer) TraceQueryEnd(ctx context.Context, _ *pgx.Conn, data pgx.Tra
 }
 
 func (t *pgxTracer) TraceBatchStart(ctx context.Context, conn *pgx.Conn, data pgx.TraceBatchStartData) context.Context {
+	for _, query := range data.Batch.QueuedQueries {
+		if ctx = protectQuery(ctx, query.SQL); ctx.Err() != nil {
+			break
+		}
+	}
 	if !t.cfg.traceBatch {
 		return ctx
 	}
@@ -144,6 +154,7 @@ func (t *pgxTracer) TraceCopyFromEnd(ctx context.Context, _ *pgx.Conn, data pgx.
 }
 
 func (t *pgxTracer) TracePrepareStart(ctx context.Context, conn *pgx.Conn, data pgx.TracePrepareStartData) context.Context {
+	ctx = protectQuery(ctx, data.SQL)
 	if !t.cfg.tracePrepare {
 		return ctx
 	}
@@ -213,3 +224,44 @@ func finishSpan(ctx context.Context, err error) {
 	}
 	span.Finish(tracer.WithError(err))
 }
+
+// protectQuery checks the given query for SQL injections when RASP is enabled. pgx tracer hooks cannot return errors,
+// so the query gets blocked by returning a context that is already done, which pgx checks before sending anything to
+// the database. The resulting pgx error wraps the *events.BlockingSecurityEvent error.
+func protectQuery(ctx context.Context, query string) context.Context {
+	if !appsec.RASPEnabled() {
+		return ctx
+	}
+	if err := sqlsec.ProtectSQLOperation(ctx, query, ext.DBSystemPostgreSQL); err != nil {
+		return &blockedContext{Context: ctx, err: &blockedQueryError{err: err}}
+	}
+	return ctx
+}
+
+// closedChan is the Done channel of every blockedContext.
+var closedChan = func() chan struct{} {
+	c := make(chan struct{})
+	close(c)
+	return c
+}()
+
+// blockedContext is a context that is already done because its query was
+// blocked, while keeping the values of its parent context.
+type blockedContext struct {
+	context.Context
+	err error
+}
+
+func (*blockedContext) Deadline() (time.Time, bool) { return time.Time{}, false }
+func (*blockedContext) Done() <-chan struct{}       { return closedChan }
+func (c *blockedContext) Err() error                { return c.err }
+
+// blockedQueryError is the error of a blockedContext. It is both a
+// context.Canceled error, as expected from a done context, and the blocking
+// error returned by the WAF.
+type blockedQueryError struct {
+	err error
+}
+
+func (e *blockedQueryError) Error() string   { return e.err.Error() }
+func (e *blockedQueryError) Unwrap() []error { return []error{e.err, context.Canceled} }
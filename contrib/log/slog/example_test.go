@@ -0,0 +1,40 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2024 Datadog, Inc.
+
+//go:build go1.21
+
+package slog_test
+
+import (
+	"context"
+	"log/slog"
+	"os"
+
+	slogtrace "gopkg.in/DataDog/dd-trace-go.v1/contrib/log/slog"
+	"gopkg.in/DataDog/dd-trace-go.v1/ddtrace/tracer"
+)
+
+func ExampleNewJSONHandler() {
+	// Ensure your tracer is started and stopped
+	// Setup slog, do this once at the beginning of your program
+	logger := slog.New(slogtrace.NewJSONHandler(os.Stdout, nil))
+
+	span, ctx := tracer.StartSpanFromContext(context.Background(), "mySpan")
+	defer span.Finish()
+
+	// Pass the current span context to the logger, with the Context variant of the logging functions
+	logger.InfoContext(ctx, "Completed some work!")
+}
+
+func ExampleWrapHandler() {
+	// Correlate the logs of any handler with the traces
+	logger := slog.New(slogtrace.WrapHandler(slog.NewTextHandler(os.Stdout, nil)))
+
+	span, ctx := tracer.StartSpanFromContext(context.Background(), "mySpan")
+	defer span.Finish()
+
+	// The correlation attributes stay at the top level of the logs of the loggers with groups
+	logger.WithGroup("request").InfoContext(ctx, "Completed some work!", "status", 200)
+}
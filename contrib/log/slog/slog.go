@@ -0,0 +1,142 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2024 Datadog, Inc.
+
+//go:build go1.21
+
+// Package slog provides a log/span correlation handler for the log/slog package (https://pkg.go.dev/log/slog).
+package slog
+
+import (
+	"context"
+	"io"
+	"log/slog"
+
+	"gopkg.in/DataDog/dd-trace-go.v1/contrib/internal/logcorrelation"
+	"gopkg.in/DataDog/dd-trace-go.v1/ddtrace"
+	"gopkg.in/DataDog/dd-trace-go.v1/ddtrace/ext"
+	"gopkg.in/DataDog/dd-trace-go.v1/ddtrace/tracer"
+	"gopkg.in/DataDog/dd-trace-go.v1/internal/telemetry"
+)
+
+const componentName = "log/slog"
+
+func init() {
+	telemetry.LoadIntegration(componentName)
+	tracer.MarkIntegrationImported("log/slog")
+}
+
+// NewJSONHandler returns a handler writing the logs to w as JSON objects, like slog.NewJSONHandler, with
+// the logs written in a span correlated with its trace, see WrapHandler.
+func NewJSONHandler(w io.Writer, opts *slog.HandlerOptions) slog.Handler {
+	return WrapHandler(slog.NewJSONHandler(w, opts))
+}
+
+// WrapHandler returns a handler wrapping h, which correlates the logs written in a span, whose context
+// is passed to the logger, with its trace. It adds to these logs the dd.trace_id, dd.span_id, dd.service,
+// dd.env and dd.version attributes, at their top level even when the logger has groups. The trace ID is
+// the 128-bit trace ID when DD_TRACE_128_BIT_TRACEID_LOGGING_ENABLED is set and the trace has one. The
+// logs written outside of a span are handled by h as they are, without allocations.
+func WrapHandler(h slog.Handler) slog.Handler {
+	return &handler{
+		Handler:          h,
+		base:             h,
+		use128BitTraceID: logcorrelation.Use128BitTraceID(),
+	}
+}
+
+// handler is a slog.Handler correlating the logs with the traces.
+type handler struct {
+	slog.Handler // Wrapped handler, with the attributes and groups of the logger.
+
+	// base is the wrapped handler, without the attributes and groups of the logger.
+	base slog.Handler
+
+	// groupsOrAttrs holds the groups and attributes of the logger, in the order they were added.
+	groupsOrAttrs []groupOrAttrs
+
+	// grouped is true when the logger has a group.
+	grouped bool
+
+	// use128BitTraceID is true when the logs have the 128-bit trace IDs.
+	use128BitTraceID bool
+}
+
+// groupOrAttrs is either a group or attributes of a logger.
+type groupOrAttrs struct {
+	group string      // Name of the group, empty for attributes.
+	attrs []slog.Attr // Attributes, nil for a group.
+}
+
+// Handle handles the record, with the attributes correlating it with the trace of the span in ctx.
+func (h *handler) Handle(ctx context.Context, rec slog.Record) error {
+	span, ok := tracer.SpanFromContext(ctx)
+	if !ok {
+		return h.Handler.Handle(ctx, rec)
+	}
+	attrs := h.spanAttrs(span.Context())
+	if !h.grouped {
+		// The record may be shared with other handlers: add the attributes to a copy.
+		rec = rec.Clone()
+		rec.AddAttrs(attrs...)
+		return h.Handler.Handle(ctx, rec)
+	}
+
+	// The attributes of the record would be in the groups of the logger: add the correlation attributes
+	// to the wrapped handler before its groups instead.
+	reqHandler := h.base.WithAttrs(attrs)
+	for _, goa := range h.groupsOrAttrs {
+		if goa.group != "" {
+			reqHandler = reqHandler.WithGroup(goa.group)
+		} else {
+			reqHandler = reqHandler.WithAttrs(goa.attrs)
+		}
+	}
+	return reqHandler.Handle(ctx, rec)
+}
+
+// WithAttrs returns a handler whose logs have the attributes attrs, in the current group.
+func (h *handler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	if len(attrs) == 0 {
+		return h
+	}
+	return h.with(groupOrAttrs{attrs: attrs}, h.Handler.WithAttrs(attrs))
+}
+
+// WithGroup returns a handler whose logs have the next attributes in the group name.
+func (h *handler) WithGroup(name string) slog.Handler {
+	if name == "" {
+		return h
+	}
+	return h.with(groupOrAttrs{group: name}, h.Handler.WithGroup(name))
+}
+
+// with returns a copy of h with a group or attributes, wrapping the handler wrapped.
+func (h *handler) with(goa groupOrAttrs, wrapped slog.Handler) *handler {
+	h2 := *h
+	h2.Handler = wrapped
+	h2.groupsOrAttrs = append(h.groupsOrAttrs[:len(h.groupsOrAttrs):len(h.groupsOrAttrs)], goa)
+	h2.grouped = h.grouped || goa.group != ""
+	return &h2
+}
+
+// spanAttrs returns the attributes correlating a log with the span of the context ctx.
+func (h *handler) spanAttrs(ctx ddtrace.SpanContext) []slog.Attr {
+	fields := logcorrelation.SpanFields(ctx, h.use128BitTraceID)
+	attrs := make([]slog.Attr, 0, 5)
+	attrs = append(attrs,
+		slog.String(ext.LogKeyTraceID, fields.TraceID),
+		slog.String(ext.LogKeySpanID, fields.SpanID),
+	)
+	if fields.Service != "" {
+		attrs = append(attrs, slog.String(ext.LogKeyService, fields.Service))
+	}
+	if fields.Env != "" {
+		attrs = append(attrs, slog.String(ext.LogKeyEnv, fields.Env))
+	}
+	if fields.Version != "" {
+		attrs = append(attrs, slog.String(ext.LogKeyVersion, fields.Version))
+	}
+	return attrs
+}
@@ -0,0 +1,137 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2024 Datadog, Inc.
+
+//go:build go1.21
+
+package slog
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"gopkg.in/DataDog/dd-trace-go.v1/ddtrace"
+	"gopkg.in/DataDog/dd-trace-go.v1/ddtrace/tracer"
+	"gopkg.in/DataDog/dd-trace-go.v1/internal/globalconfig"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func startTracer(t *testing.T) {
+	tracer.Start(tracer.WithService("test-service"), tracer.WithEnv("test-env"), tracer.WithServiceVersion("1.2.3"))
+	t.Cleanup(func() {
+		tracer.Stop()
+		globalconfig.SetServiceName("")
+		globalconfig.SetEnv("")
+		globalconfig.SetVersion("")
+	})
+}
+
+// decodeLogs decodes the JSON logs written to buf.
+func decodeLogs(t *testing.T, buf *bytes.Buffer) []map[string]interface{} {
+	var logs []map[string]interface{}
+	for _, line := range strings.Split(strings.TrimSpace(buf.String()), "\n") {
+		var log map[string]interface{}
+		require.NoError(t, json.Unmarshal([]byte(line), &log))
+		logs = append(logs, log)
+	}
+	return logs
+}
+
+func TestNewJSONHandler(t *testing.T) {
+	startTracer(t)
+	span, ctx := tracer.StartSpanFromContext(context.Background(), "testSpan", tracer.WithSpanID(1234))
+	defer span.Finish()
+
+	var buf bytes.Buffer
+	logger := slog.New(NewJSONHandler(&buf, nil))
+	logger.InfoContext(ctx, "in span", "key", "value")
+	logger.Info("outside of span")
+
+	logs := decodeLogs(t, &buf)
+	require.Len(t, logs, 2)
+	assert.Equal(t, "in span", logs[0]["msg"])
+	assert.Equal(t, "value", logs[0]["key"])
+	assert.Equal(t, strconv.FormatUint(span.Context().TraceID(), 10), logs[0]["dd.trace_id"])
+	assert.Equal(t, "1234", logs[0]["dd.span_id"])
+	assert.Equal(t, "test-service", logs[0]["dd.service"])
+	assert.Equal(t, "test-env", logs[0]["dd.env"])
+	assert.Equal(t, "1.2.3", logs[0]["dd.version"])
+
+	assert.Equal(t, "outside of span", logs[1]["msg"])
+	assert.NotContains(t, logs[1], "dd.trace_id")
+	assert.NotContains(t, logs[1], "dd.span_id")
+}
+
+func TestWithGroup(t *testing.T) {
+	startTracer(t)
+	span, ctx := tracer.StartSpanFromContext(context.Background(), "testSpan", tracer.WithSpanID(1234))
+	defer span.Finish()
+
+	var buf bytes.Buffer
+	logger := slog.New(NewJSONHandler(&buf, nil)).With("a", 1).WithGroup("g").With("b", 2)
+	logger.InfoContext(ctx, "in span", "c", 3)
+	logger.Info("outside of span", "c", 3)
+
+	logs := decodeLogs(t, &buf)
+	require.Len(t, logs, 2)
+	for _, log := range logs {
+		assert.Equal(t, 1.0, log["a"])
+		assert.Equal(t, map[string]interface{}{"b": 2.0, "c": 3.0}, log["g"])
+	}
+	// The correlation attributes are at the top level of the log, not in the group.
+	assert.Equal(t, "1234", logs[0]["dd.span_id"])
+	assert.Equal(t, "test-service", logs[0]["dd.service"])
+	assert.NotContains(t, logs[1], "dd.span_id")
+}
+
+func Test128BitTraceID(t *testing.T) {
+	t.Setenv("DD_TRACE_128_BIT_TRACEID_LOGGING_ENABLED", "true")
+	startTracer(t)
+	span, ctx := tracer.StartSpanFromContext(context.Background(), "testSpan")
+	defer span.Finish()
+
+	var buf bytes.Buffer
+	logger := slog.New(NewJSONHandler(&buf, nil))
+	logger.InfoContext(ctx, "in span")
+
+	logs := decodeLogs(t, &buf)
+	require.Len(t, logs, 1)
+	traceID := span.Context().(ddtrace.SpanContextW3C).TraceID128()
+	assert.Len(t, traceID, 32)
+	assert.Equal(t, traceID, logs[0]["dd.trace_id"])
+}
+
+// discardHandler is a slog.Handler discarding the logs.
+type discardHandler struct{}
+
+func (discardHandler) Enabled(context.Context, slog.Level) bool  { return true }
+func (discardHandler) Handle(context.Context, slog.Record) error { return nil }
+func (h discardHandler) WithAttrs([]slog.Attr) slog.Handler      { return h }
+func (h discardHandler) WithGroup(string) slog.Handler           { return h }
+
+func TestHandleWithoutSpanAllocations(t *testing.T) {
+	rec := slog.NewRecord(time.Now(), slog.LevelInfo, "outside of span", 0)
+	rec.AddAttrs(slog.Int("a", 1))
+	for name, h := range map[string]slog.Handler{
+		"default":  WrapHandler(discardHandler{}),
+		"grouped":  WrapHandler(discardHandler{}).WithAttrs([]slog.Attr{slog.Int("b", 2)}).WithGroup("g"),
+		"attrs":    WrapHandler(discardHandler{}).WithAttrs([]slog.Attr{slog.Int("b", 2)}),
+		"no-attrs": WrapHandler(discardHandler{}).WithAttrs(nil).WithGroup(""),
+	} {
+		t.Run(name, func(t *testing.T) {
+			allocs := testing.AllocsPerRun(100, func() {
+				_ = h.Handle(context.Background(), rec)
+			})
+			assert.Zero(t, allocs)
+		})
+	}
+}
@@ -0,0 +1,119 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2024 Datadog, Inc.
+
+// Package ginkgo provides a CI Visibility integration of Ginkgo (https://github.com/onsi/ginkgo), reporting
+// each spec as a test.
+package ginkgo
+
+import (
+	"runtime"
+	"strings"
+	"time"
+
+	"gopkg.in/DataDog/dd-trace-go.v1/civisibility"
+	"gopkg.in/DataDog/dd-trace-go.v1/civisibility/gotesting"
+	"gopkg.in/DataDog/dd-trace-go.v1/ddtrace/tracer"
+	"gopkg.in/DataDog/dd-trace-go.v1/internal/civisibility/constants"
+	"gopkg.in/DataDog/dd-trace-go.v1/internal/civisibility/utils"
+	"gopkg.in/DataDog/dd-trace-go.v1/internal/telemetry"
+
+	"github.com/onsi/ginkgo/v2"
+	"github.com/onsi/ginkgo/v2/types"
+)
+
+const componentName = "onsi/ginkgo.v2"
+
+func init() {
+	telemetry.LoadIntegration(componentName)
+	tracer.MarkIntegrationImported("github.com/onsi/ginkgo/v2")
+}
+
+const (
+	// testFramework represents the name of the testing framework.
+	testFramework = "github.com/onsi/ginkgo/v2"
+
+	// maxTestOutputSize is the maximum size of the output reported for a spec, only the end is kept beyond it.
+	maxTestOutputSize = 64 * 1024
+)
+
+// RunSpecs is the entry point of the Ginkgo spec runner, like ginkgo.RunSpecs. When the tests are run with
+// gotesting.RunM, each spec is reported to CI visibility as a test, named after the texts of its containers
+// and its own text, in a suite named after the description. The tests have the source location and labels
+// of the specs, and the skip reasons or failures reported by Ginkgo. The specs retried by Ginkgo with
+// --flake-attempts are reported with their last attempt, tagged as a retry.
+func RunSpecs(t ginkgo.GinkgoTestingT, description string, args ...interface{}) bool {
+	session := gotesting.GetTestSession()
+	if session == nil {
+		return ginkgo.RunSpecs(t, description, args...)
+	}
+
+	// The specs belong to the module of the Go test running them.
+	pc, _, _, _ := runtime.Caller(1)
+	moduleName, _ := utils.GetModuleAndSuiteName(pc)
+	suite := session.GetOrCreateModule(moduleName).GetOrCreateSuite(description)
+	suite.SetTag(constants.TestFramework, testFramework)
+	suite.SetTag(constants.TestFrameworkVersion, types.VERSION)
+	defer suite.Close()
+
+	ginkgo.ReportAfterEach(func(report ginkgo.SpecReport) {
+		reportSpec(suite, report)
+	})
+	return ginkgo.RunSpecs(t, description, args...)
+}
+
+// testSourceSetter is implemented by the tests of the civisibility package, to set the source location
+// of the tests which aren't Go functions, like the specs.
+type testSourceSetter interface {
+	SetTestSource(file string, line int)
+}
+
+// reportSpec reports a spec which ran, or was skipped, as a test of the suite.
+func reportSpec(suite civisibility.DdTestSuite, report ginkgo.SpecReport) {
+	startTime, endTime := report.StartTime, report.EndTime
+	if startTime.IsZero() {
+		startTime = time.Now()
+	}
+	if endTime.Before(startTime) {
+		endTime = startTime
+	}
+
+	test := suite.CreateTestWithStartTime(report.FullText(), startTime)
+	test.SetTag(constants.TestFramework, testFramework)
+	test.SetTag(constants.TestFrameworkVersion, types.VERSION)
+	if t, ok := test.(testSourceSetter); ok {
+		t.SetTestSource(report.LeafNodeLocation.FileName, report.LeafNodeLocation.LineNumber)
+	}
+	if labels := report.Labels(); len(labels) > 0 {
+		test.SetTag(constants.TestLabels, strings.Join(labels, ","))
+	}
+	if report.NumAttempts > 1 {
+		test.SetTag(constants.TestIsRetry, "true")
+	}
+
+	switch {
+	case report.State.Is(types.SpecStatePassed):
+		test.CloseWithFinishTime(civisibility.ResultStatusPass, endTime)
+	case report.State.Is(types.SpecStateSkipped | types.SpecStatePending):
+		skipReason := report.Failure.Message
+		if skipReason == "" {
+			skipReason = report.State.String()
+		}
+		test.CloseWithFinishTimeAndSkipReason(civisibility.ResultStatusSkip, endTime, skipReason)
+	default:
+		message := report.FailureMessage()
+		if report.Failure.ForwardedPanic != "" {
+			message = report.Failure.ForwardedPanic
+		}
+		test.SetErrorInfo(report.State.String(), message, report.FailureLocation().FullStackTrace)
+		if output := report.CombinedOutput(); output != "" {
+			if len(output) > maxTestOutputSize {
+				output = output[len(output)-maxTestOutputSize:]
+				test.SetTag(constants.TestOutputTruncated, "true")
+			}
+			test.SetTag(constants.TestOutput, output)
+		}
+		test.CloseWithFinishTime(civisibility.ResultStatusFail, endTime)
+	}
+}
@@ -0,0 +1,83 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2024 Datadog, Inc.
+
+package ginkgo
+
+import (
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/onsi/ginkgo/v2"
+	"github.com/stretchr/testify/assert"
+	"gopkg.in/DataDog/dd-trace-go.v1/civisibility/gotesting"
+	"gopkg.in/DataDog/dd-trace-go.v1/ddtrace/ext"
+	"gopkg.in/DataDog/dd-trace-go.v1/ddtrace/mocktracer"
+	"gopkg.in/DataDog/dd-trace-go.v1/internal/civisibility"
+	"gopkg.in/DataDog/dd-trace-go.v1/internal/civisibility/constants"
+)
+
+var mt mocktracer.Tracer
+
+func TestMain(m *testing.M) {
+	// Initialize civisibility using the mocktracer for testing
+	mt = civisibility.InitializeCiVisibilityMock()
+	os.Exit(gotesting.RunM(m))
+}
+
+var _ = ginkgo.Describe("Calculator", func() {
+	ginkgo.Context("when adding", func() {
+		ginkgo.It("sums the numbers", ginkgo.Label("math", "fast"), func() {})
+
+		ginkgo.It("is not supported yet", func() {
+			ginkgo.Skip("not supported")
+		})
+
+		ginkgo.PIt("is pending")
+	})
+})
+
+func TestRunSpecs(t *testing.T) {
+	if !RunSpecs(t, "Example Suite") {
+		return
+	}
+
+	var suite mocktracer.Span
+	tests := map[string]mocktracer.Span{}
+	for _, span := range mt.FinishedSpans() {
+		switch span.Tag(ext.SpanType) {
+		case constants.SpanTypeTest:
+			if span.Tag(constants.TestSuite) == "Example Suite" {
+				tests[span.Tag(constants.TestName).(string)] = span
+			}
+		case constants.SpanTypeTestSuite:
+			if span.Tag(constants.TestSuite) == "Example Suite" {
+				suite = span
+			}
+		}
+	}
+	if assert.NotNil(t, suite) {
+		assert.Equal(t, testFramework, suite.Tag(constants.TestFramework))
+	}
+	assert.Len(t, tests, 3)
+
+	pass := tests["Calculator when adding sums the numbers"]
+	if assert.NotNil(t, pass) {
+		assert.Equal(t, constants.TestStatusPass, pass.Tag(constants.TestStatus))
+		assert.Equal(t, "math,fast", pass.Tag(constants.TestLabels))
+		assert.True(t, strings.HasSuffix(pass.Tag(constants.TestSourceFile).(string), "ginkgo_test.go"))
+		assert.Equal(t, 32, pass.Tag(constants.TestSourceStartLine))
+	}
+	skip := tests["Calculator when adding is not supported yet"]
+	if assert.NotNil(t, skip) {
+		assert.Equal(t, constants.TestStatusSkip, skip.Tag(constants.TestStatus))
+		assert.Equal(t, "not supported", skip.Tag(constants.TestSkipReason))
+	}
+	pending := tests["Calculator when adding is pending"]
+	if assert.NotNil(t, pending) {
+		assert.Equal(t, constants.TestStatusSkip, pending.Tag(constants.TestStatus))
+		assert.Equal(t, "pending", pending.Tag(constants.TestSkipReason))
+	}
+}
@@ -0,0 +1,54 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2024 Datadog, Inc.
+
+package os_test
+
+import (
+	"io"
+	"io/fs"
+	"net/http"
+	"os"
+	"path/filepath"
+
+	"gopkg.in/DataDog/dd-trace-go.v1/appsec/events"
+	httptrace "gopkg.in/DataDog/dd-trace-go.v1/contrib/net/http"
+	ostrace "gopkg.in/DataDog/dd-trace-go.v1/contrib/os"
+)
+
+func Example() {
+	mux := httptrace.NewServeMux()
+	mux.HandleFunc("/download", func(w http.ResponseWriter, r *http.Request) {
+		f, err := ostrace.Open(r.Context(), filepath.Join("/var/data", r.URL.Query().Get("file")))
+		if events.IsSecurityError(err) {
+			// The request was blocked by AppSec: the response is handled by
+			// the tracer and nothing else must be written.
+			return
+		}
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusNotFound)
+			return
+		}
+		defer f.Close()
+		io.Copy(w, f)
+	})
+	http.ListenAndServe(":8080", mux)
+}
+
+func ExampleWrapFS() {
+	data := os.DirFS("/var/data")
+	mux := httptrace.NewServeMux()
+	mux.HandleFunc("/download", func(w http.ResponseWriter, r *http.Request) {
+		b, err := fs.ReadFile(ostrace.WrapFS(r.Context(), data), r.URL.Query().Get("file"))
+		if events.IsSecurityError(err) {
+			return
+		}
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusNotFound)
+			return
+		}
+		w.Write(b)
+	})
+	http.ListenAndServe(":8080", mux)
+}
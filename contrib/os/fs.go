@@ -0,0 +1,83 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2024 Datadog, Inc.
+
+package os
+
+import (
+	"context"
+	"io/fs"
+	"os"
+)
+
+// WrapFS returns a filesystem opening the files of fsys after checking them
+// for LFI within the request found in ctx. Since fs.FS methods don't take any
+// context, the returned filesystem is meant to be created for the request
+// being served, e.g. with WrapFS(r.Context(), fsys).
+//
+// Besides fs.FS, the returned filesystem implements fs.ReadFileFS,
+// fs.ReadDirFS, fs.StatFS and fs.SubFS, whose methods check the given name
+// before delegating to the io/fs helpers on fsys. The filesystems returned by
+// Sub are protected the same way. Note that the files are only checked when
+// accessed through the filesystem: the directory entries and file infos it
+// returns are not.
+func WrapFS(ctx context.Context, fsys fs.FS) fs.FS {
+	return &protectedFS{fsys: fsys, ctx: ctx}
+}
+
+type protectedFS struct {
+	fsys fs.FS
+	ctx  context.Context
+}
+
+var (
+	_ fs.ReadFileFS = (*protectedFS)(nil)
+	_ fs.ReadDirFS  = (*protectedFS)(nil)
+	_ fs.StatFS     = (*protectedFS)(nil)
+	_ fs.SubFS      = (*protectedFS)(nil)
+)
+
+// Open implements fs.FS.
+func (p *protectedFS) Open(name string) (fs.File, error) {
+	if err := protect(p.ctx, "open", name, os.O_RDONLY, 0); err != nil {
+		return nil, err
+	}
+	return p.fsys.Open(name)
+}
+
+// ReadFile implements fs.ReadFileFS.
+func (p *protectedFS) ReadFile(name string) ([]byte, error) {
+	if err := protect(p.ctx, "readfile", name, os.O_RDONLY, 0); err != nil {
+		return nil, err
+	}
+	return fs.ReadFile(p.fsys, name)
+}
+
+// ReadDir implements fs.ReadDirFS.
+func (p *protectedFS) ReadDir(name string) ([]fs.DirEntry, error) {
+	if err := protect(p.ctx, "readdir", name, os.O_RDONLY, 0); err != nil {
+		return nil, err
+	}
+	return fs.ReadDir(p.fsys, name)
+}
+
+// Stat implements fs.StatFS.
+func (p *protectedFS) Stat(name string) (fs.FileInfo, error) {
+	if err := protect(p.ctx, "stat", name, os.O_RDONLY, 0); err != nil {
+		return nil, err
+	}
+	return fs.Stat(p.fsys, name)
+}
+
+// Sub implements fs.SubFS.
+func (p *protectedFS) Sub(dir string) (fs.FS, error) {
+	if err := protect(p.ctx, "sub", dir, os.O_RDONLY, 0); err != nil {
+		return nil, err
+	}
+	sub, err := fs.Sub(p.fsys, dir)
+	if err != nil {
+		return nil, err
+	}
+	return WrapFS(p.ctx, sub), nil
+}
@@ -0,0 +1,61 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2024 Datadog, Inc.
+
+// Package os provides functions to protect the files opened with the os package
+// (https://golang.org/pkg/os) and the io/fs package (https://golang.org/pkg/io/fs)
+// against Local File Inclusion (LFI) with AppSec RASP.
+//
+// The paths of the files opened while serving a request monitored by AppSec are
+// checked by the WAF before the files get opened. When the WAF decides to
+// block, the file is not opened and the returned error wraps an
+// *events.BlockingSecurityEvent error, which can be detected with
+// events.IsSecurityError.
+package os // import "gopkg.in/DataDog/dd-trace-go.v1/contrib/os"
+
+import (
+	"context"
+	"io/fs"
+	"os"
+
+	"gopkg.in/DataDog/dd-trace-go.v1/ddtrace/tracer"
+	"gopkg.in/DataDog/dd-trace-go.v1/internal/appsec"
+	"gopkg.in/DataDog/dd-trace-go.v1/internal/appsec/emitter/ossec"
+	"gopkg.in/DataDog/dd-trace-go.v1/internal/telemetry"
+)
+
+const componentName = "os"
+
+func init() {
+	telemetry.LoadIntegration(componentName)
+	tracer.MarkIntegrationImported("os")
+}
+
+// Open opens the named file for reading, like os.Open, after checking it for
+// LFI within the request found in ctx.
+func Open(ctx context.Context, name string) (*os.File, error) {
+	return OpenFile(ctx, name, os.O_RDONLY, 0)
+}
+
+// OpenFile opens the named file with the given flag and permission, like
+// os.OpenFile, after checking it for LFI within the request found in ctx.
+func OpenFile(ctx context.Context, name string, flag int, perm fs.FileMode) (*os.File, error) {
+	if err := protect(ctx, "open", name, flag, perm); err != nil {
+		return nil, err
+	}
+	return os.OpenFile(name, flag, perm)
+}
+
+// protect checks the given file for LFI when RASP is enabled. The returned
+// error is an *fs.PathError wrapping the blocking error when the file must not
+// be opened.
+func protect(ctx context.Context, op, name string, flag int, perm fs.FileMode) error {
+	if !appsec.RASPEnabled() {
+		return nil
+	}
+	if err := ossec.ProtectOpen(ctx, name, flag, perm); err != nil {
+		return &fs.PathError{Op: op, Path: name, Err: err}
+	}
+	return nil
+}
@@ -0,0 +1,82 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2024 Datadog, Inc.
+
+package os
+
+import (
+	"context"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"testing"
+	"testing/fstest"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestOpen(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "file.txt")
+	require.NoError(t, os.WriteFile(path, []byte("hello"), 0o600))
+
+	t.Run("open", func(t *testing.T) {
+		f, err := Open(context.Background(), path)
+		require.NoError(t, err)
+		defer f.Close()
+		b, err := io.ReadAll(f)
+		require.NoError(t, err)
+		require.Equal(t, "hello", string(b))
+	})
+
+	t.Run("open-file", func(t *testing.T) {
+		f, err := OpenFile(context.Background(), path, os.O_WRONLY|os.O_APPEND, 0)
+		require.NoError(t, err)
+		_, err = f.WriteString(" world")
+		require.NoError(t, err)
+		require.NoError(t, f.Close())
+		b, err := os.ReadFile(path)
+		require.NoError(t, err)
+		require.Equal(t, "hello world", string(b))
+	})
+
+	t.Run("not-found", func(t *testing.T) {
+		_, err := Open(context.Background(), filepath.Join(t.TempDir(), "missing.txt"))
+		require.ErrorIs(t, err, fs.ErrNotExist)
+	})
+}
+
+func TestWrapFS(t *testing.T) {
+	fsys := WrapFS(context.Background(), fstest.MapFS{
+		"dir/file.txt": {Data: []byte("hello")},
+	})
+	b, err := fs.ReadFile(fsys, "dir/file.txt")
+	require.NoError(t, err)
+	require.Equal(t, "hello", string(b))
+
+	_, err = fsys.Open("dir/missing.txt")
+	require.ErrorIs(t, err, fs.ErrNotExist)
+
+	t.Run("fs-interfaces", func(t *testing.T) {
+		b, err := fsys.(fs.ReadFileFS).ReadFile("dir/file.txt")
+		require.NoError(t, err)
+		require.Equal(t, "hello", string(b))
+
+		entries, err := fsys.(fs.ReadDirFS).ReadDir("dir")
+		require.NoError(t, err)
+		require.Len(t, entries, 1)
+		require.Equal(t, "file.txt", entries[0].Name())
+
+		info, err := fsys.(fs.StatFS).Stat("dir/file.txt")
+		require.NoError(t, err)
+		require.EqualValues(t, 5, info.Size())
+
+		sub, err := fsys.(fs.SubFS).Sub("dir")
+		require.NoError(t, err)
+		require.IsType(t, &protectedFS{}, sub)
+		b, err = fs.ReadFile(sub, "file.txt")
+		require.NoError(t, err)
+		require.Equal(t, "hello", string(b))
+	})
+}
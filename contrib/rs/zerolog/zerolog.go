@@ -0,0 +1,48 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2024 Datadog, Inc.
+
+// Package zerolog provides a log/span correlation hook for the rs/zerolog package (https://github.com/rs/zerolog).
+package zerolog
+
+import (
+	"gopkg.in/DataDog/dd-trace-go.v1/contrib/internal/logcorrelation"
+	"gopkg.in/DataDog/dd-trace-go.v1/ddtrace/ext"
+	"gopkg.in/DataDog/dd-trace-go.v1/ddtrace/tracer"
+	"gopkg.in/DataDog/dd-trace-go.v1/internal/telemetry"
+
+	"github.com/rs/zerolog"
+)
+
+const componentName = "rs/zerolog"
+
+func init() {
+	telemetry.LoadIntegration(componentName)
+	tracer.MarkIntegrationImported("github.com/rs/zerolog")
+}
+
+// DDContextLogHook ensures that any span in the context of an event, set with Ctx, is correlated to log output.
+// It adds the dd.trace_id, dd.span_id, dd.service, dd.env and dd.version fields to the event. The trace ID is
+// the 128-bit trace ID when DD_TRACE_128_BIT_TRACEID_LOGGING_ENABLED is set and the trace has one.
+type DDContextLogHook struct{}
+
+// Run implements zerolog.Hook interface, attaches trace and span details found in the event context
+func (d DDContextLogHook) Run(e *zerolog.Event, _ zerolog.Level, _ string) {
+	span, found := tracer.SpanFromContext(e.GetCtx())
+	if !found {
+		return
+	}
+	fields := logcorrelation.SpanFields(span.Context(), logcorrelation.Use128BitTraceID())
+	e.Str(ext.LogKeyTraceID, fields.TraceID)
+	e.Str(ext.LogKeySpanID, fields.SpanID)
+	if fields.Service != "" {
+		e.Str(ext.LogKeyService, fields.Service)
+	}
+	if fields.Env != "" {
+		e.Str(ext.LogKeyEnv, fields.Env)
+	}
+	if fields.Version != "" {
+		e.Str(ext.LogKeyVersion, fields.Version)
+	}
+}
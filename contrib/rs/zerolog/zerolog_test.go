@@ -0,0 +1,83 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2024 Datadog, Inc.
+
+package zerolog
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"strconv"
+	"testing"
+
+	"gopkg.in/DataDog/dd-trace-go.v1/ddtrace"
+	"gopkg.in/DataDog/dd-trace-go.v1/ddtrace/tracer"
+	"gopkg.in/DataDog/dd-trace-go.v1/internal/globalconfig"
+
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func startTracer(t *testing.T) {
+	tracer.Start(tracer.WithService("test-service"), tracer.WithEnv("test-env"), tracer.WithServiceVersion("1.2.3"))
+	t.Cleanup(func() {
+		tracer.Stop()
+		globalconfig.SetServiceName("")
+		globalconfig.SetEnv("")
+		globalconfig.SetVersion("")
+	})
+}
+
+func TestRun(t *testing.T) {
+	startTracer(t)
+	span, ctx := tracer.StartSpanFromContext(context.Background(), "testSpan", tracer.WithSpanID(1234))
+	defer span.Finish()
+
+	var buf bytes.Buffer
+	logger := zerolog.New(&buf).Hook(DDContextLogHook{})
+	logger.Info().Ctx(ctx).Msg("in span")
+
+	var log map[string]interface{}
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &log))
+	assert.Equal(t, map[string]interface{}{
+		"level":       "info",
+		"message":     "in span",
+		"dd.trace_id": strconv.FormatUint(span.Context().TraceID(), 10),
+		"dd.span_id":  "1234",
+		"dd.service":  "test-service",
+		"dd.env":      "test-env",
+		"dd.version":  "1.2.3",
+	}, log)
+
+	// The span can also be in the context of the logger.
+	buf.Reset()
+	ctxLogger := logger.With().Ctx(ctx).Logger()
+	ctxLogger.Info().Msg("logger in span")
+	log = nil
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &log))
+	assert.Equal(t, "1234", log["dd.span_id"])
+
+	buf.Reset()
+	logger.Info().Msg("without span")
+	assert.Equal(t, `{"level":"info","message":"without span"}`+"\n", buf.String())
+}
+
+func TestRun128BitTraceID(t *testing.T) {
+	t.Setenv("DD_TRACE_128_BIT_TRACEID_LOGGING_ENABLED", "true")
+	startTracer(t)
+	span, ctx := tracer.StartSpanFromContext(context.Background(), "testSpan")
+	defer span.Finish()
+
+	var buf bytes.Buffer
+	logger := zerolog.New(&buf).Hook(DDContextLogHook{})
+	logger.Info().Ctx(ctx).Msg("in span")
+
+	var log map[string]interface{}
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &log))
+	traceID := span.Context().(ddtrace.SpanContextW3C).TraceID128()
+	assert.Len(t, traceID, 32)
+	assert.Equal(t, traceID, log["dd.trace_id"])
+}
@@ -0,0 +1,172 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2024 Datadog, Inc.
+
+// Package suite provides a CI Visibility integration of the testify suites (https://github.com/stretchr/testify),
+// reporting each test method of a suite as a test.
+//
+// Run re-implements suite.Run of testify, as the test methods must be run as CI Visibility tests. It tracks
+// suite.Run of testify v1.8.4, the version required by this module, whose changes must be ported to Run when
+// upgrading testify.
+package suite
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"reflect"
+	"regexp"
+	"runtime"
+	"runtime/debug"
+	"testing"
+	"time"
+
+	"gopkg.in/DataDog/dd-trace-go.v1/civisibility/gotesting"
+	"gopkg.in/DataDog/dd-trace-go.v1/ddtrace/tracer"
+	"gopkg.in/DataDog/dd-trace-go.v1/internal/telemetry"
+
+	"github.com/stretchr/testify/suite"
+)
+
+const componentName = "stretchr/testify"
+
+func init() {
+	telemetry.LoadIntegration(componentName)
+	tracer.MarkIntegrationImported("github.com/stretchr/testify")
+}
+
+// testMethodRegex matches the names of the test methods of a suite.
+var testMethodRegex = regexp.MustCompile("^Test")
+
+// Run takes a testing suite and runs all of the tests attached to it, like suite.Run of testify does. When
+// the tests are run with gotesting.RunM, each test method is reported to CI visibility as a test of a suite
+// named after the suite type, with the source location of the method, and failed methods are retried by
+// Automatic Test Retries along with their SetupTest and TearDownTest.
+func Run(t *testing.T, s suite.TestingSuite) {
+	defer recoverAndFailOnPanic(t)
+
+	s.SetT(t)
+	s.SetS(s)
+
+	var suiteSetupDone bool
+
+	var stats *suite.SuiteInformation
+	if _, ok := s.(suite.WithStats); ok {
+		stats = &suite.SuiteInformation{TestStats: map[string]*suite.TestInformation{}}
+	}
+
+	var tests []gotesting.Subtest
+	methodFinder := reflect.TypeOf(s)
+	suiteName := methodFinder.Elem().Name()
+
+	for i := 0; i < methodFinder.NumMethod(); i++ {
+		method := methodFinder.Method(i)
+
+		ok, err := methodFilter(method.Name)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "testify: invalid regexp for -m: %s\n", err)
+			os.Exit(1)
+		}
+
+		if !ok {
+			continue
+		}
+
+		if !suiteSetupDone {
+			if stats != nil {
+				stats.Start = time.Now()
+			}
+
+			if setupAllSuite, ok := s.(suite.SetupAllSuite); ok {
+				setupAllSuite.SetupSuite()
+			}
+
+			suiteSetupDone = true
+		}
+
+		tests = append(tests, gotesting.Subtest{
+			Name: method.Name,
+			Func: runtime.FuncForPC(method.Func.Pointer()),
+			F: func(t *testing.T) {
+				parentT := s.T()
+				s.SetT(t)
+				defer recoverAndFailOnPanic(t)
+				defer func() {
+					r := recover()
+
+					if stats != nil {
+						stats.TestStats[method.Name].End = time.Now()
+						stats.TestStats[method.Name].Passed = !t.Failed() && r == nil
+					}
+
+					if afterTestSuite, ok := s.(suite.AfterTest); ok {
+						afterTestSuite.AfterTest(suiteName, method.Name)
+					}
+
+					if tearDownTestSuite, ok := s.(suite.TearDownTestSuite); ok {
+						tearDownTestSuite.TearDownTest()
+					}
+
+					s.SetT(parentT)
+					failOnPanic(t, r)
+				}()
+
+				if setupTestSuite, ok := s.(suite.SetupTestSuite); ok {
+					setupTestSuite.SetupTest()
+				}
+				if beforeTestSuite, ok := s.(suite.BeforeTest); ok {
+					beforeTestSuite.BeforeTest(suiteName, method.Name)
+				}
+
+				if stats != nil {
+					stats.TestStats[method.Name] = &suite.TestInformation{TestName: method.Name, Start: time.Now()}
+				}
+
+				method.Func.Call([]reflect.Value{reflect.ValueOf(s)})
+			},
+		})
+	}
+	if suiteSetupDone {
+		defer func() {
+			if tearDownAllSuite, ok := s.(suite.TearDownAllSuite); ok {
+				tearDownAllSuite.TearDownSuite()
+			}
+
+			if suiteWithStats, measureStats := s.(suite.WithStats); measureStats {
+				stats.End = time.Now()
+				suiteWithStats.HandleStats(suiteName, stats)
+			}
+		}()
+	}
+
+	if len(tests) == 0 {
+		t.Log("warning: no tests to run")
+		return
+	}
+	gotesting.RunSubtests(t, suiteName, tests)
+}
+
+// methodFilter filters the test methods according to the regular expression of the -testify.m flag.
+func methodFilter(name string) (bool, error) {
+	if !testMethodRegex.MatchString(name) {
+		return false, nil
+	}
+	var matchMethod string
+	if f := flag.Lookup("testify.m"); f != nil {
+		matchMethod = f.Value.String()
+	}
+	return regexp.MatchString(matchMethod, name)
+}
+
+func recoverAndFailOnPanic(t *testing.T) {
+	r := recover()
+	failOnPanic(t, r)
+}
+
+func failOnPanic(t *testing.T, r interface{}) {
+	if r != nil {
+		t.Errorf("test panicked: %v\n%s", r, debug.Stack())
+		t.FailNow()
+	}
+}
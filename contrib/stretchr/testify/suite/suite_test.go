@@ -0,0 +1,143 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2024 Datadog, Inc.
+
+package suite
+
+import (
+	"flag"
+	"os"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+	"gopkg.in/DataDog/dd-trace-go.v1/civisibility/gotesting"
+	"gopkg.in/DataDog/dd-trace-go.v1/ddtrace/ext"
+	"gopkg.in/DataDog/dd-trace-go.v1/ddtrace/mocktracer"
+	"gopkg.in/DataDog/dd-trace-go.v1/internal/civisibility"
+	"gopkg.in/DataDog/dd-trace-go.v1/internal/civisibility/constants"
+)
+
+var mt mocktracer.Tracer
+
+func TestMain(m *testing.M) {
+	// Initialize civisibility using the mocktracer for testing
+	mt = civisibility.InitializeCiVisibilityMock()
+	// Enable the Automatic Test Retries of the failed suite methods
+	os.Setenv(constants.CiVisibilityFlakyRetryEnabledEnvironmentVariable, "true")
+	os.Exit(gotesting.RunM(m))
+}
+
+type exampleSuite struct {
+	suite.Suite
+	setupTests int
+}
+
+func (s *exampleSuite) SetupTest() {
+	s.setupTests++
+}
+
+func (s *exampleSuite) TestPass() {
+	s.Equal(1, s.setupTests)
+}
+
+func (s *exampleSuite) TestSkip() {
+	s.T().Skip("not supported")
+}
+
+func (s *exampleSuite) NotATest() {
+	s.Fail("not a test method")
+}
+
+func TestRun(t *testing.T) {
+	t.Run("example", func(t *testing.T) {
+		Run(t, new(exampleSuite))
+	})
+	if t.Failed() {
+		return
+	}
+
+	tests := map[string]mocktracer.Span{}
+	for _, span := range mt.FinishedSpans() {
+		if span.Tag(ext.SpanType) == constants.SpanTypeTest && span.Tag(constants.TestSuite) == "exampleSuite" {
+			tests[span.Tag(constants.TestName).(string)] = span
+		}
+	}
+	assert.Len(t, tests, 2)
+
+	pass := tests["TestPass"]
+	if assert.NotNil(t, pass) {
+		assert.Equal(t, constants.TestStatusPass, pass.Tag(constants.TestStatus))
+		assert.True(t, strings.HasSuffix(pass.Tag(constants.TestSourceFile).(string), "suite_test.go"))
+		assert.NotNil(t, pass.Tag(constants.TestSourceStartLine))
+	}
+	skip := tests["TestSkip"]
+	if assert.NotNil(t, skip) {
+		assert.Equal(t, constants.TestStatusSkip, skip.Tag(constants.TestStatus))
+		if !testing.Verbose() {
+			assert.Equal(t, "not supported", skip.Tag(constants.TestSkipReason))
+		}
+	}
+}
+
+type filteredSuite struct {
+	exampleSuite
+}
+
+func TestRunMethodFilter(t *testing.T) {
+	require.NoError(t, flag.Set("testify.m", "Pass$"))
+	defer flag.Set("testify.m", "")
+
+	t.Run("filtered", func(t *testing.T) {
+		Run(t, new(filteredSuite))
+	})
+	if t.Failed() {
+		return
+	}
+
+	var tests []string
+	for _, span := range mt.FinishedSpans() {
+		if span.Tag(ext.SpanType) == constants.SpanTypeTest && span.Tag(constants.TestSuite) == "filteredSuite" {
+			tests = append(tests, span.Tag(constants.TestName).(string))
+		}
+	}
+	assert.Equal(t, []string{"TestPass"}, tests)
+}
+
+type parallelSuite struct {
+	suite.Suite
+	subtestExecutions int32
+}
+
+func (s *parallelSuite) TestParallelSubtest() {
+	s.T().Run("parallel", func(t *testing.T) {
+		t.Parallel()
+		if atomic.AddInt32(&s.subtestExecutions, 1) == 1 {
+			t.Fatal("flaky failure")
+		}
+	})
+}
+
+func TestRunParallelSubtest(t *testing.T) {
+	s := new(parallelSuite)
+	t.Run("parallel", func(t *testing.T) {
+		Run(t, s)
+	})
+	if t.Failed() {
+		return
+	}
+
+	// The failure of the parallel subtest is retried by Automatic Test Retries
+	var statuses []any
+	for _, span := range mt.FinishedSpans() {
+		if span.Tag(ext.SpanType) == constants.SpanTypeTest && span.Tag(constants.TestSuite) == "parallelSuite" {
+			statuses = append(statuses, span.Tag(constants.TestStatus))
+		}
+	}
+	assert.Equal(t, []any{constants.TestStatusFail, constants.TestStatusPass}, statuses)
+	assert.Equal(t, int32(2), atomic.LoadInt32(&s.subtestExecutions))
+}
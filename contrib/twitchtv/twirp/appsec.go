@@ -0,0 +1,117 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2024 Datadog, Inc.
+
+package twirp
+
+import (
+	"context"
+	"net/http"
+
+	"gopkg.in/DataDog/dd-trace-go.v1/appsec/events"
+	"gopkg.in/DataDog/dd-trace-go.v1/ddtrace/tracer"
+	"gopkg.in/DataDog/dd-trace-go.v1/internal/appsec/emitter/httpsec"
+	"gopkg.in/DataDog/dd-trace-go.v1/internal/appsec/trace"
+	"gopkg.in/DataDog/dd-trace-go.v1/internal/appsec/trace/httptrace"
+	"gopkg.in/DataDog/dd-trace-go.v1/internal/log"
+
+	"github.com/twitchtv/twirp"
+)
+
+type appsecMonitorKey struct{}
+
+// NewServerInterceptor returns the twirp interceptor monitoring the decoded
+// request messages with AppSec. It is used in conjunction with WrapServer,
+// which monitors the rest of the request, and must be passed to the server
+// constructor using twirp.WithServerInterceptors. Blocked requests are
+// answered with a twirp.PermissionDenied error.
+func NewServerInterceptor() twirp.Interceptor {
+	return func(next twirp.Method) twirp.Method {
+		return func(ctx context.Context, req interface{}) (interface{}, error) {
+			m, _ := ctx.Value(appsecMonitorKey{}).(*httpsec.RequestMonitor)
+			if m == nil {
+				// AppSec is disabled or the server is not wrapped with WrapServer
+				return next(ctx, req)
+			}
+			// The returned error can be ignored as it comes along with the
+			// blocking handler checked right after.
+			_ = httpsec.MonitorParsedBody(ctx, req)
+			if m.BlockingHandler() != nil {
+				return nil, newBlockedError()
+			}
+			res, err := next(ctx, req)
+			// The request can also be blocked while executing the handler,
+			// e.g. by the AppSec SDK or RASP.
+			if m.BlockingHandler() != nil {
+				return nil, newBlockedError()
+			}
+			return res, err
+		}
+	}
+}
+
+// serveWithAppSec serves the request with the given handler while monitoring
+// it with AppSec. The request handler is not called when the request gets
+// blocked before it.
+func serveWithAppSec(w http.ResponseWriter, r *http.Request, span tracer.Span, h http.Handler) {
+	ipTags, clientIP := httptrace.ClientIPTags(r.Header, true, r.RemoteAddr)
+	log.Debug("appsec: http client ip detection returned `%s` given the http headers `%v`", clientIP, r.Header)
+	trace.SetTags(span, ipTags)
+
+	args := httpsec.MakeHandlerOperationArgs(r, clientIP, nil)
+	ctx, m := httpsec.StartRequestMonitor(r.Context(), span, args)
+	rw := &appsecResponseWriter{ResponseWriter: w}
+	defer func() {
+		m.Finish(httpsec.MakeHandlerOperationRes(rw))
+	}()
+
+	if m.BlockingHandler() != nil {
+		writeBlockedError(rw)
+		return
+	}
+	ctx = context.WithValue(ctx, appsecMonitorKey{}, m)
+	h.ServeHTTP(rw, r.WithContext(ctx))
+	if m.BlockingHandler() != nil && rw.status == 0 {
+		// The request got blocked without the handler responding, e.g. when
+		// NewServerInterceptor is not used.
+		writeBlockedError(rw)
+	}
+}
+
+// newBlockedError returns the twirp error of blocked requests. It wraps an
+// *events.BlockingSecurityEvent so that it can be told apart from other errors.
+func newBlockedError() twirp.Error {
+	return twirp.WrapError(twirp.NewError(twirp.PermissionDenied, "request blocked"), &events.BlockingSecurityEvent{})
+}
+
+func writeBlockedError(w http.ResponseWriter) {
+	if err := twirp.WriteError(w, newBlockedError()); err != nil {
+		log.Debug("contrib/twitchtv/twirp: failed to write the blocking response: %v", err)
+	}
+}
+
+// appsecResponseWriter records the response status code reported to AppSec.
+type appsecResponseWriter struct {
+	http.ResponseWriter
+	status int
+}
+
+// Status returns the status code that was written, or 0 if none was.
+func (w *appsecResponseWriter) Status() int {
+	return w.status
+}
+
+func (w *appsecResponseWriter) WriteHeader(status int) {
+	if w.status == 0 {
+		w.status = status
+	}
+	w.ResponseWriter.WriteHeader(status)
+}
+
+func (w *appsecResponseWriter) Write(b []byte) (int, error) {
+	if w.status == 0 {
+		w.status = http.StatusOK
+	}
+	return w.ResponseWriter.Write(b)
+}
@@ -0,0 +1,142 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2024 Datadog, Inc.
+
+package twirp
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	pappsec "gopkg.in/DataDog/dd-trace-go.v1/appsec"
+	"gopkg.in/DataDog/dd-trace-go.v1/ddtrace/mocktracer"
+	"gopkg.in/DataDog/dd-trace-go.v1/internal/appsec"
+
+	"github.com/stretchr/testify/require"
+	"github.com/twitchtv/twirp"
+	"github.com/twitchtv/twirp/example"
+)
+
+// blockedUserSize is the hat size making the test server set the blocked user
+// of the test rules.
+const blockedUserSize = 666
+
+type appsecHaberdasher struct{}
+
+func (appsecHaberdasher) MakeHat(ctx context.Context, size *example.Size) (*example.Hat, error) {
+	if size.Inches == blockedUserSize {
+		if err := pappsec.SetUser(ctx, "blocked-user-1"); err != nil {
+			return nil, err
+		}
+	}
+	return &example.Hat{Size: size.Inches, Color: "purple", Name: "doggie beanie"}, nil
+}
+
+func startAppSecServer(t *testing.T) example.Haberdasher {
+	server := example.NewHaberdasherServer(appsecHaberdasher{}, NewServerHooks(), twirp.WithServerInterceptors(NewServerInterceptor()))
+	srv := httptest.NewServer(WrapServer(server))
+	t.Cleanup(srv.Close)
+	return example.NewHaberdasherJSONClient(srv.URL, http.DefaultClient)
+}
+
+func findHandlerSpan(t *testing.T, mt mocktracer.Tracer) mocktracer.Span {
+	for _, s := range mt.FinishedSpans() {
+		if s.OperationName() == "twirp.handler" {
+			return s
+		}
+	}
+	require.FailNow(t, "twirp.handler span not found")
+	return nil
+}
+
+func TestAppSec(t *testing.T) {
+	appsec.Start()
+	defer appsec.Stop()
+	if !appsec.Enabled() {
+		t.Skip("appsec disabled")
+	}
+
+	client := startAppSecServer(t)
+
+	mt := mocktracer.Start()
+	defer mt.Stop()
+
+	// Send a security scanner attack (according to appsec rule id ua0-600-55x)
+	header := make(http.Header)
+	header.Set("User-Agent", "Arachni/v1")
+	ctx, err := twirp.WithHTTPRequestHeaders(context.Background(), header)
+	require.NoError(t, err)
+	hat, err := client.MakeHat(ctx, &example.Size{Inches: 6})
+	require.NoError(t, err)
+	require.Equal(t, int32(6), hat.Size)
+
+	span := findHandlerSpan(t, mt)
+	require.Equal(t, 1, span.Tag("_dd.appsec.enabled"))
+	event, _ := span.Tag("_dd.appsec.json").(string)
+	require.Contains(t, event, "ua0-600-12x")
+}
+
+// Test that IP and user blocking work by using custom rules/rules data
+func TestBlocking(t *testing.T) {
+	t.Setenv("DD_APPSEC_RULES", "../../../internal/appsec/testdata/blocking.json")
+	appsec.Start()
+	defer appsec.Stop()
+	if !appsec.Enabled() {
+		t.Skip("AppSec needs to be enabled for this test")
+	}
+
+	client := startAppSecServer(t)
+
+	for _, tc := range []struct {
+		name      string
+		ip        string
+		size      int32
+		ruleMatch string
+	}{
+		{
+			name: "no-block",
+			ip:   "1.2.3.5",
+			size: 6,
+		},
+		{
+			name:      "ip",
+			ip:        "1.2.3.4",
+			size:      6,
+			ruleMatch: "blk-001-001",
+		},
+		{
+			name:      "user",
+			ip:        "1.2.3.5",
+			size:      blockedUserSize,
+			ruleMatch: "blk-001-002",
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			mt := mocktracer.Start()
+			defer mt.Stop()
+
+			header := make(http.Header)
+			header.Set("X-Forwarded-For", tc.ip)
+			ctx, err := twirp.WithHTTPRequestHeaders(context.Background(), header)
+			require.NoError(t, err)
+			hat, err := client.MakeHat(ctx, &example.Size{Inches: tc.size})
+
+			span := findHandlerSpan(t, mt)
+			if tc.ruleMatch == "" {
+				require.NoError(t, err)
+				require.Equal(t, tc.size, hat.Size)
+				require.NotContains(t, span.Tags(), "appsec.blocked")
+				return
+			}
+			var twerr twirp.Error
+			require.ErrorAs(t, err, &twerr)
+			require.Equal(t, twirp.PermissionDenied, twerr.Code())
+			require.Equal(t, true, span.Tag("appsec.blocked"))
+			event, _ := span.Tag("_dd.appsec.json").(string)
+			require.Contains(t, event, tc.ruleMatch)
+		})
+	}
+}
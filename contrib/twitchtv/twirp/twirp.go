@@ -16,6 +16,7 @@ import (
 
 	"gopkg.in/DataDog/dd-trace-go.v1/ddtrace/ext"
 	"gopkg.in/DataDog/dd-trace-go.v1/ddtrace/tracer"
+	"gopkg.in/DataDog/dd-trace-go.v1/internal/appsec"
 	"gopkg.in/DataDog/dd-trace-go.v1/internal/log"
 	"gopkg.in/DataDog/dd-trace-go.v1/internal/namingschema"
 	"gopkg.in/DataDog/dd-trace-go.v1/internal/telemetry"
@@ -117,6 +118,9 @@ func (wc *wrappedClient) Do(req *http.Request) (*http.Response, error) {
 }
 
 // WrapServer wraps an http.Handler to add distributed tracing to a Twirp server.
+// When AppSec is enabled, the requests are also monitored and protected by
+// AppSec, along with their decoded messages when the server is created with
+// the interceptor returned by NewServerInterceptor.
 func WrapServer(h http.Handler, opts ...Option) http.Handler {
 	cfg := new(config)
 	serverDefaults(cfg)
@@ -145,6 +149,10 @@ func WrapServer(h http.Handler, opts ...Option) http.Handler {
 		defer span.Finish()
 
 		r = r.WithContext(ctx)
+		if appsec.Enabled() {
+			serveWithAppSec(w, r, span, h)
+			return
+		}
 		h.ServeHTTP(w, r)
 	})
 }
@@ -0,0 +1,146 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2024 Datadog, Inc.
+
+package fasthttp
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+
+	pappsec "gopkg.in/DataDog/dd-trace-go.v1/appsec"
+	"gopkg.in/DataDog/dd-trace-go.v1/ddtrace/mocktracer"
+	"gopkg.in/DataDog/dd-trace-go.v1/internal/appsec"
+
+	"github.com/stretchr/testify/require"
+	"github.com/valyala/fasthttp"
+)
+
+func TestAppSec(t *testing.T) {
+	appsec.Start()
+	defer appsec.Stop()
+	if !appsec.Enabled() {
+		t.Skip("appsec disabled")
+	}
+
+	h := WrapHandler(func(fctx *fasthttp.RequestCtx) {
+		fctx.SetStatusCode(http.StatusOK)
+		fmt.Fprintf(fctx, "Hello World!\n")
+	})
+
+	t.Run("request-uri", func(t *testing.T) {
+		mt := mocktracer.Start()
+		defer mt.Stop()
+		// Send an LFI attack (according to appsec rule id crs-930-110)
+		var fctx fasthttp.RequestCtx
+		fctx.Request.SetRequestURI("/../../../secret.txt")
+		h(&fctx)
+		require.Equal(t, http.StatusOK, fctx.Response.StatusCode())
+		spans := mt.FinishedSpans()
+		require.Len(t, spans, 1)
+		event, _ := spans[0].Tag("_dd.appsec.json").(string)
+		require.Contains(t, event, "crs-930-110")
+		require.Contains(t, event, "server.request.uri.raw")
+	})
+
+	t.Run("query", func(t *testing.T) {
+		mt := mocktracer.Start()
+		defer mt.Stop()
+		// Send a security scanner attack (according to appsec rule id crs-913-120)
+		var fctx fasthttp.RequestCtx
+		fctx.Request.SetRequestURI("/?attack=appscan_fingerprint")
+		h(&fctx)
+		spans := mt.FinishedSpans()
+		require.Len(t, spans, 1)
+		event, _ := spans[0].Tag("_dd.appsec.json").(string)
+		require.Contains(t, event, "crs-913-120")
+		require.Contains(t, event, "server.request.query")
+	})
+}
+
+// Test that IP, user and body blocking work by using custom rules/rules data
+func TestBlocking(t *testing.T) {
+	t.Setenv("DD_APPSEC_RULES", "../../../internal/appsec/testdata/blocking.json")
+	appsec.Start()
+	defer appsec.Stop()
+	if !appsec.Enabled() {
+		t.Skip("AppSec needs to be enabled for this test")
+	}
+
+	h := WrapHandler(func(fctx *fasthttp.RequestCtx) {
+		if userID := string(fctx.Request.Header.Peek("user-id")); userID != "" {
+			if err := pappsec.SetUser(fctx, userID); err != nil {
+				return
+			}
+		}
+		fctx.SetStatusCode(http.StatusOK)
+		fmt.Fprintf(fctx, "Hello World!\n")
+	})
+
+	for _, tc := range []struct {
+		name        string
+		headers     map[string]string
+		body        string
+		shouldBlock bool
+	}{
+		{
+			name:        "ip/block",
+			headers:     map[string]string{"x-forwarded-for": "1.2.3.4"},
+			shouldBlock: true,
+		},
+		{
+			name:    "ip/no-block",
+			headers: map[string]string{"x-forwarded-for": "1.2.3.5"},
+		},
+		{
+			name:        "user/block",
+			headers:     map[string]string{"user-id": "blocked-user-1"},
+			shouldBlock: true,
+		},
+		{
+			name:    "user/no-block",
+			headers: map[string]string{"user-id": "legit-user-1"},
+		},
+		{
+			name:        "body/block",
+			headers:     map[string]string{"content-type": "application/json"},
+			body:        `{"name":"$globals"}`,
+			shouldBlock: true,
+		},
+		{
+			name:    "body/no-block",
+			headers: map[string]string{"content-type": "application/json"},
+			body:    `{"name":"legit"}`,
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			mt := mocktracer.Start()
+			defer mt.Stop()
+
+			var fctx fasthttp.RequestCtx
+			fctx.Request.Header.SetMethod("POST")
+			fctx.Request.SetRequestURI("/")
+			for k, v := range tc.headers {
+				fctx.Request.Header.Set(k, v)
+			}
+			fctx.Request.SetBodyString(tc.body)
+			h(&fctx)
+
+			spans := mt.FinishedSpans()
+			require.Len(t, spans, 1)
+			if tc.shouldBlock {
+				require.Equal(t, http.StatusForbidden, fctx.Response.StatusCode())
+				require.False(t, strings.Contains(string(fctx.Response.Body()), "Hello World!"))
+				require.Equal(t, true, spans[0].Tag("appsec.blocked"))
+			} else {
+				require.Equal(t, http.StatusOK, fctx.Response.StatusCode())
+				require.Equal(t, "Hello World!\n", string(fctx.Response.Body()))
+				require.NotContains(t, spans[0].Tags(), "appsec.blocked")
+			}
+			require.Equal(t, fmt.Sprintf("%d", fctx.Response.StatusCode()), spans[0].Tag("http.status_code"))
+		})
+	}
+}
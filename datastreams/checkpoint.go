@@ -0,0 +1,58 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package datastreams
+
+import (
+	"context"
+
+	"gopkg.in/DataDog/dd-trace-go.v1/datastreams/options"
+	"gopkg.in/DataDog/dd-trace-go.v1/ddtrace/tracer"
+)
+
+// SetProduceCheckpoint sets a produce checkpoint on the pathway found in ctx and injects
+// the resulting pathway into carrier, so it can be picked up by SetConsumeCheckpoint on the
+// receiving side. The "direction:out" edge tag is added to the given edge tags.
+//
+// It can be used to monitor pipelines that aren't covered by an integration, for example files
+// dropped on S3, HTTP webhooks or batch jobs. Edge tags would then look like "type:s3" and
+// "topic:my-bucket". Only the "type", "topic", "group", "exchange" and "event_type" edge tags
+// identify a pathway.
+//
+// The returned boolean is false if Data Streams Monitoring is not enabled on the tracer.
+func SetProduceCheckpoint(ctx context.Context, carrier TextMapWriter, edgeTags ...string) (context.Context, bool) {
+	return SetProduceCheckpointWithParams(ctx, carrier, options.CheckpointParams{}, edgeTags...)
+}
+
+// SetProduceCheckpointWithParams works like SetProduceCheckpoint, and additionally records
+// the payload size and message schema given in params.
+func SetProduceCheckpointWithParams(ctx context.Context, carrier TextMapWriter, params options.CheckpointParams, edgeTags ...string) (context.Context, bool) {
+	ctx, ok := tracer.SetDataStreamsCheckpointWithParams(ctx, params, append([]string{"direction:out"}, edgeTags...)...)
+	if !ok {
+		return ctx, false
+	}
+	if carrier != nil {
+		InjectToBase64Carrier(ctx, carrier)
+	}
+	return ctx, true
+}
+
+// SetConsumeCheckpoint extracts the pathway propagated in carrier, if any, and sets a consume
+// checkpoint on it. The "direction:in" edge tag is added to the given edge tags. The returned
+// context holds the resulting pathway and should be used for any checkpoint set downstream.
+//
+// The returned boolean is false if Data Streams Monitoring is not enabled on the tracer.
+func SetConsumeCheckpoint(ctx context.Context, carrier TextMapReader, edgeTags ...string) (context.Context, bool) {
+	return SetConsumeCheckpointWithParams(ctx, carrier, options.CheckpointParams{}, edgeTags...)
+}
+
+// SetConsumeCheckpointWithParams works like SetConsumeCheckpoint, and additionally records
+// the payload size and message schema given in params.
+func SetConsumeCheckpointWithParams(ctx context.Context, carrier TextMapReader, params options.CheckpointParams, edgeTags ...string) (context.Context, bool) {
+	if carrier != nil {
+		ctx = ExtractFromBase64Carrier(ctx, carrier)
+	}
+	return tracer.SetDataStreamsCheckpointWithParams(ctx, params, append([]string{"direction:in"}, edgeTags...)...)
+}
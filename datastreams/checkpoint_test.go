@@ -0,0 +1,78 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package datastreams
+
+import (
+	"context"
+	"testing"
+
+	"gopkg.in/DataDog/dd-trace-go.v1/datastreams/options"
+	"gopkg.in/DataDog/dd-trace-go.v1/ddtrace/ext"
+	"gopkg.in/DataDog/dd-trace-go.v1/ddtrace/mocktracer"
+	"gopkg.in/DataDog/dd-trace-go.v1/ddtrace/tracer"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCheckpoints(t *testing.T) {
+	mt := mocktracer.Start()
+	defer mt.Stop()
+
+	c := make(carrier)
+	produceCtx, ok := SetProduceCheckpoint(context.Background(), c, "type:s3", "topic:my-bucket")
+	require.True(t, ok)
+	expectedCtx, _ := tracer.SetDataStreamsCheckpoint(context.Background(), "direction:out", "type:s3", "topic:my-bucket")
+	expected, _ := PathwayFromContext(expectedCtx)
+	produced, ok := PathwayFromContext(produceCtx)
+	require.True(t, ok)
+	assert.NotEqual(t, uint64(0), produced.GetHash())
+	assert.Equal(t, expected.GetHash(), produced.GetHash())
+	injected, ok := PathwayFromContext(ExtractFromBase64Carrier(context.Background(), c))
+	require.True(t, ok)
+	assert.Equal(t, produced.GetHash(), injected.GetHash())
+
+	consumeCtx, ok := SetConsumeCheckpoint(context.Background(), c, "type:s3", "topic:my-bucket")
+	require.True(t, ok)
+	expectedCtx, _ = tracer.SetDataStreamsCheckpoint(expectedCtx, "direction:in", "type:s3", "topic:my-bucket")
+	expected, _ = PathwayFromContext(expectedCtx)
+	consumed, ok := PathwayFromContext(consumeCtx)
+	require.True(t, ok)
+	assert.Equal(t, expected.GetHash(), consumed.GetHash())
+}
+
+func TestCheckpointsDisabled(t *testing.T) {
+	c := make(carrier)
+	_, ok := SetProduceCheckpoint(context.Background(), c, "type:s3", "topic:my-bucket")
+	assert.False(t, ok)
+	assert.Empty(t, c)
+	_, ok = SetConsumeCheckpoint(context.Background(), c, "type:s3", "topic:my-bucket")
+	assert.False(t, ok)
+}
+
+func TestCheckpointSchema(t *testing.T) {
+	mt := mocktracer.Start()
+	defer mt.Stop()
+
+	schema := &options.Schema{ID: "42", Type: "avro", Name: "User", Definition: `{"type":"record","name":"User"}`}
+	for i := 0; i < 2; i++ {
+		span, ctx := tracer.StartSpanFromContext(context.Background(), "produce")
+		_, ok := SetProduceCheckpointWithParams(ctx, make(carrier), options.CheckpointParams{Schema: schema}, "type:kafka", "topic:users")
+		assert.True(t, ok)
+		span.Finish()
+	}
+
+	spans := mt.FinishedSpans()
+	require.Len(t, spans, 2)
+	assert.Equal(t, "42", spans[0].Tag(ext.SchemaID))
+	assert.Equal(t, "avro", spans[0].Tag(ext.SchemaType))
+	assert.Equal(t, "User", spans[0].Tag(ext.SchemaName))
+	assert.Equal(t, schema.Definition, spans[0].Tag(ext.SchemaDefinition))
+	assert.Equal(t, int64(1), spans[0].Tag(ext.SchemaWeight))
+	assert.Equal(t, "serialization", spans[0].Tag(ext.SchemaOperation))
+	// the second schema falls in the same sampling interval
+	assert.Nil(t, spans[1].Tag(ext.SchemaID))
+}
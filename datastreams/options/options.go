@@ -7,4 +7,21 @@ package options
 
 type CheckpointParams struct {
 	PayloadSize int64
+	// Schema, when set, describes the schema used to serialize or deserialize
+	// the message at this checkpoint. Schemas are sampled, and sampled schemas
+	// are reported on the span found in the checkpoint's context.
+	Schema *Schema
+}
+
+// Schema describes a message schema, as stored in a schema registry.
+type Schema struct {
+	// ID is the schema identifier, such as the schema registry ID.
+	ID string
+	// Type is the schema format, such as "avro" or "protobuf".
+	Type string
+	// Name is the fully qualified name of the message type.
+	Name string
+	// Definition is the schema definition: the Avro JSON schema or the
+	// serialized Protobuf file descriptor set.
+	Definition string
 }
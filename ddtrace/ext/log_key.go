@@ -0,0 +1,25 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2024 Datadog, Inc.
+
+package ext
+
+// Keys of the log attributes correlating logs with traces, see
+// https://docs.datadoghq.com/tracing/other_telemetry/connect_logs_and_traces/
+const (
+	// LogKeyTraceID is the ID of the trace a log was written in.
+	LogKeyTraceID = "dd.trace_id"
+
+	// LogKeySpanID is the ID of the span a log was written in.
+	LogKeySpanID = "dd.span_id"
+
+	// LogKeyService is the service of the application writing the log.
+	LogKeyService = "dd.service"
+
+	// LogKeyEnv is the environment of the application writing the log.
+	LogKeyEnv = "dd.env"
+
+	// LogKeyVersion is the version of the application writing the log.
+	LogKeyVersion = "dd.version"
+)
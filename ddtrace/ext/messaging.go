@@ -23,3 +23,19 @@ const (
 	// KafkaBootstrapServers holds a comma separated list of bootstrap servers as defined in producer or consumer config.
 	KafkaBootstrapServers = "messaging.kafka.bootstrap.servers"
 )
+
+// Data Streams schema tags.
+const (
+	// SchemaID holds the schema registry identifier of a message schema.
+	SchemaID = "schema.id"
+	// SchemaType holds the format of a message schema (avro, protobuf...).
+	SchemaType = "schema.type"
+	// SchemaName holds the fully qualified name of a message type.
+	SchemaName = "schema.name"
+	// SchemaDefinition holds the definition of a message schema.
+	SchemaDefinition = "schema.definition"
+	// SchemaWeight holds the number of messages represented by a sampled schema.
+	SchemaWeight = "schema.weight"
+	// SchemaOperation is either "serialization" or "deserialization".
+	SchemaOperation = "schema.operation"
+)
@@ -0,0 +1,105 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2024 Datadog, Inc.
+
+package tracer
+
+import (
+	"github.com/tinylib/msgp/msgp"
+
+	"gopkg.in/DataDog/dd-trace-go.v1/internal/civisibility/coverage"
+)
+
+// ciTestCoveragePayloadVersion is the version of the test coverage payload format.
+const ciTestCoveragePayloadVersion = 2
+
+// Ensure that ciTestCoveragePayload implements the msgp.Encodable interface.
+var _ msgp.Encodable = (*ciTestCoveragePayload)(nil)
+
+// ciTestCoveragePayload represents the payload of the test coverage intake, encoded as:
+//
+//	{
+//	  "version": 2,
+//	  "coverages": [{
+//	    "test_session_id": uint64, "test_suite_id": uint64, "span_id": uint64,
+//	    "files": [{"filename": string, "bitmap": bytes}]
+//	  }]
+//	}
+type ciTestCoveragePayload struct {
+	Coverages []*coverage.TestCoverage
+}
+
+// EncodeMsg implements msgp.Encodable.
+func (p *ciTestCoveragePayload) EncodeMsg(en *msgp.Writer) (err error) {
+	if err = en.WriteMapHeader(2); err != nil {
+		return
+	}
+	if err = en.WriteString("version"); err != nil {
+		return
+	}
+	if err = en.WriteInt32(ciTestCoveragePayloadVersion); err != nil {
+		return
+	}
+	if err = en.WriteString("coverages"); err != nil {
+		return
+	}
+	if err = en.WriteArrayHeader(uint32(len(p.Coverages))); err != nil {
+		return
+	}
+	for _, c := range p.Coverages {
+		if err = encodeTestCoverage(en, c); err != nil {
+			return
+		}
+	}
+	return
+}
+
+// encodeTestCoverage encodes the coverage of a test.
+func encodeTestCoverage(en *msgp.Writer, c *coverage.TestCoverage) (err error) {
+	if err = en.WriteMapHeader(4); err != nil {
+		return
+	}
+	if err = en.WriteString("test_session_id"); err != nil {
+		return
+	}
+	if err = en.WriteUint64(c.SessionID); err != nil {
+		return
+	}
+	if err = en.WriteString("test_suite_id"); err != nil {
+		return
+	}
+	if err = en.WriteUint64(c.SuiteID); err != nil {
+		return
+	}
+	if err = en.WriteString("span_id"); err != nil {
+		return
+	}
+	if err = en.WriteUint64(c.SpanID); err != nil {
+		return
+	}
+	if err = en.WriteString("files"); err != nil {
+		return
+	}
+	if err = en.WriteArrayHeader(uint32(len(c.Files))); err != nil {
+		return
+	}
+	for _, f := range c.Files {
+		if err = en.WriteMapHeader(2); err != nil {
+			return
+		}
+		if err = en.WriteString("filename"); err != nil {
+			return
+		}
+		if err = en.WriteString(f.Filename); err != nil {
+			return
+		}
+		if err = en.WriteString("bitmap"); err != nil {
+			return
+		}
+		if err = en.WriteBytes(f.Bitmap); err != nil {
+			return
+		}
+	}
+	return
+}
@@ -0,0 +1,175 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2024 Datadog, Inc.
+
+package tracer
+
+import (
+	"bytes"
+	"fmt"
+	"mime/multipart"
+	"net/http"
+	"net/textproto"
+	"sync"
+	"time"
+
+	"github.com/tinylib/msgp/msgp"
+
+	"gopkg.in/DataDog/dd-trace-go.v1/internal/civisibility/coverage"
+	"gopkg.in/DataDog/dd-trace-go.v1/internal/log"
+	"gopkg.in/DataDog/dd-trace-go.v1/internal/version"
+)
+
+// coverageFlushThreshold is the number of test coverages buffered before triggering a flush.
+const coverageFlushThreshold = 100
+
+// Ensure that ciVisibilityCoverageWriter implements the coverage.Writer interface.
+var _ coverage.Writer = (*ciVisibilityCoverageWriter)(nil)
+
+// ciVisibilityCoverageWriter is responsible for buffering and sending the per-test code coverage
+// to the test coverage intake, either in agentless mode or through the EVP proxy.
+type ciVisibilityCoverageWriter struct {
+	config    *config                  // Configuration for the tracer.
+	url       string                   // URL of the test coverage intake.
+	headers   map[string]string        // HTTP headers to be included in the requests.
+	mu        sync.Mutex               // Guards coverages.
+	coverages []*coverage.TestCoverage // Buffered test coverages.
+	climit    chan struct{}            // Limits the number of concurrent outgoing connections.
+	wg        sync.WaitGroup           // Waits for all uploads to finish.
+}
+
+// newCiVisibilityCoverageWriter creates a new instance of ciVisibilityCoverageWriter.
+//
+// Parameters:
+//
+//	c - The tracer configuration.
+//
+// Returns:
+//
+//	A pointer to an initialized ciVisibilityCoverageWriter.
+func newCiVisibilityCoverageWriter(c *config) *ciVisibilityCoverageWriter {
+	headers := map[string]string{
+		"Datadog-Meta-Lang":           "go",
+		"Datadog-Meta-Tracer-Version": version.Tag,
+	}
+	return &ciVisibilityCoverageWriter{
+		config:  c,
+		url:     getCiVisibilityIntakeURL(c, TestCoverageSubdomain, TestCoveragePath, headers),
+		headers: headers,
+		climit:  make(chan struct{}, concurrentConnectionLimit),
+	}
+}
+
+// Write adds the coverage of a test to the buffer, flushing it when it's full.
+//
+// Parameters:
+//
+//	c - The coverage of the test.
+func (w *ciVisibilityCoverageWriter) Write(c *coverage.TestCoverage) {
+	w.mu.Lock()
+	w.coverages = append(w.coverages, c)
+	full := len(w.coverages) >= coverageFlushThreshold
+	w.mu.Unlock()
+	if full {
+		w.flush()
+	}
+}
+
+// stop stops the coverage writer, ensuring all data is flushed and all uploads are completed.
+func (w *ciVisibilityCoverageWriter) stop() {
+	w.flush()
+	w.wg.Wait()
+}
+
+// flush sends the buffered test coverages to the intake.
+func (w *ciVisibilityCoverageWriter) flush() {
+	w.mu.Lock()
+	coverages := w.coverages
+	w.coverages = nil
+	w.mu.Unlock()
+	if len(coverages) == 0 {
+		return
+	}
+
+	w.wg.Add(1)
+	w.climit <- struct{}{}
+	go func() {
+		defer func() {
+			<-w.climit
+			w.wg.Done()
+		}()
+
+		var err error
+		for attempt := 0; attempt <= w.config.sendRetries; attempt++ {
+			log.Debug("Sending coverage payload: coverages: %d\n", len(coverages))
+			if err = w.send(coverages); err == nil {
+				log.Debug("sent coverages after %d attempts", attempt+1)
+				return
+			}
+			log.Error("failure sending coverages (attempt %d), will retry: %v", attempt+1, err)
+			time.Sleep(time.Millisecond)
+		}
+		log.Error("lost %d coverages: %v", len(coverages), err)
+	}()
+}
+
+// send sends the given test coverages to the intake, as the msgpack file of a multipart form.
+//
+// Parameters:
+//
+//	coverages - The test coverages to be sent.
+//
+// Returns:
+//
+//	An error if the operation fails.
+func (w *ciVisibilityCoverageWriter) send(coverages []*coverage.TestCoverage) error {
+	var msgpBuffer bytes.Buffer
+	if err := msgp.Encode(&msgpBuffer, &ciTestCoveragePayload{Coverages: coverages}); err != nil {
+		return fmt.Errorf("cannot encode coverage payload: %v", err)
+	}
+
+	var body bytes.Buffer
+	form := multipart.NewWriter(&body)
+	if err := writeFormFile(form, "coverage1", "filecoverage1.msgpack", "application/msgpack", msgpBuffer.Bytes()); err != nil {
+		return err
+	}
+	// The intake requires an event part along with the coverage.
+	if err := writeFormFile(form, "event", "fileevent.json", "application/json", []byte(`{"dummy": true}`)); err != nil {
+		return err
+	}
+	if err := form.Close(); err != nil {
+		return fmt.Errorf("cannot create coverage form: %v", err)
+	}
+
+	req, err := http.NewRequest("POST", w.url, &body)
+	if err != nil {
+		return fmt.Errorf("cannot create http request: %v", err)
+	}
+	for header, value := range w.headers {
+		req.Header.Set(header, value)
+	}
+	req.Header.Set("Content-Type", form.FormDataContentType())
+	response, err := w.config.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+	if code := response.StatusCode; code >= 400 {
+		return fmt.Errorf("%s", http.StatusText(code))
+	}
+	return nil
+}
+
+// writeFormFile adds a file part with the given content type to a multipart form.
+func writeFormFile(form *multipart.Writer, name, filename, contentType string, content []byte) error {
+	h := make(textproto.MIMEHeader)
+	h.Set("Content-Disposition", fmt.Sprintf(`form-data; name="%s"; filename="%s"`, name, filename))
+	h.Set("Content-Type", contentType)
+	part, err := form.CreatePart(h)
+	if err != nil {
+		return fmt.Errorf("cannot create coverage form: %v", err)
+	}
+	_, err = part.Write(content)
+	return err
+}
@@ -0,0 +1,89 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2024 Datadog, Inc.
+
+package tracer
+
+import (
+	"bytes"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tinylib/msgp/msgp"
+
+	"gopkg.in/DataDog/dd-trace-go.v1/internal/civisibility/coverage"
+)
+
+func TestCiVisibilityCoverageWriter(t *testing.T) {
+	var (
+		mu    sync.Mutex
+		parts = map[string][]byte{}
+		types = map[string]string{}
+	)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		assert.Equal(t, "/"+TestCoveragePath, r.URL.Path)
+		assert.Equal(t, "api-key", r.Header.Get("dd-api-key"))
+		_, params, err := mime.ParseMediaType(r.Header.Get("Content-Type"))
+		require.NoError(t, err)
+		reader := multipart.NewReader(r.Body, params["boundary"])
+		for {
+			part, err := reader.NextPart()
+			if err == io.EOF {
+				break
+			}
+			require.NoError(t, err)
+			content, err := io.ReadAll(part)
+			require.NoError(t, err)
+			parts[part.FormName()] = content
+			types[part.FormName()] = part.Header.Get("Content-Type")
+		}
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	defer server.Close()
+	t.Setenv("DD_CIVISIBILITY_AGENTLESS_ENABLED", "true")
+	t.Setenv("DD_CIVISIBILITY_AGENTLESS_URL", server.URL)
+	t.Setenv("DD_API_KEY", "api-key")
+
+	w := newCiVisibilityCoverageWriter(newConfig())
+	bitmap := coverage.Bitmap{}
+	bitmap.Set(3)
+	bitmap.Set(10)
+	w.Write(&coverage.TestCoverage{
+		SessionID: 1,
+		SuiteID:   2,
+		SpanID:    3,
+		Files:     []*coverage.FileCoverage{{Filename: "pkg/file.go", Bitmap: bitmap}},
+	})
+	w.stop()
+
+	mu.Lock()
+	defer mu.Unlock()
+	assert.Equal(t, "application/msgpack", types["coverage1"])
+	assert.Equal(t, "application/json", types["event"])
+
+	decoded, err := msgp.NewReader(bytes.NewReader(parts["coverage1"])).ReadIntf()
+	require.NoError(t, err)
+	payload := decoded.(map[string]any)
+	assert.EqualValues(t, 2, payload["version"])
+	coverages := payload["coverages"].([]any)
+	require.Len(t, coverages, 1)
+	testCoverage := coverages[0].(map[string]any)
+	assert.EqualValues(t, 1, testCoverage["test_session_id"])
+	assert.EqualValues(t, 2, testCoverage["test_suite_id"])
+	assert.EqualValues(t, 3, testCoverage["span_id"])
+	files := testCoverage["files"].([]any)
+	require.Len(t, files, 1)
+	file := files[0].(map[string]any)
+	assert.Equal(t, "pkg/file.go", file["filename"])
+	assert.Equal(t, []byte{0b00010000, 0b00100000}, file["bitmap"])
+}
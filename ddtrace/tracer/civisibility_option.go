@@ -9,6 +9,7 @@ import (
 	"fmt"
 	"gopkg.in/DataDog/dd-trace-go.v1/ddtrace/ext"
 	"gopkg.in/DataDog/dd-trace-go.v1/internal"
+	civisibilitynet "gopkg.in/DataDog/dd-trace-go.v1/internal/civisibility/utils/net"
 	"gopkg.in/DataDog/dd-trace-go.v1/internal/globalconfig"
 	"gopkg.in/DataDog/dd-trace-go.v1/internal/log"
 	"gopkg.in/DataDog/dd-trace-go.v1/internal/namingschema"
@@ -174,7 +175,10 @@ func newCiVisibilityConfig(opts ...StartOption) *config {
 		}
 	}
 
-	c.transport = newCiVisibilityTransport(c)
+	transport := newCiVisibilityTransport(c)
+	// The CI Visibility backend API client reaches the backend like the transport.
+	civisibilitynet.SetIntake(transport)
+	c.transport = transport
 
 	if c.propagator == nil {
 		envKey := "DD_TRACE_X_DATADOG_TAGS_MAX_LENGTH"
@@ -15,6 +15,7 @@ import (
 	"runtime"
 	"strconv"
 	"strings"
+	"sync"
 
 	"gopkg.in/DataDog/dd-trace-go.v1/internal"
 	"gopkg.in/DataDog/dd-trace-go.v1/internal/civisibility/constants"
@@ -23,9 +24,11 @@ import (
 
 // Constants for CI Visibility API paths and subdomains.
 const (
-	TestCycleSubdomain = "citestcycle-intake" // Subdomain for test cycle intake.
-	TestCyclePath      = "api/v2/citestcycle" // API path for test cycle.
-	EvpProxyPath       = "evp_proxy/v2"       // Path for EVP proxy.
+	TestCycleSubdomain    = "citestcycle-intake" // Subdomain for test cycle intake.
+	TestCyclePath         = "api/v2/citestcycle" // API path for test cycle.
+	TestCoverageSubdomain = "citestcov-intake"   // Subdomain for test coverage intake.
+	TestCoveragePath      = "api/v2/citestcov"   // API path for test coverage.
+	EvpProxyPath          = "evp_proxy/v2"       // Path for EVP proxy.
 )
 
 // Ensure that civisibilityTransport implements the transport interface.
@@ -38,6 +41,8 @@ type civisibilityTransport struct {
 	testCycleUrlPath string            // URL path for the test cycle endpoint.
 	client           *http.Client      // HTTP client used to send the requests.
 	headers          map[string]string // HTTP headers to be included in the requests.
+	payloadFile      string            // File the payloads are written to instead of being sent, if any.
+	payloadFileMutex sync.Mutex        // Mutex serializing the writes to the payload file.
 }
 
 // newCiVisibilityTransport creates and initializes a new civisibilityTransport
@@ -67,44 +72,62 @@ func newCiVisibilityTransport(config *config) *civisibilityTransport {
 		defaultHeaders["Datadog-Entity-ID"] = eid
 	}
 
-	// Determine if agentless mode is enabled through an environment variable.
-	agentlessEnabled := internal.BoolEnv(constants.CiVisibilityAgentlessEnabledEnvironmentVariable, false)
+	return &civisibilityTransport{
+		config:           config,
+		testCycleUrlPath: getCiVisibilityIntakeURL(config, TestCycleSubdomain, TestCyclePath, defaultHeaders),
+		client:           config.httpClient,
+		headers:          defaultHeaders,
+		payloadFile:      os.Getenv(constants.CiVisibilityPayloadFileEnvironmentVariable),
+	}
+}
 
-	testCycleUrl := ""
-	if agentlessEnabled {
-		// Agentless mode is enabled.
-		defaultHeaders["dd-api-key"] = os.Getenv(constants.ApiKeyEnvironmentVariable)
+// IntakeURL returns the URL of the given path of the CI Visibility intake of the given subdomain, and
+// adds the headers required to reach it to the given headers.
+func (t *civisibilityTransport) IntakeURL(subdomain, path string, headers map[string]string) string {
+	return getCiVisibilityIntakeURL(t.config, subdomain, path, headers)
+}
 
-		// Check for a custom agentless URL.
-		agentlessUrl := ""
-		if v := os.Getenv(constants.CiVisibilityAgentlessUrlEnvironmentVariable); v != "" {
-			agentlessUrl = v
-		}
+// HTTPClient returns the HTTP client reaching the CI Visibility intakes.
+func (t *civisibilityTransport) HTTPClient() *http.Client {
+	return t.client
+}
 
-		if agentlessUrl == "" {
-			// Use the standard agentless URL format.
-			site := "datadoghq.com"
-			if v := os.Getenv("DD_SITE"); v != "" {
-				site = v
-			}
-
-			testCycleUrl = fmt.Sprintf("https://%s.%s/%s", TestCycleSubdomain, site, TestCyclePath)
-		} else {
-			// Use the custom agentless URL.
-			testCycleUrl = fmt.Sprintf("%s/%s", agentlessUrl, TestCyclePath)
-		}
-	} else {
+// getCiVisibilityIntakeURL returns the URL of a CI Visibility intake, either agentless or through
+// the EVP proxy of the agent, and adds the headers required to reach it to the given headers.
+//
+// Parameters:
+//
+//	config - The tracer configuration.
+//	subdomain - The subdomain of the intake.
+//	path - The API path of the intake.
+//	headers - The HTTP headers of the requests to the intake.
+//
+// Returns:
+//
+//	The URL of the intake.
+func getCiVisibilityIntakeURL(config *config, subdomain, path string, headers map[string]string) string {
+	// Determine if agentless mode is enabled through an environment variable.
+	if !internal.BoolEnv(constants.CiVisibilityAgentlessEnabledEnvironmentVariable, false) {
 		// Use agent mode with the EVP proxy.
-		defaultHeaders["X-Datadog-EVP-Subdomain"] = TestCycleSubdomain
-		testCycleUrl = fmt.Sprintf("%s/%s/%s", config.agentURL.String(), EvpProxyPath, TestCyclePath)
+		headers["X-Datadog-EVP-Subdomain"] = subdomain
+		return fmt.Sprintf("%s/%s/%s", config.agentURL.String(), EvpProxyPath, path)
 	}
 
-	return &civisibilityTransport{
-		config:           config,
-		testCycleUrlPath: testCycleUrl,
-		client:           config.httpClient,
-		headers:          defaultHeaders,
+	// Agentless mode is enabled.
+	headers["dd-api-key"] = os.Getenv(constants.ApiKeyEnvironmentVariable)
+
+	// Check for a custom agentless URL.
+	if agentlessUrl := os.Getenv(constants.CiVisibilityAgentlessUrlEnvironmentVariable); agentlessUrl != "" {
+		// Use the custom agentless URL.
+		return fmt.Sprintf("%s/%s", agentlessUrl, path)
 	}
+
+	// Use the standard agentless URL format.
+	site := "datadoghq.com"
+	if v := os.Getenv("DD_SITE"); v != "" {
+		site = v
+	}
+	return fmt.Sprintf("https://%s.%s/%s", subdomain, site, path)
 }
 
 // send sends the CI Visibility payload to the Datadog endpoint.
@@ -123,6 +146,9 @@ func (t *civisibilityTransport) send(p *payload) (body io.ReadCloser, err error)
 	if bufferErr != nil {
 		return nil, fmt.Errorf("cannot create buffer payload: %v", bufferErr)
 	}
+	if t.payloadFile != "" {
+		return io.NopCloser(bytes.NewReader(nil)), t.writePayloadFile(buffer)
+	}
 
 	// Compress payload
 	var gzipBuffer bytes.Buffer
@@ -164,6 +190,30 @@ func (t *civisibilityTransport) send(p *payload) (body io.ReadCloser, err error)
 	return response.Body, nil
 }
 
+// writePayloadFile appends the encoded CI Visibility payload to the payload file, the payloads
+// being written one after another without compression.
+//
+// Parameters:
+//
+//	buffer - The encoded payload.
+//
+// Returns:
+//
+//	An error if the payload couldn't be written.
+func (t *civisibilityTransport) writePayloadFile(buffer *bytes.Buffer) error {
+	t.payloadFileMutex.Lock()
+	defer t.payloadFileMutex.Unlock()
+	file, err := os.OpenFile(t.payloadFile, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return fmt.Errorf("cannot open payload file: %v", err)
+	}
+	if _, err = buffer.WriteTo(file); err != nil {
+		_ = file.Close()
+		return fmt.Errorf("cannot write payload file: %v", err)
+	}
+	return file.Close()
+}
+
 // sendStats is a no-op for CI Visibility transport as it does not support sending stats payloads.
 //
 // Parameters:
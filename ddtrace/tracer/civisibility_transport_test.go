@@ -10,12 +10,16 @@ import (
 	"compress/gzip"
 	"net/http"
 	"net/http/httptest"
+	"os"
+	"path/filepath"
 	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 	"github.com/tinylib/msgp/msgp"
 	"gopkg.in/DataDog/dd-trace-go.v1/internal/civisibility/constants"
+	civisibilitynet "gopkg.in/DataDog/dd-trace-go.v1/internal/civisibility/utils/net"
 )
 
 func TestCiVisibilityTransport(t *testing.T) {
@@ -94,3 +98,68 @@ func TestCiVisibilityTransport(t *testing.T) {
 	assert.Equal(hits, len(testCases))
 	assert.Equal(remainingEvents, 0)
 }
+
+func TestCiVisibilityTransportPayloadFile(t *testing.T) {
+	assert := assert.New(t)
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Error("unexpected request with a payload file")
+	}))
+	defer srv.Close()
+	c := config{
+		ciVisibilityEnabled: true,
+		httpClient:          defaultHTTPClient(0),
+	}
+
+	payloadFile := filepath.Join(t.TempDir(), "payload.msgpack")
+	t.Setenv(constants.CiVisibilityAgentlessEnabledEnvironmentVariable, "1")
+	t.Setenv(constants.CiVisibilityAgentlessUrlEnvironmentVariable, srv.URL)
+	t.Setenv(constants.CiVisibilityPayloadFileEnvironmentVariable, payloadFile)
+
+	transport := newCiVisibilityTransport(&c)
+	for _, trace := range [][][]*span{getTestTrace(1, 1), getTestTrace(10, 1)} {
+		p := newCiVisibilityPayload()
+		for _, t := range trace {
+			for _, span := range t {
+				assert.NoError(p.push(getCiVisibilityEvent(span)))
+			}
+		}
+		_, err := transport.send(p.payload)
+		assert.NoError(err)
+	}
+
+	// The payloads are written one after another.
+	content, err := os.ReadFile(payloadFile)
+	assert.NoError(err)
+	reader := msgp.NewReader(bytes.NewReader(content))
+	var total int
+	for i := 0; i < 2; i++ {
+		var testCyclePayload ciTestCyclePayload
+		assert.NoError(testCyclePayload.DecodeMsg(reader))
+
+		var events ciVisibilityEvents
+		assert.NoError(msgp.Decode(bytes.NewBuffer(testCyclePayload.Events), &events))
+		total += len(events)
+	}
+	assert.Equal(11, total)
+}
+
+func TestCiVisibilityIntake(t *testing.T) {
+	var paths, subdomains []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		paths = append(paths, r.URL.Path)
+		subdomains = append(subdomains, r.Header.Get("X-Datadog-EVP-Subdomain"))
+		w.Write([]byte(`{"data":{"attributes":{"itr_enabled":true}}}`))
+	}))
+	defer srv.Close()
+	t.Setenv(constants.CiVisibilityAgentlessEnabledEnvironmentVariable, "false")
+	t.Cleanup(func() { civisibilitynet.SetIntake(nil) })
+
+	// The CI Visibility backend API client reaches the agent set in the options.
+	newCiVisibilityConfig(WithAgentAddr(strings.TrimPrefix(srv.URL, "http://")))
+	settings, err := civisibilitynet.NewClient("my-service").GetSettings()
+	require.NoError(t, err)
+	assert.True(t, settings.ItrEnabled)
+	assert.Equal(t, []string{"/" + EvpProxyPath + "/api/v2/libraries/tests/services/setting"}, paths)
+	assert.Equal(t, []string{"api"}, subdomains)
+}
@@ -161,6 +161,16 @@ func getCiVisibilityEvent(span *span) *ciVisibilityEvent {
 	}
 }
 
+// isCiVisibilityEventType returns true when spanType is the type of a test, suite, module or session span.
+func isCiVisibilityEventType(spanType interface{}) bool {
+	switch spanType {
+	case constants.SpanTypeTest, constants.SpanTypeTestSuite, constants.SpanTypeTestModule, constants.SpanTypeTestSession:
+		return true
+	default:
+		return false
+	}
+}
+
 // createTestEventFromSpan creates a ciVisibilityEvent of type Test from a span.
 //
 // Parameters:
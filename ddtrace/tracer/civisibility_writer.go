@@ -9,6 +9,7 @@ import (
 	"sync"
 	"time"
 
+	"gopkg.in/DataDog/dd-trace-go.v1/internal/civisibility/coverage"
 	"gopkg.in/DataDog/dd-trace-go.v1/internal/log"
 )
 
@@ -33,6 +34,8 @@ type ciVisibilityTraceWriter struct {
 	payload *civisibilitypayload // Encodes and buffers traces in msgpack format.
 	climit  chan struct{}        // Limits the number of concurrent outgoing connections.
 	wg      sync.WaitGroup       // Waits for all uploads to finish.
+
+	coverageWriter *ciVisibilityCoverageWriter // Sends the per-test code coverage.
 }
 
 // newCiVisibilityTraceWriter creates a new instance of ciVisibilityTraceWriter.
@@ -45,10 +48,13 @@ type ciVisibilityTraceWriter struct {
 //
 //	A pointer to an initialized ciVisibilityTraceWriter.
 func newCiVisibilityTraceWriter(c *config) *ciVisibilityTraceWriter {
+	coverageWriter := newCiVisibilityCoverageWriter(c)
+	coverage.SetWriter(coverageWriter)
 	return &ciVisibilityTraceWriter{
-		config:  c,
-		payload: newCiVisibilityPayload(),
-		climit:  make(chan struct{}, concurrentConnectionLimit),
+		config:         c,
+		payload:        newCiVisibilityPayload(),
+		climit:         make(chan struct{}, concurrentConnectionLimit),
+		coverageWriter: coverageWriter,
 	}
 }
 
@@ -72,6 +78,8 @@ func (w *ciVisibilityTraceWriter) add(trace []*span) {
 
 // stop stops the trace writer, ensuring all data is flushed and all uploads are completed.
 func (w *ciVisibilityTraceWriter) stop() {
+	coverage.ClearWriter(w.coverageWriter)
+	w.coverageWriter.stop()
 	w.flush()
 	w.wg.Wait()
 }
@@ -7,8 +7,10 @@ package tracer
 
 import (
 	"context"
+	"strings"
 
 	"gopkg.in/DataDog/dd-trace-go.v1/datastreams/options"
+	"gopkg.in/DataDog/dd-trace-go.v1/ddtrace/ext"
 	"gopkg.in/DataDog/dd-trace-go.v1/ddtrace/internal"
 	idatastreams "gopkg.in/DataDog/dd-trace-go.v1/internal/datastreams"
 )
@@ -32,17 +34,50 @@ func SetDataStreamsCheckpoint(ctx context.Context, edgeTags ...string) (outCtx c
 
 // SetDataStreamsCheckpointWithParams sets a consume or produce checkpoint in a Data Streams pathway.
 // This enables tracking data flow & end to end latency.
+// If params holds a schema and the schema is sampled, it is reported on the span found in ctx.
 // To learn more about the data streams product, see: https://docs.datadoghq.com/data_streams/go/
 func SetDataStreamsCheckpointWithParams(ctx context.Context, params options.CheckpointParams, edgeTags ...string) (outCtx context.Context, ok bool) {
 	if t, ok := internal.GetGlobalTracer().(dataStreamsContainer); ok {
 		if processor := t.GetDataStreamsProcessor(); processor != nil {
 			outCtx = processor.SetCheckpointWithParams(ctx, params, edgeTags...)
+			if params.Schema != nil {
+				setDataStreamsSchema(ctx, processor, params.Schema, edgeTags)
+			}
 			return outCtx, true
 		}
 	}
 	return ctx, false
 }
 
+// setDataStreamsSchema tags the span found in ctx with the given schema, if the
+// schema is sampled for the checkpoint's topic and direction.
+func setDataStreamsSchema(ctx context.Context, processor *idatastreams.Processor, schema *options.Schema, edgeTags []string) {
+	span, ok := SpanFromContext(ctx)
+	if !ok {
+		return
+	}
+	var topic string
+	operation := idatastreams.SchemaOperationDeserialization
+	for _, tag := range edgeTags {
+		switch {
+		case tag == "direction:out":
+			operation = idatastreams.SchemaOperationSerialization
+		case strings.HasPrefix(tag, "topic:"):
+			topic = strings.TrimPrefix(tag, "topic:")
+		}
+	}
+	weight, ok := processor.TrySampleSchema(topic, operation, schema.ID)
+	if !ok {
+		return
+	}
+	span.SetTag(ext.SchemaID, schema.ID)
+	span.SetTag(ext.SchemaType, schema.Type)
+	span.SetTag(ext.SchemaName, schema.Name)
+	span.SetTag(ext.SchemaDefinition, schema.Definition)
+	span.SetTag(ext.SchemaWeight, weight)
+	span.SetTag(ext.SchemaOperation, operation)
+}
+
 // TrackKafkaCommitOffset should be used in the consumer, to track when it acks offset.
 // if used together with TrackKafkaProduceOffset it can generate a Kafka lag in seconds metric.
 func TrackKafkaCommitOffset(group, topic string, partition int32, offset int64) {
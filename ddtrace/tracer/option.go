@@ -25,6 +25,8 @@ import (
 	"gopkg.in/DataDog/dd-trace-go.v1/ddtrace"
 	"gopkg.in/DataDog/dd-trace-go.v1/ddtrace/ext"
 	"gopkg.in/DataDog/dd-trace-go.v1/internal"
+	appsecConfig "gopkg.in/DataDog/dd-trace-go.v1/internal/appsec/config"
+	"gopkg.in/DataDog/dd-trace-go.v1/internal/appsec/eventsink"
 	"gopkg.in/DataDog/dd-trace-go.v1/internal/civisibility/constants"
 	"gopkg.in/DataDog/dd-trace-go.v1/internal/globalconfig"
 	"gopkg.in/DataDog/dd-trace-go.v1/internal/log"
@@ -62,6 +64,7 @@ var contribIntegrations = map[string]struct {
 	"github.com/go-redis/redis/v7":                  {"Redis v7", false},
 	"github.com/go-redis/redis/v8":                  {"Redis v8", false},
 	"go.mongodb.org/mongo-driver":                   {"MongoDB", false},
+	"go.uber.org/zap":                               {"zap", false},
 	"github.com/gocql/gocql":                        {"Cassandra", false},
 	"github.com/gofiber/fiber/v2":                   {"Fiber", false},
 	"github.com/gomodule/redigo":                    {"Redigo", false},
@@ -80,15 +83,20 @@ var contribIntegrations = map[string]struct {
 	"k8s.io/client-go/kubernetes":                   {"Kubernetes", false},
 	"github.com/labstack/echo":                      {"echo", false},
 	"github.com/labstack/echo/v4":                   {"echo v4", false},
+	"log/slog":                                      {"log/slog", false},
 	"github.com/miekg/dns":                          {"miekg/dns", false},
+	"github.com/onsi/ginkgo/v2":                     {"Ginkgo", false},
 	"net/http":                                      {"HTTP", false},
+	"os":                                            {"os", false},
 	"gopkg.in/olivere/elastic.v5":                   {"Elasticsearch v5", false},
 	"gopkg.in/olivere/elastic.v3":                   {"Elasticsearch v3", false},
 	"github.com/redis/go-redis/v9":                  {"Redis v9", false},
+	"github.com/rs/zerolog":                         {"zerolog", false},
 	"github.com/segmentio/kafka-go":                 {"Kafka v0", false},
 	"github.com/IBM/sarama":                         {"IBM sarama", false},
 	"github.com/Shopify/sarama":                     {"Shopify sarama", false},
 	"github.com/sirupsen/logrus":                    {"Logrus", false},
+	"github.com/stretchr/testify":                   {"Testify", false},
 	"github.com/syndtr/goleveldb":                   {"LevelDB", false},
 	"github.com/tidwall/buntdb":                     {"BuntDB", false},
 	"github.com/twitchtv/twirp":                     {"Twirp", false},
@@ -274,6 +282,9 @@ type config struct {
 
 	// ciVisibilityEnabled controls if the tracer is loaded with CI Visibility mode. default false
 	ciVisibilityEnabled bool
+
+	// appsecStartOptions holds the options AppSec is started with.
+	appsecStartOptions []appsecConfig.StartOption
 }
 
 // orchestrionConfig contains Orchestrion configuration.
@@ -518,6 +529,8 @@ func newConfig(opts ...StartOption) *config {
 	// Re-initialize the globalTags config with the value constructed from the environment and start options
 	// This allows persisting the initial value of globalTags for future resets and updates.
 	c.initGlobalTags(c.globalTags.get())
+	globalconfig.SetEnv(c.env)
+	globalconfig.SetVersion(c.version)
 
 	return c
 }
@@ -981,6 +994,15 @@ func WithAnalyticsRate(rate float64) StartOption {
 	}
 }
 
+// WithAppSecEventSink sets the event sink the security events detected by AppSec are exported to, instead of
+// the one configured with DD_APPSEC_EVENT_SINK. The sink, usually implemented by the application as an
+// appsec.EventSink, is not closed when the tracer stops.
+func WithAppSecEventSink(sink eventsink.Sink) StartOption {
+	return func(c *config) {
+		c.appsecStartOptions = append(c.appsecStartOptions, appsecConfig.WithEventSink(sink))
+	}
+}
+
 // WithRuntimeMetrics enables automatic collection of runtime metrics every 10 seconds.
 func WithRuntimeMetrics() StartOption {
 	return func(cfg *config) {
@@ -254,7 +254,7 @@ func TestAgentIntegration(t *testing.T) {
 		defer clearIntegrationsForTests()
 
 		cfg.loadContribIntegrations(nil)
-		assert.Equal(t, len(cfg.integrations), 55)
+		assert.Equal(t, len(cfg.integrations), 61)
 		for integrationName, v := range cfg.integrations {
 			assert.False(t, v.Instrumented, "integrationName=%s", integrationName)
 		}
@@ -1047,6 +1047,7 @@ func TestVersionConfig(t *testing.T) {
 			WithServiceVersion("1.2.3"),
 		)
 		assert.Equal("1.2.3", c.version)
+		assert.Equal("1.2.3", globalconfig.Version())
 	})
 
 	t.Run("env", func(t *testing.T) {
@@ -1099,6 +1100,7 @@ func TestEnvConfig(t *testing.T) {
 			WithEnv("testing"),
 		)
 		assert.Equal("testing", c.env)
+		assert.Equal("testing", globalconfig.Env())
 	})
 
 	t.Run("env", func(t *testing.T) {
@@ -22,6 +22,7 @@ import (
 	globalinternal "gopkg.in/DataDog/dd-trace-go.v1/internal"
 	"gopkg.in/DataDog/dd-trace-go.v1/internal/appsec"
 	appsecConfig "gopkg.in/DataDog/dd-trace-go.v1/internal/appsec/config"
+	"gopkg.in/DataDog/dd-trace-go.v1/internal/civisibility/testcontext"
 	"gopkg.in/DataDog/dd-trace-go.v1/internal/datastreams"
 	"gopkg.in/DataDog/dd-trace-go.v1/internal/hostname"
 	"gopkg.in/DataDog/dd-trace-go.v1/internal/log"
@@ -170,7 +171,7 @@ func Start(opts ...StartOption) {
 	// appsec.Start() may use the telemetry client to report activation, so it is
 	// important this happens _AFTER_ startTelemetry() has been called, so the
 	// client is appropriately configured.
-	appsec.Start(appsecConfig.WithRCConfig(cfg))
+	appsec.Start(append([]appsecConfig.StartOption{appsecConfig.WithRCConfig(cfg)}, t.config.appsecStartOptions...)...)
 	_ = t.hostname() // Prime the hostname cache
 }
 
@@ -485,6 +486,12 @@ func (t *tracer) StartSpan(operationName string, options ...ddtrace.StartSpanOpt
 				spanID:  p.SpanID(),
 			}
 		}
+	} else if t.config.ciVisibilityEnabled && !isCiVisibilityEventType(opts.Tags[ext.SpanType]) {
+		// The spans started without a parent during a test, such as the spans of the integrations,
+		// are the children of the test.
+		if ctx, ok := testcontext.Active().(*spanContext); ok {
+			context = ctx
+		}
 	}
 	if pprofContext == nil {
 		// For root span's without context, there is no pprofContext, but we need
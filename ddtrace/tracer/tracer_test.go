@@ -28,6 +28,8 @@ import (
 	"gopkg.in/DataDog/dd-trace-go.v1/ddtrace/ext"
 	"gopkg.in/DataDog/dd-trace-go.v1/ddtrace/internal"
 	maininternal "gopkg.in/DataDog/dd-trace-go.v1/internal"
+	"gopkg.in/DataDog/dd-trace-go.v1/internal/civisibility/constants"
+	"gopkg.in/DataDog/dd-trace-go.v1/internal/civisibility/testcontext"
 	"gopkg.in/DataDog/dd-trace-go.v1/internal/globalconfig"
 	"gopkg.in/DataDog/dd-trace-go.v1/internal/log"
 	"gopkg.in/DataDog/dd-trace-go.v1/internal/statsdtest"
@@ -772,6 +774,26 @@ func TestTracerStartChildSpan(t *testing.T) {
 		assert.Equal(1.0, root.Metrics[keyTopLevel])
 		assert.NotContains(child.Metrics, keyTopLevel)
 	})
+
+	t.Run("ci-visibility-test", func(t *testing.T) {
+		assert := assert.New(t)
+		tracer := newTracer()
+		defer tracer.Stop()
+		tracer.config.ciVisibilityEnabled = true
+		test := tracer.StartSpan("test", SpanType(constants.SpanTypeTest)).(*span)
+		endTest := testcontext.Start(test.Context(), nil)
+
+		// the spans started without a parent during the test are its children, but not the subtests
+		subtest := tracer.StartSpan("test", SpanType(constants.SpanTypeTest)).(*span)
+		child := tracer.StartSpan("http.request").(*span)
+		assert.Equal(uint64(0), subtest.ParentID)
+		assert.Equal(test.SpanID, child.ParentID)
+		assert.Equal(test.TraceID, child.TraceID)
+
+		endTest()
+		root := tracer.StartSpan("http.request").(*span)
+		assert.Equal(uint64(0), root.ParentID)
+	})
 }
 
 func TestTracerBaggagePropagation(t *testing.T) {
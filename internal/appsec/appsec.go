@@ -15,6 +15,7 @@ import (
 
 	"gopkg.in/DataDog/dd-trace-go.v1/internal/appsec/config"
 	"gopkg.in/DataDog/dd-trace-go.v1/internal/appsec/dyngo"
+	"gopkg.in/DataDog/dd-trace-go.v1/internal/appsec/eventsink"
 	"gopkg.in/DataDog/dd-trace-go.v1/internal/log"
 )
 
@@ -26,6 +27,26 @@ func Enabled() bool {
 	return activeAppSec != nil && activeAppSec.started
 }
 
+// RASPEnabled returns true when AppSec is up and running and its Runtime Application Self-Protection (RASP) features
+// are enabled. RASP is enabled by default and can be disabled with DD_APPSEC_RASP_ENABLED=false.
+func RASPEnabled() bool {
+	mu.RLock()
+	defer mu.RUnlock()
+	return activeAppSec != nil && activeAppSec.started && activeAppSec.cfg.RASP
+}
+
+// UserIdentificationMode returns the way users are identified in the automatically tracked login events when AppSec is
+// up and running, and config.UserIdentificationModeDisabled otherwise. It can be configured with
+// DD_APPSEC_AUTO_USER_INSTRUMENTATION_MODE.
+func UserIdentificationMode() config.UserIdentificationMode {
+	mu.RLock()
+	defer mu.RUnlock()
+	if activeAppSec == nil || !activeAppSec.started {
+		return config.UserIdentificationModeDisabled
+	}
+	return activeAppSec.cfg.UserIdentificationMode
+}
+
 // Start AppSec when enabled is enabled by both using the appsec build tag and
 // setting the environment variable DD_APPSEC_ENABLED to true.
 func Start(opts ...config.StartOption) {
@@ -131,6 +152,11 @@ type appsec struct {
 	limiter   *limiter.TokenTicker
 	wafHandle *waf.Handle
 	started   bool
+
+	// Local rules directory state, only used when cfg.RulesDir is set
+	rulesDirWatcher     *rulesDirWatcher
+	rulesDirFallback    config.RulesFragment
+	rulesDirFingerprint string
 }
 
 func newAppSec(cfg *config.Config) *appsec {
@@ -156,16 +182,26 @@ func (a *appsec) start(telemetry *appsecTelemetry) error {
 	a.limiter = limiter.NewTokenTicker(a.cfg.TraceRateLimit, a.cfg.TraceRateLimit)
 	a.limiter.Start()
 
-	// Register the WAF operation event listener
-	if err := a.swapWAF(a.cfg.RulesManager.Latest); err != nil {
-		return err
+	if a.cfg.RulesDir != "" {
+		a.loadRulesDir()
+	}
+	// Register the WAF operation event listener, unless already done with the local rules directory
+	if a.wafHandle == nil {
+		if err := a.swapWAF(a.cfg.RulesManager.Latest); err != nil {
+			return err
+		}
 	}
 
 	a.enableRCBlocking()
+	a.startEventSink()
 
 	a.started = true
 	log.Info("appsec: up and running")
 
+	if a.cfg.RulesDir != "" {
+		a.startRulesDirWatcher()
+	}
+
 	// TODO: log the config like the APM tracer does but we first need to define
 	//   an user-friendly string representation of our config and its sources
 
@@ -182,8 +218,10 @@ func (a *appsec) stop() {
 	defer telemetry.emit()
 
 	a.started = false
-	// Disable RC blocking first so that the following is guaranteed not to be concurrent anymore.
+	// Disable RC blocking and the local rules directory watcher first so that the following is guaranteed not to be
+	// concurrent anymore.
 	a.disableRCBlocking()
+	a.stopRulesDirWatcher()
 
 	// Disable the currently applied instrumentation
 	dyngo.SwapRootOperation(nil)
@@ -194,6 +232,24 @@ func (a *appsec) stop() {
 	// TODO: block until no more requests are using dyngo operations
 
 	a.limiter.Stop()
+	eventsink.Stop()
+}
+
+// startEventSink starts exporting the security events to the configured event sink, if any.
+func (a *appsec) startEventSink() {
+	sink, closeOnStop := a.cfg.EventSink, false
+	if sink == nil {
+		if a.cfg.EventSinkURL == "" {
+			return
+		}
+		var err error
+		if sink, err = eventsink.Open(a.cfg.EventSinkURL); err != nil {
+			log.Error("appsec: could not open the event sink %s: %v", a.cfg.EventSinkURL, err)
+			return
+		}
+		closeOnStop = true
+	}
+	eventsink.Start(sink, a.cfg.EventSinkRateLimit, closeOnStop)
 }
 
 func init() {
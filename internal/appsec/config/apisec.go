@@ -0,0 +1,145 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2024 Datadog, Inc.
+
+package config
+
+import (
+	"os"
+	"strconv"
+	"sync"
+	"time"
+
+	"gopkg.in/DataDog/dd-trace-go.v1/internal/log"
+)
+
+const (
+	// EnvAPISecResponseBodyEnabled controls whether the JSON response bodies of the requests sampled by API Security
+	// are captured and analyzed by the WAF, for schema extraction and sensitive data scanners.
+	EnvAPISecResponseBodyEnabled = "DD_API_SECURITY_RESPONSE_BODY_ENABLED"
+	// EnvAPISecResponseBodyMaxSize is the maximum size, in bytes, of the response bodies captured by API Security.
+	// Larger response bodies are not analyzed.
+	EnvAPISecResponseBodyMaxSize = "DD_API_SECURITY_RESPONSE_BODY_MAX_SIZE"
+	// EnvAPISecEndpointSampleBudget is the maximum number of requests sampled by API Security per endpoint and per
+	// minute. A budget of 0, the default, disables the per-endpoint limit.
+	EnvAPISecEndpointSampleBudget = "DD_API_SECURITY_ENDPOINT_SAMPLE_BUDGET"
+
+	// DefaultAPISecResponseBodyMaxSize is the default maximum size of the response bodies captured by API Security.
+	DefaultAPISecResponseBodyMaxSize = 64 * 1024
+	// DefaultAPISecEndpointSampleBudget is the default maximum number of requests sampled by API Security per
+	// endpoint and per minute, 0 meaning the per-endpoint limit is disabled.
+	DefaultAPISecEndpointSampleBudget = 0
+
+	apiSecEndpointSampleWindow = time.Minute
+	// apiSecMaxEndpoints bounds the memory used by the endpoint sampler.
+	apiSecMaxEndpoints = 4096
+)
+
+// APISecResponseBodyMaxSize returns the maximum size of the response bodies captured by API Security, or 0 when the
+// response body capture is disabled, as configured by DD_API_SECURITY_RESPONSE_BODY_ENABLED and
+// DD_API_SECURITY_RESPONSE_BODY_MAX_SIZE. The response body capture is disabled by default.
+func APISecResponseBodyMaxSize() int {
+	enabled, _, err := parseBoolEnvVar(EnvAPISecResponseBodyEnabled)
+	if err != nil {
+		log.Error("appsec: %v", err)
+		return 0
+	}
+	if !enabled {
+		return 0
+	}
+	str := os.Getenv(EnvAPISecResponseBodyMaxSize)
+	if str == "" {
+		return DefaultAPISecResponseBodyMaxSize
+	}
+	size, err := strconv.Atoi(str)
+	if err != nil || size <= 0 {
+		log.Error("appsec: could not parse %s value `%s` as a positive integer, using %d instead", EnvAPISecResponseBodyMaxSize, str, DefaultAPISecResponseBodyMaxSize)
+		return DefaultAPISecResponseBodyMaxSize
+	}
+	return size
+}
+
+// APISecEndpointSampleBudget returns the maximum number of requests sampled by API Security per endpoint and per
+// minute, as configured by DD_API_SECURITY_ENDPOINT_SAMPLE_BUDGET. In case of a parsing error, it logs the error and
+// returns the default budget.
+func APISecEndpointSampleBudget() int {
+	str := os.Getenv(EnvAPISecEndpointSampleBudget)
+	if str == "" {
+		return DefaultAPISecEndpointSampleBudget
+	}
+	budget, err := strconv.Atoi(str)
+	if err != nil || budget < 0 {
+		log.Error("appsec: could not parse %s value `%s` as a non-negative integer, using %d instead", EnvAPISecEndpointSampleBudget, str, DefaultAPISecEndpointSampleBudget)
+		return DefaultAPISecEndpointSampleBudget
+	}
+	return budget
+}
+
+// EndpointSampler limits the number of requests sampled by API Security per endpoint, so that the schemas of every
+// endpoint get collected without spending the whole sampling rate on the most frequent ones.
+type EndpointSampler struct {
+	budget    int
+	endpoints map[endpointKey]*endpointBudget
+	mu        sync.Mutex
+	now       func() time.Time
+}
+
+type endpointKey struct {
+	method, route string
+}
+
+type endpointBudget struct {
+	windowStart time.Time
+	count       int
+}
+
+// NewEndpointSampler returns a new endpoint sampler allowing up to budget sampled requests per endpoint and per
+// minute. A budget of 0 disables the per-endpoint limit.
+func NewEndpointSampler(budget int) *EndpointSampler {
+	return &EndpointSampler{
+		budget:    budget,
+		endpoints: make(map[endpointKey]*endpointBudget),
+		now:       time.Now,
+	}
+}
+
+// Sample returns true when the budget of the endpoint identified by the given HTTP method and route allows sampling
+// the request, in which case the request is accounted for in the endpoint budget. A nil sampler samples every request.
+func (s *EndpointSampler) Sample(method, route string) bool {
+	if s == nil || s.budget == 0 {
+		return true
+	}
+	key := endpointKey{method: method, route: route}
+	now := s.now()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	b, ok := s.endpoints[key]
+	if !ok {
+		if len(s.endpoints) >= apiSecMaxEndpoints && !s.evictExpired(now) {
+			return false
+		}
+		b = &endpointBudget{windowStart: now}
+		s.endpoints[key] = b
+	} else if now.Sub(b.windowStart) >= apiSecEndpointSampleWindow {
+		b.windowStart = now
+		b.count = 0
+	}
+	if b.count >= s.budget {
+		return false
+	}
+	b.count++
+	return true
+}
+
+// evictExpired removes the endpoints whose budget window is over and returns true if any was removed.
+func (s *EndpointSampler) evictExpired(now time.Time) (evicted bool) {
+	for key, b := range s.endpoints {
+		if now.Sub(b.windowStart) >= apiSecEndpointSampleWindow {
+			delete(s.endpoints, key)
+			evicted = true
+		}
+	}
+	return evicted
+}
@@ -0,0 +1,78 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2024 Datadog, Inc.
+
+package config
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestEndpointSampler(t *testing.T) {
+	now := time.Now()
+	s := NewEndpointSampler(2)
+	s.now = func() time.Time { return now }
+
+	require.True(t, s.Sample("GET", "/users/{id}"))
+	require.True(t, s.Sample("GET", "/users/{id}"))
+	require.False(t, s.Sample("GET", "/users/{id}"))
+	// Other endpoints have their own budget
+	require.True(t, s.Sample("POST", "/users/{id}"))
+	require.True(t, s.Sample("GET", "/orders"))
+
+	// The budget gets renewed once the window is over
+	now = now.Add(apiSecEndpointSampleWindow)
+	require.True(t, s.Sample("GET", "/users/{id}"))
+
+	t.Run("unlimited", func(t *testing.T) {
+		s := NewEndpointSampler(0)
+		for i := 0; i < 10; i++ {
+			require.True(t, s.Sample("GET", "/"))
+		}
+	})
+
+	t.Run("nil", func(t *testing.T) {
+		var s *EndpointSampler
+		require.True(t, s.Sample("GET", "/"))
+	})
+
+	t.Run("max-endpoints", func(t *testing.T) {
+		now := time.Now()
+		s := NewEndpointSampler(1)
+		s.now = func() time.Time { return now }
+		for i := 0; i < apiSecMaxEndpoints; i++ {
+			require.True(t, s.Sample("GET", string(rune(i))))
+		}
+		require.False(t, s.Sample("GET", "/new"))
+		// Expired endpoints get evicted to make room for new ones
+		now = now.Add(apiSecEndpointSampleWindow)
+		require.True(t, s.Sample("GET", "/new"))
+		require.Len(t, s.endpoints, 1)
+	})
+}
+
+func TestAPISecResponseBodyMaxSize(t *testing.T) {
+	for _, tc := range []struct {
+		name     string
+		enabled  string
+		maxSize  string
+		expected int
+	}{
+		{name: "default", expected: 0},
+		{name: "disabled", enabled: "false", maxSize: "10", expected: 0},
+		{name: "enabled", enabled: "true", expected: DefaultAPISecResponseBodyMaxSize},
+		{name: "max-size", enabled: "true", maxSize: "10", expected: 10},
+		{name: "invalid-max-size", enabled: "true", maxSize: "-10", expected: DefaultAPISecResponseBodyMaxSize},
+		{name: "invalid", enabled: "yes please", expected: 0},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Setenv(EnvAPISecResponseBodyEnabled, tc.enabled)
+			t.Setenv(EnvAPISecResponseBodyMaxSize, tc.maxSize)
+			require.Equal(t, tc.expected, APISecResponseBodyMaxSize())
+		})
+	}
+}
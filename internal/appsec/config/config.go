@@ -13,6 +13,7 @@ import (
 
 	internal "github.com/DataDog/appsec-internal-go/appsec"
 
+	"gopkg.in/DataDog/dd-trace-go.v1/internal/appsec/eventsink"
 	"gopkg.in/DataDog/dd-trace-go.v1/internal/log"
 	"gopkg.in/DataDog/dd-trace-go.v1/internal/remoteconfig"
 	"gopkg.in/DataDog/dd-trace-go.v1/internal/telemetry"
@@ -47,6 +48,8 @@ const (
 	EnvEnabled = "DD_APPSEC_ENABLED"
 	// EnvSCAEnabled controls ASM Software Composition Analysis (SCA)'s enablement.
 	EnvSCAEnabled = "DD_APPSEC_SCA_ENABLED"
+	// EnvRASPEnabled controls ASM Runtime Application Self-Protection (RASP)'s enablement.
+	EnvRASPEnabled = "DD_APPSEC_RASP_ENABLED"
 )
 
 // StartOption is used to customize the AppSec configuration when invoked with appsec.Start()
@@ -65,8 +68,30 @@ type Config struct {
 	Obfuscator internal.ObfuscatorConfig
 	// APISec configuration
 	APISec internal.APISecConfig
+	// APISecSampler limits the number of requests sampled by API Security per endpoint.
+	APISecSampler *EndpointSampler
+	// APISecResponseBodyMaxSize is the maximum size of the JSON response bodies captured by API Security, or 0 when
+	// the response body capture is disabled.
+	APISecResponseBodyMaxSize int
 	// RC is the remote configuration client used to receive product configuration updates. Nil if RC is disabled (default)
 	RC *remoteconfig.ClientConfig
+	// RASP determines whether RASP features are enabled or not.
+	RASP bool
+	// RulesDir is the directory of local security rules files set with DD_APPSEC_RULES_DIR. When set, the security
+	// rules are loaded from and live-updated with its files instead of remote configuration.
+	RulesDir string
+	// RulesDirPollInterval is the interval at which RulesDir is checked for changes.
+	RulesDirPollInterval time.Duration
+	// UserIdentificationMode is the way users are identified in the automatically tracked login events.
+	UserIdentificationMode UserIdentificationMode
+	// EventSink is the event sink the security events are exported to, set with WithEventSink. It takes precedence
+	// over EventSinkURL.
+	EventSink eventsink.Sink
+	// EventSinkURL is the URL of the event sink the security events are exported to, set with DD_APPSEC_EVENT_SINK.
+	EventSinkURL string
+	// EventSinkRateLimit is the maximum number of security events exported to the event sink per second. It is
+	// independent of TraceRateLimit.
+	EventSinkRateLimit int64
 }
 
 // WithRCConfig sets the AppSec remote config client configuration to the specified cfg
@@ -110,10 +135,34 @@ func NewConfig() (*Config, error) {
 	}
 
 	return &Config{
-		RulesManager:   r,
-		WAFTimeout:     internal.WAFTimeoutFromEnv(),
-		TraceRateLimit: int64(internal.RateLimitFromEnv()),
-		Obfuscator:     internal.NewObfuscatorConfig(),
-		APISec:         internal.NewAPISecConfig(),
+		RulesManager:              r,
+		WAFTimeout:                internal.WAFTimeoutFromEnv(),
+		TraceRateLimit:            int64(internal.RateLimitFromEnv()),
+		Obfuscator:                internal.NewObfuscatorConfig(),
+		APISec:                    internal.NewAPISecConfig(),
+		APISecSampler:             NewEndpointSampler(APISecEndpointSampleBudget()),
+		APISecResponseBodyMaxSize: APISecResponseBodyMaxSize(),
+		RASP:                      RASPEnabled(),
+		RulesDir:                  os.Getenv(EnvRulesDir),
+		RulesDirPollInterval:      RulesDirPollInterval(),
+		UserIdentificationMode:    AutoUserInstrumentationMode(),
+		EventSinkURL:              os.Getenv(EnvEventSink),
+		EventSinkRateLimit:        EventSinkRateLimit(),
 	}, nil
 }
+
+// RASPEnabled returns true unless DD_APPSEC_RASP_ENABLED is set to false (as of strconv's boolean parsing rules).
+// RASP is enabled by default when AppSec is enabled. In case of a parsing error, it logs the error and keeps RASP
+// enabled.
+func RASPEnabled() bool {
+	str := os.Getenv(EnvRASPEnabled)
+	if str == "" {
+		return true
+	}
+	enabled, err := strconv.ParseBool(str)
+	if err != nil {
+		log.Error("appsec: could not parse %s value `%s` as a boolean value", EnvRASPEnabled, str)
+		return true
+	}
+	return enabled
+}
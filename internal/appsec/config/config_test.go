@@ -9,6 +9,8 @@ import (
 	"testing"
 
 	"gopkg.in/DataDog/dd-trace-go.v1/internal/telemetry/telemetrytest"
+
+	"github.com/stretchr/testify/require"
 )
 
 func TestSCAEnabled(t *testing.T) {
@@ -62,3 +64,23 @@ func TestSCAEnabled(t *testing.T) {
 		})
 	}
 }
+
+func TestRASPEnabled(t *testing.T) {
+	for _, tc := range []struct {
+		name      string
+		envVarVal string
+		expected  bool
+	}{
+		{name: "undefined", envVarVal: "", expected: true},
+		{name: "true", envVarVal: "true", expected: true},
+		{name: "false", envVarVal: "false", expected: false},
+		{name: "parsing error", envVarVal: "not a boolean", expected: true},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			if tc.envVarVal != "" {
+				t.Setenv(EnvRASPEnabled, tc.envVarVal)
+			}
+			require.Equal(t, tc.expected, RASPEnabled())
+		})
+	}
+}
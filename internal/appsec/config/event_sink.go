@@ -0,0 +1,50 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2024 Datadog, Inc.
+
+package config
+
+import (
+	"os"
+	"strconv"
+
+	"gopkg.in/DataDog/dd-trace-go.v1/internal/appsec/eventsink"
+	"gopkg.in/DataDog/dd-trace-go.v1/internal/log"
+)
+
+const (
+	// EnvEventSink is the URL of the local event sink the security events are exported to, either
+	// file:///path/to/events.ndjson or udp://host:port for a syslog server.
+	EnvEventSink = "DD_APPSEC_EVENT_SINK"
+	// EnvEventSinkRateLimit is the maximum number of security events exported to the event sink per second.
+	EnvEventSinkRateLimit = "DD_APPSEC_EVENT_SINK_RATE_LIMIT"
+
+	// DefaultEventSinkRateLimit is the default maximum number of security events exported to the event sink per
+	// second.
+	DefaultEventSinkRateLimit = 100
+)
+
+// WithEventSink sets the event sink the security events are exported to, instead of the one configured by
+// DD_APPSEC_EVENT_SINK. The given sink is not closed when AppSec stops.
+func WithEventSink(sink eventsink.Sink) StartOption {
+	return func(c *Config) {
+		c.EventSink = sink
+	}
+}
+
+// EventSinkRateLimit returns the maximum number of security events exported to the event sink per second, as
+// configured by DD_APPSEC_EVENT_SINK_RATE_LIMIT. In case of a parsing error, it logs the error and returns the default
+// rate limit.
+func EventSinkRateLimit() int64 {
+	str := os.Getenv(EnvEventSinkRateLimit)
+	if str == "" {
+		return DefaultEventSinkRateLimit
+	}
+	limit, err := strconv.ParseInt(str, 10, 64)
+	if err != nil || limit <= 0 {
+		log.Error("appsec: could not parse %s value `%s` as a positive integer, using %d instead", EnvEventSinkRateLimit, str, DefaultEventSinkRateLimit)
+		return DefaultEventSinkRateLimit
+	}
+	return limit
+}
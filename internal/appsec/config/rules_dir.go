@@ -0,0 +1,136 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2024 Datadog, Inc.
+
+package config
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"gopkg.in/DataDog/dd-trace-go.v1/internal/log"
+)
+
+const (
+	// EnvRulesDir is the directory of local security rules files watched by AppSec. When set, the rules are no longer
+	// updated with remote configuration.
+	EnvRulesDir = "DD_APPSEC_RULES_DIR"
+	// EnvRulesDirPollInterval is the interval at which the local security rules directory is checked for changes.
+	EnvRulesDirPollInterval = "DD_APPSEC_RULES_DIR_POLL_INTERVAL"
+
+	defaultRulesDirPollInterval = 5 * time.Second
+)
+
+// Rules data types supported by the WAF for IP and user denylists.
+const (
+	ruleDataTypeIPWithExpiration   = "ip_with_expiration"
+	ruleDataTypeDataWithExpiration = "data_with_expiration"
+)
+
+// RulesDirPollInterval returns the interval at which the local security rules directory is checked for changes, as
+// configured by DD_APPSEC_RULES_DIR_POLL_INTERVAL. In case of a parsing error, it logs the error and returns the
+// default interval of 5 seconds.
+func RulesDirPollInterval() time.Duration {
+	str := os.Getenv(EnvRulesDirPollInterval)
+	if str == "" {
+		return defaultRulesDirPollInterval
+	}
+	interval, err := time.ParseDuration(str)
+	if err != nil || interval <= 0 {
+		log.Error("appsec: could not parse %s value `%s` as a positive duration, using %s instead", EnvRulesDirPollInterval, str, defaultRulesDirPollInterval)
+		return defaultRulesDirPollInterval
+	}
+	return interval
+}
+
+// LoadRulesDir returns a new RulesManager out of the JSON files found at the top level of dir, each of them being a
+// RulesFragment. The file defining rules, if any, is the base fragment of the rules manager, while the others - such as
+// exclusions, custom rules, rules overrides or IP and user denylists - are its edits. The given fallback fragment is
+// the base fragment when no file defines rules.
+// Every invalid file is reported in the returned error, in which case the returned rules manager must not be used.
+func LoadRulesDir(dir string, fallback RulesFragment) (*RulesManager, error) {
+	paths, err := RulesDirFiles(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	r := &RulesManager{
+		Base:  fallback,
+		Edits: make(map[string]RulesFragment, len(paths)),
+	}
+	var (
+		errs     []error
+		basePath string
+	)
+	for _, path := range paths {
+		f, err := readRulesFile(path)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", path, err))
+			continue
+		}
+		if len(f.Rules) == 0 {
+			r.AddEdit(path, f)
+			continue
+		}
+		if basePath != "" {
+			errs = append(errs, fmt.Errorf("%s: rules are already defined by %s", path, basePath))
+			continue
+		}
+		basePath = path
+		r.ChangeBase(f, path)
+	}
+	if err := errors.Join(errs...); err != nil {
+		return nil, err
+	}
+
+	r.Compile()
+	return r, nil
+}
+
+// RulesDirFiles returns the sorted list of paths of the JSON files found at the top level of dir.
+func RulesDirFiles(dir string) ([]string, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	var paths []string
+	for _, e := range entries {
+		if e.IsDir() || !strings.EqualFold(filepath.Ext(e.Name()), ".json") {
+			continue
+		}
+		paths = append(paths, filepath.Join(dir, e.Name()))
+	}
+	sort.Strings(paths)
+	return paths, nil
+}
+
+// readRulesFile reads and validates the rules fragment of the given file.
+func readRulesFile(path string) (f RulesFragment, err error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return f, err
+	}
+	if err := json.Unmarshal(data, &f); err != nil {
+		return f, err
+	}
+	if len(f.Rules) == 0 && len(f.Overrides) == 0 && len(f.Exclusions) == 0 && len(f.RulesData) == 0 &&
+		len(f.Actions) == 0 && len(f.CustomRules) == 0 && len(f.Processors) == 0 && len(f.Scanners) == 0 {
+		return f, errors.New("no rules, rules overrides, exclusions, rules data, actions, custom rules, processors or scanners found")
+	}
+	for i, d := range f.RulesData {
+		if d.ID == "" {
+			return f, fmt.Errorf("rules_data[%d]: missing id", i)
+		}
+		if d.Type != ruleDataTypeIPWithExpiration && d.Type != ruleDataTypeDataWithExpiration {
+			return f, fmt.Errorf("rules_data[%d]: unsupported type `%s`", i, d.Type)
+		}
+	}
+	return f, nil
+}
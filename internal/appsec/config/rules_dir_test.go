@@ -0,0 +1,100 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2024 Datadog, Inc.
+
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	testRules = `{
+  "version": "2.2",
+  "rules": [{"id": "custom-001", "name": "test", "tags": {"type": "test", "category": "attack_attempt"},
+    "conditions": [{"operator": "match_regex", "parameters": {"inputs": [{"address": "server.request.query"}], "regex": "^zouzou$"}}]}]
+}`
+	testExclusions = `{"exclusions": [{"id": "exc-001", "rules_target": [{"rule_id": "custom-001"}]}]}`
+	testDenylist   = `{"rules_data": [{"id": "blocked_ips", "type": "ip_with_expiration", "data": [{"value": "1.2.3.4", "expiration": 0}]}]}`
+)
+
+func writeRulesDir(t *testing.T, files map[string]string) string {
+	dir := t.TempDir()
+	for name, content := range files {
+		require.NoError(t, os.WriteFile(filepath.Join(dir, name), []byte(content), 0o600))
+	}
+	return dir
+}
+
+func TestLoadRulesDir(t *testing.T) {
+	fallback := RulesFragment{Version: "fallback", Rules: []any{"fallback-rule"}}
+
+	t.Run("base-and-edits", func(t *testing.T) {
+		dir := writeRulesDir(t, map[string]string{
+			"rules.json":      testRules,
+			"exclusions.json": testExclusions,
+			"denylist.json":   testDenylist,
+			"README.md":       "not a rules file",
+		})
+		r, err := LoadRulesDir(dir, fallback)
+		require.NoError(t, err)
+		require.Equal(t, filepath.Join(dir, "rules.json"), r.BasePath)
+		require.Len(t, r.Edits, 2)
+		require.Len(t, r.Latest.Rules, 1)
+		require.Len(t, r.Latest.Exclusions, 1)
+		require.Len(t, r.Latest.RulesData, 1)
+		require.Equal(t, "blocked_ips", r.Latest.RulesData[0].ID)
+	})
+
+	t.Run("fallback-base", func(t *testing.T) {
+		dir := writeRulesDir(t, map[string]string{"denylist.json": testDenylist})
+		r, err := LoadRulesDir(dir, fallback)
+		require.NoError(t, err)
+		require.Empty(t, r.BasePath)
+		require.Equal(t, "fallback", r.Latest.Version)
+		require.Len(t, r.Latest.RulesData, 1)
+	})
+
+	for name, files := range map[string]map[string]string{
+		"invalid-json":       {"rules.json": testRules, "bad.json": `{"exclusions": [`},
+		"empty-fragment":     {"empty.json": `{"version": "2.2"}`},
+		"several-bases":      {"a.json": testRules, "b.json": testRules},
+		"bad-rule-data-type": {"denylist.json": `{"rules_data": [{"id": "blocked_ips", "type": "ip", "data": []}]}`},
+		"missing-rule-data":  {"denylist.json": `{"rules_data": [{"type": "ip_with_expiration", "data": []}]}`},
+	} {
+		t.Run(name, func(t *testing.T) {
+			dir := writeRulesDir(t, files)
+			r, err := LoadRulesDir(dir, fallback)
+			require.Error(t, err)
+			require.Nil(t, r)
+		})
+	}
+
+	t.Run("missing-dir", func(t *testing.T) {
+		_, err := LoadRulesDir(filepath.Join(t.TempDir(), "missing"), fallback)
+		require.Error(t, err)
+	})
+}
+
+func TestRulesDirPollInterval(t *testing.T) {
+	for _, tc := range []struct {
+		value    string
+		expected time.Duration
+	}{
+		{value: "", expected: defaultRulesDirPollInterval},
+		{value: "100ms", expected: 100 * time.Millisecond},
+		{value: "-1s", expected: defaultRulesDirPollInterval},
+		{value: "invalid", expected: defaultRulesDirPollInterval},
+	} {
+		t.Run(tc.value, func(t *testing.T) {
+			t.Setenv(EnvRulesDirPollInterval, tc.value)
+			require.Equal(t, tc.expected, RulesDirPollInterval())
+		})
+	}
+}
@@ -0,0 +1,45 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2024 Datadog, Inc.
+
+package config
+
+import (
+	"os"
+	"strings"
+
+	"gopkg.in/DataDog/dd-trace-go.v1/internal/log"
+)
+
+// EnvAutoUserInstrumentationMode is the identification mode of the users of the login events automatically tracked by
+// the AppSec authentication adapters.
+const EnvAutoUserInstrumentationMode = "DD_APPSEC_AUTO_USER_INSTRUMENTATION_MODE"
+
+// UserIdentificationMode is the way users are identified in the automatically tracked login events.
+type UserIdentificationMode string
+
+const (
+	// UserIdentificationModeIdentification reports the user IDs as-is.
+	UserIdentificationModeIdentification UserIdentificationMode = "identification"
+	// UserIdentificationModeAnonymization reports anonymized hashes of the user IDs.
+	UserIdentificationModeAnonymization UserIdentificationMode = "anonymization"
+	// UserIdentificationModeDisabled disables the automatic tracking of login events.
+	UserIdentificationModeDisabled UserIdentificationMode = "disabled"
+)
+
+// AutoUserInstrumentationMode returns the user identification mode of the automatically tracked login events, as
+// configured by DD_APPSEC_AUTO_USER_INSTRUMENTATION_MODE. It defaults to the identification mode. In case of a parsing
+// error, it logs the error and returns the default mode.
+func AutoUserInstrumentationMode() UserIdentificationMode {
+	str := os.Getenv(EnvAutoUserInstrumentationMode)
+	if str == "" {
+		return UserIdentificationModeIdentification
+	}
+	switch mode := UserIdentificationMode(strings.ToLower(str)); mode {
+	case UserIdentificationModeIdentification, UserIdentificationModeAnonymization, UserIdentificationModeDisabled:
+		return mode
+	}
+	log.Error("appsec: unexpected %s value `%s`, expected one of `identification`, `anonymization` or `disabled`, using `identification` instead", EnvAutoUserInstrumentationMode, str)
+	return UserIdentificationModeIdentification
+}
@@ -0,0 +1,30 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2024 Datadog, Inc.
+
+package config
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestAutoUserInstrumentationMode(t *testing.T) {
+	for _, tc := range []struct {
+		value    string
+		expected UserIdentificationMode
+	}{
+		{value: "", expected: UserIdentificationModeIdentification},
+		{value: "identification", expected: UserIdentificationModeIdentification},
+		{value: "ANONYMIZATION", expected: UserIdentificationModeAnonymization},
+		{value: "disabled", expected: UserIdentificationModeDisabled},
+		{value: "invalid", expected: UserIdentificationModeIdentification},
+	} {
+		t.Run(tc.value, func(t *testing.T) {
+			t.Setenv(EnvAutoUserInstrumentationMode, tc.value)
+			require.Equal(t, tc.expected, AutoUserInstrumentationMode())
+		})
+	}
+}
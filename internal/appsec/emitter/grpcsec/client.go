@@ -0,0 +1,51 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2024 Datadog, Inc.
+
+package grpcsec
+
+import (
+	"context"
+
+	"gopkg.in/DataDog/dd-trace-go.v1/internal/appsec/dyngo"
+	"gopkg.in/DataDog/dd-trace-go.v1/internal/appsec/emitter/grpcsec/types"
+	"gopkg.in/DataDog/dd-trace-go.v1/internal/appsec/listener"
+)
+
+// ProtectClientCall starts and finishes the client call operation of an
+// outgoing gRPC call made while serving the monitored request of the given
+// context. An error is returned if the call must be blocked. It does nothing
+// when the call is not made while serving a monitored request.
+func ProtectClientCall(ctx context.Context, args types.ClientCallOperationArgs) error {
+	parent, _ := ctx.Value(listener.ContextKey{}).(dyngo.Operation)
+	if parent == nil {
+		return nil
+	}
+
+	var err error
+	op := &types.ClientCallOperation{Operation: dyngo.NewOperation(parent)}
+	dyngo.OnData(op, func(e error) { err = e })
+	dyngo.StartOperation(op, args)
+	dyngo.FinishOperation(op, types.ClientCallOperationRes{})
+	return err
+}
+
+// ProtectClientMessage starts and finishes the client message operation of a
+// message sent or received by an outgoing gRPC call made while serving the
+// monitored request of the given context. An error is returned if the message
+// must be blocked. It does nothing when the call is not made while serving a
+// monitored request.
+func ProtectClientMessage(ctx context.Context, args types.ClientMessageOperationArgs) error {
+	parent, _ := ctx.Value(listener.ContextKey{}).(dyngo.Operation)
+	if parent == nil {
+		return nil
+	}
+
+	var err error
+	op := &types.ClientMessageOperation{Operation: dyngo.NewOperation(parent)}
+	dyngo.OnData(op, func(e error) { err = e })
+	dyngo.StartOperation(op, args)
+	dyngo.FinishOperation(op, types.ClientMessageOperationRes{})
+	return err
+}
@@ -44,3 +44,13 @@ func StartReceiveOperation(args types.ReceiveOperationArgs, parent dyngo.Operati
 	dyngo.StartOperation(op, args)
 	return op
 }
+
+// StartSendOperation starts a send operation of a gRPC handler, along with the
+// given arguments and parent operation, and emits a start event up in the
+// operation stack. When parent is nil, the operation is linked to the global
+// root operation.
+func StartSendOperation(args types.SendOperationArgs, parent dyngo.Operation) types.SendOperation {
+	op := types.SendOperation{Operation: dyngo.NewOperation(parent)}
+	dyngo.StartOperation(op, args)
+	return op
+}
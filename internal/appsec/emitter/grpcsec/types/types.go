@@ -14,16 +14,15 @@ import (
 
 // Abstract gRPC server handler operation definitions. It is based on two
 // operations allowing to describe every type of RPC: the HandlerOperation type
-// which represents the RPC handler, and the ReceiveOperation type which
-// represents the messages the RPC handler receives during its lifetime.
-// This means that the ReceiveOperation(s) will happen within the
-// HandlerOperation.
+// which represents the RPC handler, the ReceiveOperation type which
+// represents the messages the RPC handler receives during its lifetime, and
+// the SendOperation type which represents the messages the RPC handler sends
+// back to the client.
+// This means that the ReceiveOperation(s) and SendOperation(s) will happen
+// within the HandlerOperation.
 // Every type of RPC, unary, client streaming, server streaming, and
 // bidirectional streaming RPCs, can be all represented with a HandlerOperation
-// having one or several ReceiveOperation.
-// The send operation is not required for now and therefore not defined, which
-// means that server and bidirectional streaming RPCs currently have the same
-// run-time representation as unary and client streaming RPCs.
+// having one or several ReceiveOperation and SendOperation.
 type (
 	// HandlerOperation represents a gRPC server handler operation.
 	// It must be created with StartHandlerOperation() and finished with its
@@ -52,8 +51,16 @@ type (
 		ClientIP netip.Addr
 	}
 
-	// HandlerOperationRes is the grpc handler results. Empty as of today.
-	HandlerOperationRes struct{}
+	// HandlerOperationRes is the grpc handler results.
+	HandlerOperationRes struct {
+		// Headers is the response metadata sent as headers by the gRPC handler.
+		// Corresponds to the address `grpc.server.response.metadata.headers`.
+		Headers map[string][]string
+
+		// Trailers is the response metadata sent as trailers by the gRPC handler.
+		// Corresponds to the address `grpc.server.response.metadata.trailers`.
+		Trailers map[string][]string
+	}
 
 	// ReceiveOperation type representing an gRPC server handler operation. It must
 	// be created with StartReceiveOperation() and finished with its Finish().
@@ -73,6 +80,69 @@ type (
 		Message interface{}
 	}
 
+	// SendOperation type representing a message sent by a gRPC server handler.
+	// It must be created with StartSendOperation() and finished with its
+	// Finish().
+	SendOperation struct {
+		dyngo.Operation
+	}
+
+	// SendOperationArgs is the gRPC handler send operation arguments.
+	// Empty as of today.
+	SendOperationArgs struct{}
+
+	// SendOperationRes is the gRPC handler send operation results which
+	// contains the message the gRPC handler is about to send.
+	SendOperationRes struct {
+		// Message sent by the gRPC handler.
+		// Corresponds to the address `grpc.server.response.message`.
+		Message interface{}
+	}
+
+	// ClientCallOperation type representing an outgoing gRPC call made by an
+	// instrumented gRPC client while serving a monitored request. It gets both
+	// created and finished in a single call to grpcsec.ProtectClientCall.
+	ClientCallOperation struct {
+		dyngo.Operation
+	}
+
+	// ClientCallOperationArgs is the gRPC client call operation arguments.
+	ClientCallOperationArgs struct {
+		// Method is the gRPC method called.
+		// Corresponds to the address `grpc.client.method`.
+		Method string
+
+		// Target is the target of the client connection.
+		// Corresponds to the address `grpc.client.target`.
+		Target string
+	}
+
+	// ClientCallOperationRes is the gRPC client call operation results.
+	ClientCallOperationRes struct{}
+
+	// ClientMessageOperation type representing a message sent or received by
+	// an outgoing gRPC call. It gets both created and finished in a single call
+	// to grpcsec.ProtectClientMessage.
+	ClientMessageOperation struct {
+		dyngo.Operation
+	}
+
+	// ClientMessageOperationArgs is the gRPC client message operation
+	// arguments.
+	ClientMessageOperationArgs struct {
+		// Message sent or received by the gRPC client.
+		// Corresponds to the address `grpc.client.request.message` when it is
+		// sent, and to the address `grpc.client.response.message` when it is
+		// received.
+		Message interface{}
+
+		// Received is true when the message was received by the gRPC client.
+		Received bool
+	}
+
+	// ClientMessageOperationRes is the gRPC client message operation results.
+	ClientMessageOperationRes struct{}
+
 	// MonitoringError is used to vehicle a gRPC error that also embeds a request status code
 	MonitoringError struct {
 		msg    string
@@ -112,8 +182,23 @@ func (op ReceiveOperation) Finish(res ReceiveOperationRes) {
 	dyngo.FinishOperation(op, res)
 }
 
+// Finish the gRPC handler send operation, along with the given results, and
+// emits a finish event up in the operation stack.
+func (op SendOperation) Finish(res SendOperationRes) {
+	dyngo.FinishOperation(op, res)
+}
+
 func (HandlerOperationArgs) IsArgOf(*HandlerOperation)   {}
 func (HandlerOperationRes) IsResultOf(*HandlerOperation) {}
 
 func (ReceiveOperationArgs) IsArgOf(ReceiveOperation)   {}
 func (ReceiveOperationRes) IsResultOf(ReceiveOperation) {}
+
+func (SendOperationArgs) IsArgOf(SendOperation)   {}
+func (SendOperationRes) IsResultOf(SendOperation) {}
+
+func (ClientCallOperationArgs) IsArgOf(*ClientCallOperation)   {}
+func (ClientCallOperationRes) IsResultOf(*ClientCallOperation) {}
+
+func (ClientMessageOperationArgs) IsArgOf(*ClientMessageOperation)   {}
+func (ClientMessageOperationRes) IsResultOf(*ClientMessageOperation) {}
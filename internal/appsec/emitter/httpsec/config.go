@@ -15,6 +15,9 @@ type Config struct {
 	// apply synchronization if they allow http.ResponseWriter objects to be
 	// accessed by multiple goroutines.
 	ResponseHeaderCopier func(http.ResponseWriter) http.Header
+	// Route is the matched route of the request, if known, identifying its API
+	// endpoint for API Security sampling.
+	Route string
 }
 
 var defaultWrapHandlerConfig = &Config{
@@ -22,10 +22,12 @@ import (
 	"gopkg.in/DataDog/dd-trace-go.v1/internal/appsec/dyngo"
 	"gopkg.in/DataDog/dd-trace-go.v1/internal/appsec/emitter/httpsec/types"
 	"gopkg.in/DataDog/dd-trace-go.v1/internal/appsec/emitter/sharedsec"
+	"gopkg.in/DataDog/dd-trace-go.v1/internal/appsec/eventsink"
 	"gopkg.in/DataDog/dd-trace-go.v1/internal/appsec/listener"
 	"gopkg.in/DataDog/dd-trace-go.v1/internal/appsec/trace"
 	"gopkg.in/DataDog/dd-trace-go.v1/internal/appsec/trace/httptrace"
 	"gopkg.in/DataDog/dd-trace-go.v1/internal/log"
+	"gopkg.in/DataDog/dd-trace-go.v1/internal/stacktrace"
 
 	"github.com/DataDog/appsec-internal-go/netip"
 )
@@ -56,6 +58,33 @@ func ExecuteSDKBodyOperation(parent dyngo.Operation, args types.SDKBodyOperation
 	return err
 }
 
+// MonitorPathParams starts and finishes the path parameters operation of the
+// request, for the frameworks only knowing them once the request is routed,
+// after the request monitoring started. An error is returned if the request
+// must be blocked.
+func MonitorPathParams(ctx context.Context, pathParams map[string]string) error {
+	parent := fromContext(ctx)
+	if parent == nil {
+		log.Error("appsec: path parameters monitoring ignored: could not find the http handler instrumentation metadata in the request context: the request handler is not being monitored by a middleware function or the provided context is not the expected request context")
+		return nil
+	}
+
+	return ExecutePathParamsOperation(parent, types.PathParamsOperationArgs{PathParams: pathParams})
+}
+
+// ExecutePathParamsOperation starts and finishes the path parameters operation by emitting a dyngo start and finish
+// events. An error is returned if the request must be blocked.
+func ExecutePathParamsOperation(parent dyngo.Operation, args types.PathParamsOperationArgs) error {
+	var err error
+	op := &types.PathParamsOperation{Operation: dyngo.NewOperation(parent)}
+	dyngo.OnData(op, func(e error) {
+		err = e
+	})
+	dyngo.StartOperation(op, args)
+	dyngo.FinishOperation(op, types.PathParamsOperationRes{})
+	return err
+}
+
 // WrapHandler wraps the given HTTP handler with the abstract HTTP operation defined by HandlerOperationArgs and
 // HandlerOperationRes.
 // The onBlock params are used to cleanup the context when needed.
@@ -78,20 +107,37 @@ func WrapHandler(handler http.Handler, span ddtrace.Span, pathParams map[string]
 
 		var bypassHandler http.Handler
 		var blocking bool
+		stackTraces := stacktrace.NewCollector(span)
+		var bodyRecorder *responseBodyRecorder
+		var sinkEvents *eventsink.Collector
 		args := MakeHandlerOperationArgs(r, clientIP, pathParams)
+		args.Route = opts.Route
 		ctx, op := StartOperation(r.Context(), args, func(op *types.Operation) {
+			sinkEvents = eventsink.NewCollector(op)
 			dyngo.OnData(op, func(a *sharedsec.HTTPAction) {
 				blocking = true
 				bypassHandler = a.Handler
 			})
 			dyngo.OnData(op, func(a *sharedsec.StackTraceAction) {
-				// TODO: do something with the stacktrace
+				stackTraces.Add(&a.Event)
+			})
+			dyngo.OnData(op, func(c *types.ResponseBodyCapture) {
+				bodyRecorder = newResponseBodyRecorder(c.MaxSize)
 			})
 		})
+		handlerWriter := w
+		if bodyRecorder != nil {
+			ctx = context.WithValue(ctx, responseBodyRecorderKey{}, bodyRecorder)
+			handlerWriter = WrapResponseBodyWriter(w, bodyRecorder)
+		}
 		r = r.WithContext(ctx)
 
 		defer func() {
-			events := op.Finish(MakeHandlerOperationRes(w))
+			res := MakeHandlerOperationRes(w)
+			if bodyRecorder != nil {
+				res.Body = bodyRecorder.body(w.Header())
+			}
+			events := op.Finish(res)
 
 			// Execute the onBlock functions to make sure blocking works properly
 			// in case we are instrumenting the Gin framework
@@ -115,13 +161,16 @@ func WrapHandler(handler http.Handler, span ddtrace.Span, pathParams map[string]
 			if len(events) > 0 {
 				httptrace.SetSecurityEventsTags(span, events)
 			}
+			stackTraces.AddToSpan()
+			sinkEvents.Export(span, clientIP, blocking)
 		}()
 
 		if bypassHandler != nil {
 			handler = bypassHandler
 			bypassHandler = nil
+			handlerWriter = w
 		}
-		handler.ServeHTTP(w, r)
+		handler.ServeHTTP(handlerWriter, r)
 	})
 }
 
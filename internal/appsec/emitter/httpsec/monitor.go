@@ -0,0 +1,97 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2024 Datadog, Inc.
+
+package httpsec
+
+import (
+	"context"
+	"net/http"
+	"sync"
+
+	"gopkg.in/DataDog/dd-trace-go.v1/ddtrace"
+	"gopkg.in/DataDog/dd-trace-go.v1/internal/appsec/dyngo"
+	"gopkg.in/DataDog/dd-trace-go.v1/internal/appsec/emitter/httpsec/types"
+	"gopkg.in/DataDog/dd-trace-go.v1/internal/appsec/emitter/sharedsec"
+	"gopkg.in/DataDog/dd-trace-go.v1/internal/appsec/eventsink"
+	"gopkg.in/DataDog/dd-trace-go.v1/internal/appsec/trace"
+	"gopkg.in/DataDog/dd-trace-go.v1/internal/appsec/trace/httptrace"
+	"gopkg.in/DataDog/dd-trace-go.v1/internal/stacktrace"
+
+	"github.com/DataDog/appsec-internal-go/netip"
+)
+
+// RequestMonitor monitors a request served by an HTTP framework which is not
+// based on net/http, and which therefore cannot use WrapHandler. It must be
+// created with StartRequestMonitor and finished with Finish once the request
+// handler returned.
+type RequestMonitor struct {
+	op              *types.Operation
+	span            ddtrace.Span
+	headers         map[string][]string
+	stackTraces     *stacktrace.Collector
+	sinkEvents      *eventsink.Collector
+	clientIP        netip.Addr
+	blocked         bool
+	blockingHandler http.Handler
+	mu              sync.Mutex
+}
+
+// StartRequestMonitor starts the HTTP handler operation of the request
+// described by args and tags the given span as monitored by AppSec. The
+// returned context holds the operation and must be used as the request context
+// so that the AppSec SDK and RASP can find it.
+func StartRequestMonitor(ctx context.Context, span ddtrace.Span, args types.HandlerOperationArgs) (context.Context, *RequestMonitor) {
+	m := &RequestMonitor{span: span, headers: args.Headers, clientIP: args.ClientIP, stackTraces: stacktrace.NewCollector(span)}
+	trace.SetAppSecEnabledTags(span)
+	ctx, m.op = StartOperation(ctx, args, func(op *types.Operation) {
+		m.sinkEvents = eventsink.NewCollector(op)
+		dyngo.OnData(op, func(a *sharedsec.HTTPAction) {
+			m.mu.Lock()
+			defer m.mu.Unlock()
+			m.blocked = true
+			m.blockingHandler = a.Handler
+		})
+		dyngo.OnData(op, func(a *sharedsec.StackTraceAction) {
+			m.stackTraces.Add(&a.Event)
+		})
+	})
+	return ctx, m
+}
+
+// BlockingHandler returns the net/http handler writing the blocking response
+// of the request when the request got blocked since the last call, and nil
+// otherwise. The request handler must not be called, or must stop as soon as
+// possible, when a blocking handler is returned.
+func (m *RequestMonitor) BlockingHandler() http.Handler {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	h := m.blockingHandler
+	m.blockingHandler = nil
+	return h
+}
+
+// Blocked returns true when the request got blocked.
+func (m *RequestMonitor) Blocked() bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.blocked
+}
+
+// Finish finishes the HTTP handler operation along with the given response,
+// and tags the span with the resulting security events.
+func (m *RequestMonitor) Finish(res types.HandlerOperationRes) {
+	events := m.op.Finish(res)
+	if m.Blocked() {
+		m.op.SetTag(trace.BlockedRequestTag, true)
+	}
+	setRequestHeadersTags(m.span, m.headers)
+	setResponseHeadersTags(m.span, res.Headers)
+	trace.SetTags(m.span, m.op.Tags())
+	if len(events) > 0 {
+		httptrace.SetSecurityEventsTags(m.span, events)
+	}
+	m.stackTraces.AddToSpan()
+	m.sinkEvents.Export(m.span, m.clientIP, m.Blocked())
+}
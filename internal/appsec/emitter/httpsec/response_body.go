@@ -0,0 +1,187 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2024 Datadog, Inc.
+
+package httpsec
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"mime"
+	"net/http"
+	"strings"
+	"sync"
+
+	"gopkg.in/DataDog/dd-trace-go.v1/internal/log"
+)
+
+// responseBodyRecorder records the response body of a request sampled by API
+// Security, up to a maximum size. Larger response bodies are dropped as they
+// couldn't be parsed anyway.
+type responseBodyRecorder struct {
+	buf       bytes.Buffer
+	maxSize   int
+	truncated bool
+	mu        sync.Mutex
+}
+
+func newResponseBodyRecorder(maxSize int) *responseBodyRecorder {
+	return &responseBodyRecorder{maxSize: maxSize}
+}
+
+// Write records the given response body chunk. It never fails so that it can
+// be used to tee the response body.
+func (r *responseBodyRecorder) Write(b []byte) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.truncated {
+		return len(b), nil
+	}
+	if r.buf.Len()+len(b) > r.maxSize {
+		r.truncated = true
+		r.buf = bytes.Buffer{}
+		return len(b), nil
+	}
+	r.buf.Write(b)
+	return len(b), nil
+}
+
+// body returns the parsed response body when it was fully recorded and is
+// JSON according to the given response headers, and nil otherwise.
+func (r *responseBodyRecorder) body(headers http.Header) any {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.truncated || r.buf.Len() == 0 || !isJSONContentType(headers.Get("Content-Type")) {
+		return nil
+	}
+	if enc := headers.Get("Content-Encoding"); enc != "" && enc != "identity" {
+		return nil
+	}
+	var body any
+	if err := json.Unmarshal(r.buf.Bytes(), &body); err != nil {
+		log.Debug("appsec: could not parse the JSON response body: %v", err)
+		return nil
+	}
+	return body
+}
+
+// isJSONContentType returns true when the given content type is
+// application/json or any +json structured syntax suffix media type.
+func isJSONContentType(contentType string) bool {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return false
+	}
+	return mediaType == "application/json" || strings.HasSuffix(mediaType, "+json")
+}
+
+type responseBodyRecorderKey struct{}
+
+// ResponseBodyRecorder returns the writer recording the response body of the
+// request monitored in the given context when API Security sampled it for
+// response body analysis, and nil otherwise. WrapHandler already records the
+// response body written through its http.ResponseWriter, so this is only needed
+// by integrations whose request handlers write the response through another
+// response writer, which must then tee the response body to the returned
+// writer.
+func ResponseBodyRecorder(ctx context.Context) io.Writer {
+	if rec, ok := ctx.Value(responseBodyRecorderKey{}).(*responseBodyRecorder); ok {
+		return rec
+	}
+	return nil
+}
+
+// ResponseBodyWriter is an http.ResponseWriter wrapping another one in order to
+// tee the response body written through it to a response body recorder, see
+// ResponseBodyRecorder. It does not implement the optional interfaces of the
+// wrapped response writer, such as http.Flusher, which WrapResponseBodyWriter
+// does.
+type ResponseBodyWriter struct {
+	http.ResponseWriter
+	recorder io.Writer
+}
+
+// NewResponseBodyWriter returns a response writer wrapping w in order to tee
+// the response body written through it to the given response body recorder.
+func NewResponseBodyWriter(w http.ResponseWriter, recorder io.Writer) *ResponseBodyWriter {
+	return &ResponseBodyWriter{ResponseWriter: w, recorder: recorder}
+}
+
+// WrapResponseBodyWriter returns a response writer wrapping w in order to tee
+// the response body written through it to the given response body recorder,
+// like NewResponseBodyWriter. The returned response writer also implements the
+// http.Flusher, http.Pusher and http.Hijacker interfaces implemented by w, and
+// only them.
+func WrapResponseBodyWriter(w http.ResponseWriter, recorder io.Writer) http.ResponseWriter {
+	hFlusher, okFlusher := w.(http.Flusher)
+	hPusher, okPusher := w.(http.Pusher)
+	hHijacker, okHijacker := w.(http.Hijacker)
+
+	bw := NewResponseBodyWriter(w, recorder)
+	switch {
+	case okFlusher && okPusher && okHijacker:
+		return struct {
+			*ResponseBodyWriter
+			http.Flusher
+			http.Pusher
+			http.Hijacker
+		}{bw, hFlusher, hPusher, hHijacker}
+	case okFlusher && okPusher:
+		return struct {
+			*ResponseBodyWriter
+			http.Flusher
+			http.Pusher
+		}{bw, hFlusher, hPusher}
+	case okFlusher && okHijacker:
+		return struct {
+			*ResponseBodyWriter
+			http.Flusher
+			http.Hijacker
+		}{bw, hFlusher, hHijacker}
+	case okPusher && okHijacker:
+		return struct {
+			*ResponseBodyWriter
+			http.Pusher
+			http.Hijacker
+		}{bw, hPusher, hHijacker}
+	case okFlusher:
+		return struct {
+			*ResponseBodyWriter
+			http.Flusher
+		}{bw, hFlusher}
+	case okPusher:
+		return struct {
+			*ResponseBodyWriter
+			http.Pusher
+		}{bw, hPusher}
+	case okHijacker:
+		return struct {
+			*ResponseBodyWriter
+			http.Hijacker
+		}{bw, hHijacker}
+	default:
+		return bw
+	}
+}
+
+// Write implements http.ResponseWriter.
+func (w *ResponseBodyWriter) Write(b []byte) (int, error) {
+	n, err := w.ResponseWriter.Write(b)
+	w.recorder.Write(b[:n])
+	return n, err
+}
+
+// WriteString implements io.StringWriter.
+func (w *ResponseBodyWriter) WriteString(s string) (int, error) {
+	n, err := io.WriteString(w.ResponseWriter, s)
+	io.WriteString(w.recorder, s[:n])
+	return n, err
+}
+
+// Unwrap returns the wrapped response writer for http.ResponseController.
+func (w *ResponseBodyWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
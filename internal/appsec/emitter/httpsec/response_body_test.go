@@ -0,0 +1,58 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2024 Datadog, Inc.
+
+package httpsec
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// plainResponseWriter is an http.ResponseWriter implementing none of the
+// optional response writer interfaces.
+type plainResponseWriter struct {
+	http.ResponseWriter
+}
+
+func TestWrapResponseBodyWriter(t *testing.T) {
+	t.Run("flusher", func(t *testing.T) {
+		rec := httptest.NewRecorder()
+		var body bytes.Buffer
+		w := WrapResponseBodyWriter(rec, &body)
+		require.Implements(t, (*http.Flusher)(nil), w)
+		_, okHijacker := w.(http.Hijacker)
+		require.False(t, okHijacker)
+		_, okPusher := w.(http.Pusher)
+		require.False(t, okPusher)
+
+		io.WriteString(w, "hello ")
+		w.Write([]byte("world"))
+		w.(http.Flusher).Flush()
+		require.True(t, rec.Flushed)
+		require.Equal(t, "hello world", rec.Body.String())
+		require.Equal(t, "hello world", body.String())
+	})
+
+	t.Run("none", func(t *testing.T) {
+		rec := httptest.NewRecorder()
+		var body bytes.Buffer
+		w := WrapResponseBodyWriter(plainResponseWriter{rec}, &body)
+		_, okFlusher := w.(http.Flusher)
+		require.False(t, okFlusher)
+		_, okHijacker := w.(http.Hijacker)
+		require.False(t, okHijacker)
+		_, okPusher := w.(http.Pusher)
+		require.False(t, okPusher)
+
+		w.Write([]byte("hello"))
+		require.Equal(t, "hello", rec.Body.String())
+		require.Equal(t, "hello", body.String())
+	})
+}
@@ -0,0 +1,36 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2024 Datadog, Inc.
+
+package httpsec
+
+import (
+	"context"
+
+	"gopkg.in/DataDog/dd-trace-go.v1/internal/appsec/dyngo"
+	"gopkg.in/DataDog/dd-trace-go.v1/internal/appsec/emitter/httpsec/types"
+)
+
+// ProtectRoundTrip starts and finishes the round trip operation of an outgoing
+// HTTP request to the given URL, so that RASP can check it for Server-Side
+// Request Forgery (SSRF). The operation is a child of the HTTP handler
+// operation found in the given context, so that the WAF can correlate the URL
+// with the user input of the current request. Nothing is done when the context
+// holds no HTTP handler operation.
+// A *events.BlockingSecurityEvent error is returned when the request must be
+// blocked, in which case the caller must not send it.
+func ProtectRoundTrip(ctx context.Context, url string) error {
+	parent := fromContext(ctx)
+	if parent == nil {
+		// The outgoing request is not made while serving a monitored request
+		return nil
+	}
+
+	var err error
+	op := &types.RoundTripOperation{Operation: dyngo.NewOperation(parent)}
+	dyngo.OnData(op, func(e error) { err = e })
+	dyngo.StartOperation(op, types.RoundTripOperationArgs{URL: url})
+	dyngo.FinishOperation(op, types.RoundTripOperationRes{})
+	return err
+}
@@ -27,6 +27,12 @@ type (
 	SDKBodyOperation struct {
 		dyngo.Operation
 	}
+
+	// PathParamsOperation type representing the path parameters of a request
+	// only known once it is routed, after the handler operation started.
+	PathParamsOperation struct {
+		dyngo.Operation
+	}
 )
 
 // Finish the HTTP handler operation, along with the given results and emits a
@@ -54,6 +60,9 @@ type (
 		Method string
 		// RequestURI corresponds to the address `server.request.uri.raw`
 		RequestURI string
+		// Route is the matched route of the request, if known. It identifies the API endpoint of the request for
+		// API Security sampling and doesn't correspond to any address.
+		Route string
 	}
 
 	// HandlerOperationRes is the HTTP handler operation results.
@@ -61,6 +70,16 @@ type (
 		Headers map[string][]string
 		// Status corresponds to the address `server.response.status`.
 		Status int
+		// Body corresponds to the address `server.response.body`. It is the parsed JSON response body, only set when
+		// the response body got captured after a ResponseBodyCapture request.
+		Body any
+	}
+
+	// ResponseBodyCapture is emitted on the HTTP handler operation at its start when the response body must be
+	// captured for API Security, so that the WAF can extract its schema and scan it for sensitive data.
+	ResponseBodyCapture struct {
+		// MaxSize is the maximum size of the response body to capture. Larger response bodies are ignored.
+		MaxSize int
 	}
 
 	// SDKBodyOperationArgs is the SDK body operation arguments.
@@ -72,6 +91,15 @@ type (
 	// SDKBodyOperationRes is the SDK body operation results.
 	SDKBodyOperationRes struct{}
 
+	// PathParamsOperationArgs is the path parameters operation arguments.
+	PathParamsOperationArgs struct {
+		// PathParams corresponds to the address `server.request.path_params`.
+		PathParams map[string]string
+	}
+
+	// PathParamsOperationRes is the path parameters operation results.
+	PathParamsOperationRes struct{}
+
 	// MonitoringError is used to vehicle an HTTP error, usually resurfaced through Appsec SDKs.
 	MonitoringError struct {
 		msg string
@@ -99,5 +127,31 @@ func NewMonitoringError(msg string) error {
 func (SDKBodyOperationArgs) IsArgOf(*SDKBodyOperation)   {}
 func (SDKBodyOperationRes) IsResultOf(*SDKBodyOperation) {}
 
+func (PathParamsOperationArgs) IsArgOf(*PathParamsOperation)   {}
+func (PathParamsOperationRes) IsResultOf(*PathParamsOperation) {}
+
 func (HandlerOperationArgs) IsArgOf(*Operation)   {}
 func (HandlerOperationRes) IsResultOf(*Operation) {}
+
+// Abstract outgoing HTTP request operation definition, used by RASP to detect
+// Server-Side Request Forgery (SSRF).
+type (
+	// RoundTripOperation type representing an outgoing HTTP request made by an
+	// instrumented HTTP client. It gets both created and finished in a single
+	// call to httpsec.ProtectRoundTrip.
+	RoundTripOperation struct {
+		dyngo.Operation
+	}
+
+	// RoundTripOperationArgs is the round trip operation arguments.
+	RoundTripOperationArgs struct {
+		// URL corresponds to the address `server.io.net.url`.
+		URL string
+	}
+
+	// RoundTripOperationRes is the round trip operation results.
+	RoundTripOperationRes struct{}
+)
+
+func (RoundTripOperationArgs) IsArgOf(*RoundTripOperation)   {}
+func (RoundTripOperationRes) IsResultOf(*RoundTripOperation) {}
@@ -0,0 +1,99 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2024 Datadog, Inc.
+
+// Package messagingsec is the message queue instrumentation API and contract
+// for AppSec, defining an abstract run-time representation of the consumption
+// of messages. Message queue consumer integrations, such as Kafka or Pub/Sub,
+// must use this package to enable AppSec features for the messages they
+// consume, which listens to this package's operation events.
+// Messages are monitored only: the security events are reported in the
+// consumer span but the messages cannot be blocked.
+package messagingsec
+
+import (
+	"bytes"
+	"encoding/json"
+	"mime"
+	"strings"
+
+	"gopkg.in/DataDog/dd-trace-go.v1/ddtrace"
+	"gopkg.in/DataDog/dd-trace-go.v1/internal/appsec/dyngo"
+	"gopkg.in/DataDog/dd-trace-go.v1/internal/appsec/emitter/messagingsec/types"
+	"gopkg.in/DataDog/dd-trace-go.v1/internal/appsec/eventsink"
+	"gopkg.in/DataDog/dd-trace-go.v1/internal/appsec/trace"
+	"gopkg.in/DataDog/dd-trace-go.v1/internal/log"
+
+	"github.com/DataDog/appsec-internal-go/netip"
+)
+
+// StartConsumeOperation starts a message consumption operation, along with the
+// given arguments, and emits a start event up in the operation stack. The
+// operation is linked to the global root operation since message consumption
+// is always expected to be first in the operation stack.
+func StartConsumeOperation(args types.ConsumeOperationArgs, setup ...func(*types.ConsumeOperation)) *types.ConsumeOperation {
+	op := &types.ConsumeOperation{
+		Operation:  dyngo.NewOperation(nil),
+		TagsHolder: trace.NewTagsHolder(),
+	}
+	for _, cb := range setup {
+		cb(op)
+	}
+	dyngo.StartOperation(op, args)
+	return op
+}
+
+// MonitorConsumedMessage monitors the consumed message described by args and
+// tags the given consumer span with the resulting security events. It should
+// not be called when AppSec is disabled.
+func MonitorConsumedMessage(span ddtrace.Span, args types.ConsumeOperationArgs) {
+	trace.SetAppSecEnabledTags(span)
+	var sinkEvents *eventsink.Collector
+	op := StartConsumeOperation(args, func(op *types.ConsumeOperation) {
+		sinkEvents = eventsink.NewCollector(op)
+	})
+	events := op.Finish(types.ConsumeOperationRes{})
+	trace.SetTags(span, op.Tags())
+	if err := trace.SetEventSpanTags(span, events); err != nil {
+		log.Error("appsec: unexpected error while creating the appsec events tags: %v", err)
+	}
+	sinkEvents.Export(span, netip.Addr{}, false)
+}
+
+// maxPayloadSize is the maximum size of the message payloads decoded by
+// DecodePayload. Larger payloads are not monitored as decoding them would slow
+// down the message consumption too much.
+const maxPayloadSize = 64 * 1024
+
+// DecodePayload returns the given message payload decoded as JSON when it is
+// JSON, and as a string otherwise. The payload is JSON when the content-type
+// header of the given lowercase message headers says so or, without such a
+// header, when it is a JSON object or array. Empty payloads and payloads larger
+// than 64KiB are not decoded and nil is returned.
+func DecodePayload(payload []byte, headers map[string][]string) any {
+	if len(payload) == 0 || len(payload) > maxPayloadSize {
+		return nil
+	}
+	if isJSONPayload(payload, headers["content-type"]) {
+		var decoded any
+		if err := json.Unmarshal(payload, &decoded); err == nil {
+			return decoded
+		}
+	}
+	return string(payload)
+}
+
+// isJSONPayload returns true when the given content type values say the payload
+// is JSON or, when there are none, when the payload is a JSON object or array.
+func isJSONPayload(payload []byte, contentType []string) bool {
+	if len(contentType) > 0 {
+		mediaType, _, err := mime.ParseMediaType(contentType[0])
+		if err != nil {
+			return false
+		}
+		return mediaType == "application/json" || strings.HasSuffix(mediaType, "+json")
+	}
+	payload = bytes.TrimLeft(payload, " \t\r\n")
+	return len(payload) > 0 && (payload[0] == '{' || payload[0] == '[')
+}
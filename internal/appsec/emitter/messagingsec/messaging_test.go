@@ -0,0 +1,55 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2024 Datadog, Inc.
+
+package messagingsec
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestDecodePayload(t *testing.T) {
+	large := []byte(`["` + strings.Repeat("a", maxPayloadSize) + `"]`)
+	for _, tc := range []struct {
+		name     string
+		payload  []byte
+		headers  map[string][]string
+		expected any
+	}{
+		{name: "nil"},
+		{name: "empty", payload: []byte{}},
+		{name: "json-object", payload: []byte(`{"name":"jane","age":42}`), expected: map[string]any{"name": "jane", "age": 42.0}},
+		{name: "json-array", payload: []byte(` [1,"two"]`), expected: []any{1.0, "two"}},
+		{name: "json-string", payload: []byte(`"hello"`), expected: `"hello"`},
+		{name: "json-null", payload: []byte(`null`), expected: "null"},
+		{name: "text", payload: []byte("hello world"), expected: "hello world"},
+		{name: "truncated-json", payload: []byte(`{"name":`), expected: `{"name":`},
+		{name: "too-large", payload: large},
+		{
+			name:     "json-content-type",
+			payload:  []byte(`"hello"`),
+			headers:  map[string][]string{"content-type": {"application/json; charset=utf-8"}},
+			expected: "hello",
+		},
+		{
+			name:     "json-suffix-content-type",
+			payload:  []byte(`null`),
+			headers:  map[string][]string{"content-type": {"application/vnd.api+json"}},
+			expected: nil,
+		},
+		{
+			name:     "text-content-type",
+			payload:  []byte(`{"name":"jane"}`),
+			headers:  map[string][]string{"content-type": {"text/plain"}},
+			expected: `{"name":"jane"}`,
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			require.Equal(t, tc.expected, DecodePayload(tc.payload, tc.headers))
+		})
+	}
+}
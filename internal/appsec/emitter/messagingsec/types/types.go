@@ -0,0 +1,51 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2024 Datadog, Inc.
+
+package types
+
+import (
+	"gopkg.in/DataDog/dd-trace-go.v1/internal/appsec/dyngo"
+	"gopkg.in/DataDog/dd-trace-go.v1/internal/appsec/trace"
+)
+
+type (
+	// ConsumeOperation represents the consumption of a message from a message
+	// queue, such as Kafka or Pub/Sub. It must be created with
+	// StartConsumeOperation() and finished with its Finish() method.
+	ConsumeOperation struct {
+		dyngo.Operation
+		trace.TagsHolder
+		trace.SecurityEventsHolder
+	}
+
+	// ConsumeOperationArgs is the message consumption arguments.
+	ConsumeOperationArgs struct {
+		// System is the messaging system the message was consumed from, such
+		// as kafka or gcp_pubsub.
+		System string
+		// Topic is the topic, or subscription, the message was consumed from.
+		Topic string
+		// Headers are the message headers, or attributes, with lowercase keys.
+		// Corresponds to the address `server.request.headers.no_cookies`.
+		Headers map[string][]string
+		// Payload is the decoded message payload.
+		// Corresponds to the address `server.request.body`.
+		Payload any
+	}
+
+	// ConsumeOperationRes is the message consumption results. Empty as of
+	// today.
+	ConsumeOperationRes struct{}
+)
+
+// Finish the message consumption operation and return the security events
+// detected while monitoring the message.
+func (op *ConsumeOperation) Finish(res ConsumeOperationRes) []any {
+	dyngo.FinishOperation(op, res)
+	return op.Events()
+}
+
+func (ConsumeOperationArgs) IsArgOf(*ConsumeOperation)   {}
+func (ConsumeOperationRes) IsResultOf(*ConsumeOperation) {}
@@ -0,0 +1,42 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2024 Datadog, Inc.
+
+// Package ossec defines the filesystem instrumentation API and contract for
+// AppSec. Filesystem integrations must use this package to enable RASP Local
+// File Inclusion (LFI) detection, which is performed by the listeners of the
+// request operation the file is opened in.
+package ossec
+
+import (
+	"context"
+	"io/fs"
+
+	"gopkg.in/DataDog/dd-trace-go.v1/internal/appsec/dyngo"
+	"gopkg.in/DataDog/dd-trace-go.v1/internal/appsec/emitter/ossec/types"
+	"gopkg.in/DataDog/dd-trace-go.v1/internal/appsec/listener"
+)
+
+// ProtectOpen starts and finishes the file opening operation of the given
+// path, with the given flag and permission as given to os.OpenFile, so that
+// RASP can check it for Local File Inclusion (LFI). The operation is a child
+// of the request operation found in the given context, so that the WAF can
+// correlate the path with the user input of the current request. Nothing is
+// done when the context holds no request operation.
+// A *events.BlockingSecurityEvent error is returned when the file must not be
+// opened.
+func ProtectOpen(ctx context.Context, path string, flag int, perm fs.FileMode) error {
+	parent, ok := ctx.Value(listener.ContextKey{}).(dyngo.Operation)
+	if !ok {
+		// The file is not opened while serving a monitored request
+		return nil
+	}
+
+	var err error
+	op := &types.OpenOperation{Operation: dyngo.NewOperation(parent)}
+	dyngo.OnData(op, func(e error) { err = e })
+	dyngo.StartOperation(op, types.OpenOperationArgs{Path: path, Flag: flag, Perm: perm})
+	dyngo.FinishOperation(op, types.OpenOperationRes{})
+	return err
+}
@@ -0,0 +1,39 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2024 Datadog, Inc.
+
+package types
+
+import (
+	"io/fs"
+
+	"gopkg.in/DataDog/dd-trace-go.v1/internal/appsec/dyngo"
+)
+
+// Abstract file opening operation definition, used by RASP to detect Local
+// File Inclusions (LFI).
+type (
+	// OpenOperation type representing a file about to be opened by an
+	// instrumented filesystem API. It gets both created and finished in a
+	// single call to ossec.ProtectOpen.
+	OpenOperation struct {
+		dyngo.Operation
+	}
+
+	// OpenOperationArgs is the file opening operation arguments.
+	OpenOperationArgs struct {
+		// Path corresponds to the address `server.io.fs.file`.
+		Path string
+		// Flag is the flag the file is opened with, as given to os.OpenFile.
+		Flag int
+		// Perm is the permission the file is opened with, as given to os.OpenFile.
+		Perm fs.FileMode
+	}
+
+	// OpenOperationRes is the file opening operation results.
+	OpenOperationRes struct{}
+)
+
+func (OpenOperationArgs) IsArgOf(*OpenOperation)   {}
+func (OpenOperationRes) IsResultOf(*OpenOperation) {}
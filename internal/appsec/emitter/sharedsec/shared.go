@@ -10,6 +10,7 @@ import (
 	"reflect"
 
 	"gopkg.in/DataDog/dd-trace-go.v1/internal/appsec/dyngo"
+	"gopkg.in/DataDog/dd-trace-go.v1/internal/appsec/eventsink"
 	"gopkg.in/DataDog/dd-trace-go.v1/internal/appsec/listener"
 	"gopkg.in/DataDog/dd-trace-go.v1/internal/log"
 )
@@ -41,6 +42,7 @@ func ExecuteUserIDOperation(parent dyngo.Operation, args UserIDOperationArgs) er
 	op := &UserIDOperation{Operation: dyngo.NewOperation(parent)}
 	dyngo.OnData(op, func(e error) { err = e })
 	dyngo.StartOperation(op, args)
+	eventsink.EmitUser(op, args.UserID)
 	dyngo.FinishOperation(op, UserIDOperationRes{})
 	return err
 }
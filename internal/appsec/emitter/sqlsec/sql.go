@@ -0,0 +1,41 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2024 Datadog, Inc.
+
+// Package sqlsec defines the SQL instrumentation API and contract for AppSec.
+// SQL integrations must use this package to enable RASP SQL injection
+// detection, which is performed by the listeners of the request operation the
+// query is executed in.
+package sqlsec
+
+import (
+	"context"
+
+	"gopkg.in/DataDog/dd-trace-go.v1/internal/appsec/dyngo"
+	"gopkg.in/DataDog/dd-trace-go.v1/internal/appsec/emitter/sqlsec/types"
+	"gopkg.in/DataDog/dd-trace-go.v1/internal/appsec/listener"
+)
+
+// ProtectSQLOperation starts and finishes the SQL operation of the given query
+// about to be executed against a database of the given system (cf.
+// ext.DBSystem), so that RASP can check it for SQL injections. The operation
+// is a child of the request operation found in the given context, so that the
+// WAF can correlate the query with the user input of the current request.
+// Nothing is done when the context holds no request operation.
+// A *events.BlockingSecurityEvent error is returned when the query must be
+// blocked, in which case the caller must not execute it.
+func ProtectSQLOperation(ctx context.Context, query, driver string) error {
+	parent, ok := ctx.Value(listener.ContextKey{}).(dyngo.Operation)
+	if !ok {
+		// The query is not executed while serving a monitored request
+		return nil
+	}
+
+	var err error
+	op := &types.SQLOperation{Operation: dyngo.NewOperation(parent)}
+	dyngo.OnData(op, func(e error) { err = e })
+	dyngo.StartOperation(op, types.SQLOperationArgs{Query: query, Driver: driver})
+	dyngo.FinishOperation(op, types.SQLOperationRes{})
+	return err
+}
@@ -0,0 +1,35 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2024 Datadog, Inc.
+
+package types
+
+import (
+	"gopkg.in/DataDog/dd-trace-go.v1/internal/appsec/dyngo"
+)
+
+// Abstract SQL query operation definition, used by RASP to detect SQL
+// injections.
+type (
+	// SQLOperation type representing an SQL query about to be executed by an
+	// instrumented database client. It gets both created and finished in a
+	// single call to sqlsec.ProtectSQLOperation.
+	SQLOperation struct {
+		dyngo.Operation
+	}
+
+	// SQLOperationArgs is the SQL operation arguments.
+	SQLOperationArgs struct {
+		// Query corresponds to the address `server.db.statement`.
+		Query string
+		// Driver corresponds to the address `server.db.system`.
+		Driver string
+	}
+
+	// SQLOperationRes is the SQL operation results.
+	SQLOperationRes struct{}
+)
+
+func (SQLOperationArgs) IsArgOf(*SQLOperation)   {}
+func (SQLOperationRes) IsResultOf(*SQLOperation) {}
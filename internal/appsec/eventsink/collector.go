@@ -0,0 +1,154 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2024 Datadog, Inc.
+
+package eventsink
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"gopkg.in/DataDog/dd-trace-go.v1/ddtrace"
+	"gopkg.in/DataDog/dd-trace-go.v1/internal/appsec/dyngo"
+
+	"github.com/DataDog/appsec-internal-go/netip"
+)
+
+// WAFEvents are the security events returned by a WAF run. They are emitted up
+// the operation stack before being rate limited for the traces, so that every
+// WAF match gets exported.
+type WAFEvents struct {
+	Events []any
+}
+
+// EmitEvents emits the given WAF events up the operation stack of op when the
+// events are being exported.
+func EmitEvents(op dyngo.Operation, events []any) {
+	if len(events) == 0 || !Enabled() {
+		return
+	}
+	dyngo.EmitData(op, &WAFEvents{Events: events})
+}
+
+// user is the user ID emitted up the operation stack when the user of a
+// request is set.
+type user struct {
+	id string
+}
+
+// EmitUser emits the given user ID up the operation stack of op when the
+// security events are being exported.
+func EmitUser(op dyngo.Operation, userID string) {
+	if !Enabled() {
+		return
+	}
+	dyngo.EmitData(op, &user{id: userID})
+}
+
+// Collector collects the WAF events and the user of a monitored request so
+// that they can be exported once the request is over.
+type Collector struct {
+	events []any
+	userID string
+	mu     sync.Mutex
+}
+
+// NewCollector returns a collector listening to the WAF events and user IDs
+// emitted up the operation stack of the given request operation, or nil when
+// the security events are not being exported. It must be called before
+// starting the operation.
+func NewCollector(op dyngo.Operation) *Collector {
+	if !Enabled() {
+		return nil
+	}
+	c := &Collector{}
+	dyngo.OnData(op, func(e *WAFEvents) {
+		c.mu.Lock()
+		defer c.mu.Unlock()
+		c.events = append(c.events, e.Events...)
+	})
+	dyngo.OnData(op, func(u *user) {
+		c.mu.Lock()
+		defer c.mu.Unlock()
+		c.userID = u.id
+	})
+	return c
+}
+
+// Export exports the collected WAF matches along with the given span, client
+// IP and blocking outcome of the request. It does nothing on a nil collector.
+func (c *Collector) Export(span ddtrace.Span, clientIP netip.Addr, blocked bool) {
+	if c == nil {
+		return
+	}
+	c.mu.Lock()
+	events, userID := c.events, c.userID
+	c.events = nil
+	c.mu.Unlock()
+	if len(events) == 0 {
+		return
+	}
+
+	template := Event{
+		Timestamp: time.Now().UTC(),
+		UserID:    userID,
+		Blocked:   blocked,
+	}
+	if clientIP.IsValid() {
+		template.ClientIP = clientIP.String()
+	}
+	if span != nil {
+		template.TraceID = span.Context().TraceID()
+		template.SpanID = span.Context().SpanID()
+	}
+	var matches []*Event
+	for _, e := range events {
+		matches = append(matches, makeEvents(template, e)...)
+	}
+	export(matches)
+}
+
+// makeEvents returns an event per rule match parameter of the given WAF event,
+// filled with the given event template.
+func makeEvents(template Event, wafEvent any) []*Event {
+	m, _ := wafEvent.(map[string]any)
+	rule, _ := m["rule"].(map[string]any)
+	template.RuleID = toString(rule["id"])
+	template.RuleName = toString(rule["name"])
+	if tags, ok := rule["tags"].(map[string]any); ok {
+		template.RuleType = toString(tags["type"])
+	}
+
+	var events []*Event
+	ruleMatches, _ := m["rule_matches"].([]any)
+	for _, rm := range ruleMatches {
+		rm, _ := rm.(map[string]any)
+		params, _ := rm["parameters"].([]any)
+		for _, p := range params {
+			p, _ := p.(map[string]any)
+			e := template
+			e.Address = toString(p["address"])
+			e.KeyPath, _ = p["key_path"].([]any)
+			e.Value = toString(p["value"])
+			events = append(events, &e)
+		}
+	}
+	if len(events) == 0 {
+		// Still export the rule match when its parameters are unknown
+		events = append(events, &template)
+	}
+	return events
+}
+
+func toString(v any) string {
+	switch v := v.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	default:
+		return fmt.Sprint(v)
+	}
+}
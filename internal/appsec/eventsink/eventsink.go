@@ -0,0 +1,164 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2024 Datadog, Inc.
+
+// Package eventsink exports the security events detected by the WAF to a local
+// event sink, such as a file or a syslog server, in a structured form meant to
+// be ingested by SIEM tools. The events are exported independently of the
+// security events reported in the traces and of their rate limiting.
+package eventsink
+
+import (
+	"fmt"
+	"net/url"
+	"sync"
+	"time"
+
+	"github.com/DataDog/appsec-internal-go/limiter"
+
+	"gopkg.in/DataDog/dd-trace-go.v1/internal/log"
+)
+
+// Event is a WAF match exported to the event sink.
+type Event struct {
+	Timestamp time.Time `json:"timestamp"`
+	RuleID    string    `json:"rule_id"`
+	RuleName  string    `json:"rule_name,omitempty"`
+	RuleType  string    `json:"rule_type,omitempty"`
+	Address   string    `json:"address,omitempty"`
+	KeyPath   []any     `json:"key_path,omitempty"`
+	Value     string    `json:"value,omitempty"`
+	ClientIP  string    `json:"client_ip,omitempty"`
+	UserID    string    `json:"user_id,omitempty"`
+	Blocked   bool      `json:"blocked"`
+	TraceID   uint64    `json:"trace_id,omitempty"`
+	SpanID    uint64    `json:"span_id,omitempty"`
+}
+
+// Sink receives the security events. Its methods are never called
+// concurrently.
+type Sink interface {
+	// Write exports the given event.
+	Write(e *Event) error
+	// Close releases the resources of the sink once no more events will be
+	// written.
+	Close() error
+}
+
+// Open returns the event sink described by the given URL, either
+// file:///path/to/events.ndjson for a NDJSON file sink or udp://host:port for
+// a syslog sink over UDP.
+func Open(rawURL string) (Sink, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, err
+	}
+	switch u.Scheme {
+	case "file":
+		return NewFileSink(u.Path)
+	case "udp":
+		return NewSyslogSink(u.Host)
+	default:
+		return nil, fmt.Errorf("unsupported event sink scheme `%s`, expected `file` or `udp`", u.Scheme)
+	}
+}
+
+// queueSize is the maximum number of events waiting to be written to the
+// sink. Events are dropped when the sink doesn't keep up.
+const queueSize = 1024
+
+type dispatcher struct {
+	sink        Sink
+	closeOnStop bool
+	limiter     *limiter.TokenTicker
+	queue       chan *Event
+	done        chan struct{}
+}
+
+var (
+	active *dispatcher
+	mu     sync.RWMutex
+)
+
+// Start starts exporting the security events to the given sink, at most
+// rateLimit events per second. The sink is closed by Stop when closeOnStop is
+// true.
+func Start(sink Sink, rateLimit int64, closeOnStop bool) {
+	d := &dispatcher{
+		sink:        sink,
+		closeOnStop: closeOnStop,
+		limiter:     limiter.NewTokenTicker(rateLimit, rateLimit),
+		queue:       make(chan *Event, queueSize),
+		done:        make(chan struct{}),
+	}
+	d.limiter.Start()
+	go d.run()
+
+	mu.Lock()
+	prev := active
+	active = d
+	mu.Unlock()
+	if prev != nil {
+		prev.stop()
+	}
+}
+
+// Stop stops exporting the security events, once the pending ones are written.
+func Stop() {
+	mu.Lock()
+	d := active
+	active = nil
+	mu.Unlock()
+	if d != nil {
+		d.stop()
+	}
+}
+
+// Enabled returns true when the security events are being exported.
+func Enabled() bool {
+	mu.RLock()
+	defer mu.RUnlock()
+	return active != nil
+}
+
+func (d *dispatcher) run() {
+	defer close(d.done)
+	for e := range d.queue {
+		if err := d.sink.Write(e); err != nil {
+			log.Error("appsec: could not write the security event to the event sink: %v", err)
+		}
+	}
+}
+
+func (d *dispatcher) stop() {
+	close(d.queue)
+	<-d.done
+	d.limiter.Stop()
+	if d.closeOnStop {
+		if err := d.sink.Close(); err != nil {
+			log.Error("appsec: could not close the event sink: %v", err)
+		}
+	}
+}
+
+// export queues the given events, within the limits of the rate limiter and of
+// the queue.
+func export(events []*Event) {
+	mu.RLock()
+	defer mu.RUnlock()
+	if active == nil {
+		return
+	}
+	for _, e := range events {
+		if !active.limiter.Allow() {
+			log.Debug("appsec: security event dropped by the event sink rate limiter")
+			continue
+		}
+		select {
+		case active.queue <- e:
+		default:
+			log.Debug("appsec: security event dropped as the event sink queue is full")
+		}
+	}
+}
@@ -0,0 +1,155 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2024 Datadog, Inc.
+
+package eventsink
+
+import (
+	"bufio"
+	"encoding/json"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/DataDog/appsec-internal-go/netip"
+	"github.com/stretchr/testify/require"
+)
+
+type memorySink struct {
+	events []*Event
+	closed bool
+	mu     sync.Mutex
+}
+
+func (s *memorySink) Write(e *Event) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.events = append(s.events, e)
+	return nil
+}
+
+func (s *memorySink) Close() error {
+	s.closed = true
+	return nil
+}
+
+var wafEvent = map[string]any{
+	"rule": map[string]any{
+		"id":   "crs-942-100",
+		"name": "SQL Injection Attack Detected via libinjection",
+		"tags": map[string]any{"type": "sql_injection", "category": "attack_attempt"},
+	},
+	"rule_matches": []any{
+		map[string]any{
+			"operator": "is_sqli",
+			"parameters": []any{
+				map[string]any{
+					"address":  "server.request.query",
+					"key_path": []any{"id", "0"},
+					"value":    "1' OR 1=1--",
+				},
+			},
+		},
+	},
+}
+
+func TestExport(t *testing.T) {
+	sink := &memorySink{}
+	Start(sink, 2, true)
+	require.True(t, Enabled())
+
+	template := Event{ClientIP: "1.2.3.4", Blocked: true}
+	events := makeEvents(template, wafEvent)
+	require.Equal(t, []*Event{{
+		RuleID:   "crs-942-100",
+		RuleName: "SQL Injection Attack Detected via libinjection",
+		RuleType: "sql_injection",
+		Address:  "server.request.query",
+		KeyPath:  []any{"id", "0"},
+		Value:    "1' OR 1=1--",
+		ClientIP: "1.2.3.4",
+		Blocked:  true,
+	}}, events)
+
+	// Only 2 out of 3 events are allowed by the rate limiter
+	export(append(events, makeEvents(template, wafEvent)...))
+	export(makeEvents(template, wafEvent))
+
+	Stop()
+	require.False(t, Enabled())
+	require.True(t, sink.closed)
+	require.Len(t, sink.events, 2)
+
+	t.Run("not-closed", func(t *testing.T) {
+		sink := &memorySink{}
+		Start(sink, 1, false)
+		Stop()
+		require.False(t, sink.closed)
+	})
+
+	t.Run("disabled", func(t *testing.T) {
+		// Exporting without a sink is a no-op
+		export(makeEvents(template, wafEvent))
+		var c *Collector
+		c.Export(nil, netip.Addr{}, false)
+	})
+}
+
+func TestFileSink(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "events.ndjson")
+	sink, err := Open("file://" + path)
+	require.NoError(t, err)
+	for _, e := range makeEvents(Event{}, wafEvent) {
+		require.NoError(t, sink.Write(e))
+		require.NoError(t, sink.Write(e))
+	}
+	require.NoError(t, sink.Close())
+
+	f, err := os.Open(path)
+	require.NoError(t, err)
+	defer f.Close()
+	var lines int
+	for s := bufio.NewScanner(f); s.Scan(); lines++ {
+		var e Event
+		require.NoError(t, json.Unmarshal(s.Bytes(), &e))
+		require.Equal(t, "crs-942-100", e.RuleID)
+	}
+	require.Equal(t, 2, lines)
+}
+
+func TestSyslogSink(t *testing.T) {
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer conn.Close()
+
+	sink, err := Open("udp://" + conn.LocalAddr().String())
+	require.NoError(t, err)
+	defer sink.Close()
+	e := makeEvents(Event{Timestamp: time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)}, wafEvent)[0]
+	require.NoError(t, sink.Write(e))
+
+	buf := make([]byte, 4096)
+	require.NoError(t, conn.SetReadDeadline(time.Now().Add(5*time.Second)))
+	n, _, err := conn.ReadFrom(buf)
+	require.NoError(t, err)
+	msg := string(buf[:n])
+	require.True(t, strings.HasPrefix(msg, "<132>1 2024-05-01T12:00:00Z "), msg)
+	header, body, ok := strings.Cut(msg, " appsec - ")
+	require.True(t, ok, msg)
+	require.Contains(t, header, " dd-trace-go ")
+	var decoded Event
+	require.NoError(t, json.Unmarshal([]byte(body), &decoded))
+	require.Equal(t, "crs-942-100", decoded.RuleID)
+}
+
+func TestOpen(t *testing.T) {
+	_, err := Open("tcp://localhost:514")
+	require.Error(t, err)
+	_, err = Open("file:///does/not/exist/events.ndjson")
+	require.Error(t, err)
+}
@@ -0,0 +1,87 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2024 Datadog, Inc.
+
+package eventsink
+
+import (
+	"encoding/json"
+	"fmt"
+	"net"
+	"os"
+	"time"
+)
+
+// FileSink writes the security events to a file, one JSON object per line
+// (NDJSON).
+type FileSink struct {
+	f *os.File
+}
+
+// NewFileSink returns a sink appending the security events to the file at the
+// given path, which is created if needed.
+func NewFileSink(path string) (*FileSink, error) {
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0o600)
+	if err != nil {
+		return nil, err
+	}
+	return &FileSink{f: f}, nil
+}
+
+// Write appends the given event to the file.
+func (s *FileSink) Write(e *Event) error {
+	line, err := json.Marshal(e)
+	if err != nil {
+		return err
+	}
+	_, err = s.f.Write(append(line, '\n'))
+	return err
+}
+
+// Close closes the file.
+func (s *FileSink) Close() error {
+	return s.f.Close()
+}
+
+// syslogPriority is the RFC 5424 priority of the security events: facility
+// local0 (16) and severity warning (4).
+const syslogPriority = 16*8 + 4
+
+// SyslogSink sends the security events to a syslog server over UDP, as RFC 5424
+// messages whose body is the JSON event.
+type SyslogSink struct {
+	conn     net.Conn
+	hostname string
+	pid      int
+}
+
+// NewSyslogSink returns a sink sending the security events to the syslog server
+// listening at the given UDP address.
+func NewSyslogSink(addr string) (*SyslogSink, error) {
+	conn, err := net.Dial("udp", addr)
+	if err != nil {
+		return nil, err
+	}
+	hostname, err := os.Hostname()
+	if err != nil || hostname == "" {
+		hostname = "-"
+	}
+	return &SyslogSink{conn: conn, hostname: hostname, pid: os.Getpid()}, nil
+}
+
+// Write sends the given event as a syslog message.
+func (s *SyslogSink) Write(e *Event) error {
+	msg, err := json.Marshal(e)
+	if err != nil {
+		return err
+	}
+	header := fmt.Sprintf("<%d>1 %s %s dd-trace-go %d appsec - ", syslogPriority, e.Timestamp.Format(time.RFC3339Nano), s.hostname, s.pid)
+	_, err = s.conn.Write(append([]byte(header), msg...))
+	return err
+}
+
+// Close closes the UDP connection.
+func (s *SyslogSink) Close() error {
+	return s.conn.Close()
+}
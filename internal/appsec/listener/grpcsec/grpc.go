@@ -27,20 +27,30 @@ import (
 
 // gRPC rule addresses currently supported by the WAF
 const (
-	GRPCServerMethodAddr          = "grpc.server.method"
-	GRPCServerRequestMessageAddr  = "grpc.server.request.message"
-	GRPCServerRequestMetadataAddr = "grpc.server.request.metadata"
-	HTTPClientIPAddr              = httpsec.HTTPClientIPAddr
-	UserIDAddr                    = httpsec.UserIDAddr
+	GRPCServerMethodAddr           = "grpc.server.method"
+	GRPCServerRequestMessageAddr   = "grpc.server.request.message"
+	GRPCServerRequestMetadataAddr  = "grpc.server.request.metadata"
+	GRPCServerResponseMessageAddr  = "grpc.server.response.message"
+	GRPCServerResponseHeadersAddr  = "grpc.server.response.metadata.headers"
+	GRPCServerResponseTrailersAddr = "grpc.server.response.metadata.trailers"
+	HTTPClientIPAddr               = httpsec.HTTPClientIPAddr
+	UserIDAddr                     = httpsec.UserIDAddr
 )
 
 // List of gRPC rule addresses currently supported by the WAF
 var supportedAddresses = listener.AddressSet{
-	GRPCServerMethodAddr:          {},
-	GRPCServerRequestMessageAddr:  {},
-	GRPCServerRequestMetadataAddr: {},
-	HTTPClientIPAddr:              {},
-	UserIDAddr:                    {},
+	GRPCServerMethodAddr:                 {},
+	GRPCServerRequestMessageAddr:         {},
+	GRPCServerRequestMetadataAddr:        {},
+	GRPCServerResponseMessageAddr:        {},
+	GRPCServerResponseHeadersAddr:        {},
+	GRPCServerResponseTrailersAddr:       {},
+	HTTPClientIPAddr:                     {},
+	UserIDAddr:                           {},
+	shared.GRPCClientMethodAddr:          {},
+	shared.GRPCClientTargetAddr:          {},
+	shared.GRPCClientRequestMessageAddr:  {},
+	shared.GRPCClientResponseMessageAddr: {},
 }
 
 // Install registers the gRPC WAF Event Listener on the given root operation.
@@ -131,6 +141,9 @@ func (l *wafEventListener) onEvent(op *types.HandlerOperation, handlerArgs types
 		})
 	}
 
+	// Outgoing gRPC calls made while serving the request
+	shared.ListenGRPCClientCalls(op, wafCtx, l.addresses, l.limiter)
+
 	values := make(map[string]any, 2) // 2 because the method and client ip addresses are commonly present in the rules
 	if l.isSecAddressListened(GRPCServerMethodAddr) {
 		// Note that this address is passed asap for the passlist, which are created per grpc method
@@ -151,8 +164,9 @@ func (l *wafEventListener) onEvent(op *types.HandlerOperation, handlerArgs types
 		}
 	}
 
-	// When the gRPC handler receives a message
-	dyngo.OnFinish(op, func(_ types.ReceiveOperation, res types.ReceiveOperationRes) {
+	// runMessage runs the WAF on the given message values, and processes the
+	// resulting actions so that the integration can interrupt the stream.
+	runMessage := func(msgOp dyngo.Operation, values waf.RunAddressData) {
 		if nbEvents.Load() == maxWAFEventsPerRequest {
 			logOnce.Do(func() {
 				log.Debug("appsec: ignoring the rpc message due to the maximum number of security events per grpc call reached")
@@ -160,6 +174,21 @@ func (l *wafEventListener) onEvent(op *types.HandlerOperation, handlerArgs types
 			return
 		}
 
+		wafResult := shared.RunWAF(wafCtx, values)
+		if wafResult.HasActions() {
+			shared.ProcessActions(msgOp, wafResult.Actions, nil)
+		}
+		if wafResult.HasEvents() {
+			log.Debug("appsec: attack detected by the grpc waf")
+			nbEvents.Inc()
+			mu.Lock()
+			defer mu.Unlock()
+			events = append(events, wafResult.Events...)
+		}
+	}
+
+	// When the gRPC handler receives a message
+	dyngo.OnFinish(op, func(recvOp types.ReceiveOperation, res types.ReceiveOperationRes) {
 		// Run the WAF on the rule addresses available and listened to by the sec rules
 		var values waf.RunAddressData
 		// Add the gRPC message to the values if the WAF rules are using it.
@@ -175,22 +204,39 @@ func (l *wafEventListener) onEvent(op *types.HandlerOperation, handlerArgs types
 			}
 		}
 
-		// Run the WAF, ignoring the returned actions - if any - since blocking after the request handler's
-		// response is not supported at the moment.
-		wafResult := shared.RunWAF(wafCtx, values)
-
-		if wafResult.HasEvents() {
-			log.Debug("appsec: attack detected by the grpc waf")
-			nbEvents.Inc()
-			mu.Lock()
-			defer mu.Unlock()
-			events = append(events, wafResult.Events...)
-		}
+		runMessage(recvOp, values)
 	})
 
+	// When the gRPC handler sends a message
+	if l.isSecAddressListened(GRPCServerResponseMessageAddr) {
+		dyngo.OnFinish(op, func(sendOp types.SendOperation, res types.SendOperationRes) {
+			// The response message is also an ephemeral address since server
+			// streams can send more than one message per RPC.
+			runMessage(sendOp, waf.RunAddressData{Ephemeral: map[string]any{GRPCServerResponseMessageAddr: res.Message}})
+		})
+	}
+
 	// When the gRPC handler finishes
-	dyngo.OnFinish(op, func(op *types.HandlerOperation, _ types.HandlerOperationRes) {
+	dyngo.OnFinish(op, func(op *types.HandlerOperation, res types.HandlerOperationRes) {
 		defer wafCtx.Close()
+
+		// Run the WAF on the response metadata, ignoring the returned actions - if any - since the response
+		// has already been sent.
+		values := make(map[string]any, 2)
+		if l.isSecAddressListened(GRPCServerResponseHeadersAddr) && len(res.Headers) > 0 {
+			values[GRPCServerResponseHeadersAddr] = res.Headers
+		}
+		if l.isSecAddressListened(GRPCServerResponseTrailersAddr) && len(res.Trailers) > 0 {
+			values[GRPCServerResponseTrailersAddr] = res.Trailers
+		}
+		if len(values) > 0 {
+			if wafResult := shared.RunWAF(wafCtx, waf.RunAddressData{Persistent: values}); wafResult.HasEvents() {
+				log.Debug("appsec: attack detected by the grpc waf in the response metadata")
+				mu.Lock()
+				events = append(events, wafResult.Events...)
+				mu.Unlock()
+			}
+		}
 		shared.AddWAFMonitoringTags(op, l.wafDiags.Version, wafCtx.Stats().Metrics())
 
 		// Log the following metrics once per instantiation of a WAF handle
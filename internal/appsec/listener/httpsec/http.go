@@ -12,11 +12,14 @@ import (
 
 	"github.com/DataDog/appsec-internal-go/limiter"
 	waf "github.com/DataDog/go-libddwaf/v3"
+	"gopkg.in/DataDog/dd-trace-go.v1/appsec/events"
 	"gopkg.in/DataDog/dd-trace-go.v1/ddtrace/ext"
 	"gopkg.in/DataDog/dd-trace-go.v1/internal/appsec/config"
 	"gopkg.in/DataDog/dd-trace-go.v1/internal/appsec/dyngo"
 	"gopkg.in/DataDog/dd-trace-go.v1/internal/appsec/emitter/httpsec/types"
+	ossec "gopkg.in/DataDog/dd-trace-go.v1/internal/appsec/emitter/ossec/types"
 	"gopkg.in/DataDog/dd-trace-go.v1/internal/appsec/emitter/sharedsec"
+	sqlsec "gopkg.in/DataDog/dd-trace-go.v1/internal/appsec/emitter/sqlsec/types"
 	"gopkg.in/DataDog/dd-trace-go.v1/internal/appsec/listener"
 	shared "gopkg.in/DataDog/dd-trace-go.v1/internal/appsec/listener/sharedsec"
 	"gopkg.in/DataDog/dd-trace-go.v1/internal/log"
@@ -34,23 +37,37 @@ const (
 	ServerRequestBodyAddr              = "server.request.body"
 	ServerResponseStatusAddr           = "server.response.status"
 	ServerResponseHeadersNoCookiesAddr = "server.response.headers.no_cookies"
+	ServerResponseBodyAddr             = "server.response.body"
 	HTTPClientIPAddr                   = "http.client_ip"
 	UserIDAddr                         = "usr.id"
+	ServerIoNetURLAddr                 = "server.io.net.url"
+	ServerDBStatementAddr              = "server.db.statement"
+	ServerDBTypeAddr                   = "server.db.system"
+	ServerIOFSFileAddr                 = "server.io.fs.file"
 )
 
 // List of HTTP rule addresses currently supported by the WAF
 var supportedAddresses = listener.AddressSet{
-	ServerRequestMethodAddr:            {},
-	ServerRequestRawURIAddr:            {},
-	ServerRequestHeadersNoCookiesAddr:  {},
-	ServerRequestCookiesAddr:           {},
-	ServerRequestQueryAddr:             {},
-	ServerRequestPathParamsAddr:        {},
-	ServerRequestBodyAddr:              {},
-	ServerResponseStatusAddr:           {},
-	ServerResponseHeadersNoCookiesAddr: {},
-	HTTPClientIPAddr:                   {},
-	UserIDAddr:                         {},
+	ServerRequestMethodAddr:              {},
+	ServerRequestRawURIAddr:              {},
+	ServerRequestHeadersNoCookiesAddr:    {},
+	ServerRequestCookiesAddr:             {},
+	ServerRequestQueryAddr:               {},
+	ServerRequestPathParamsAddr:          {},
+	ServerRequestBodyAddr:                {},
+	ServerResponseStatusAddr:             {},
+	ServerResponseHeadersNoCookiesAddr:   {},
+	ServerResponseBodyAddr:               {},
+	HTTPClientIPAddr:                     {},
+	UserIDAddr:                           {},
+	ServerIoNetURLAddr:                   {},
+	ServerDBStatementAddr:                {},
+	ServerDBTypeAddr:                     {},
+	ServerIOFSFileAddr:                   {},
+	shared.GRPCClientMethodAddr:          {},
+	shared.GRPCClientTargetAddr:          {},
+	shared.GRPCClientRequestMessageAddr:  {},
+	shared.GRPCClientResponseMessageAddr: {},
 }
 
 // Install registers the HTTP WAF Event Listener on the given root operation.
@@ -119,6 +136,56 @@ func (l *wafEventListener) onEvent(op *types.Operation, args types.HandlerOperat
 		})
 	}
 
+	if _, ok := l.addresses[ServerIoNetURLAddr]; ok && l.config.RASP {
+		// OnRoundTripOperationStart happens when an outgoing HTTP request is made by an instrumented HTTP client
+		// while serving the request. The WAF is run with the request URL in the same WAF context as the request
+		// so that it can be correlated with the request's user input, in order to detect Server-Side Request
+		// Forgery (SSRF).
+		dyngo.On(op, func(operation *types.RoundTripOperation, args types.RoundTripOperationArgs) {
+			wafResult := shared.RunRASP(operation, wafCtx, waf.RunAddressData{Ephemeral: map[string]any{ServerIoNetURLAddr: args.URL}}, listener.RASPRuleTypeSSRF)
+			if wafResult.HasActions() || wafResult.HasEvents() {
+				shared.ProcessActions(operation, wafResult.Actions, &events.BlockingSecurityEvent{})
+				shared.AddSecurityEvents(op, l.limiter, wafResult.Events)
+				log.Debug("appsec: WAF detected a suspicious outgoing request URL: %s", args.URL)
+			}
+		})
+	}
+
+	if _, ok := l.addresses[ServerDBStatementAddr]; ok && l.config.RASP {
+		// OnSQLOperationStart happens when an SQL query is about to be executed by an instrumented database client
+		// while serving the request. The WAF is run with the query and the database system in the same WAF context as
+		// the request so that the query can be correlated with the request's user input, in order to detect SQL
+		// injections.
+		dyngo.On(op, func(operation *sqlsec.SQLOperation, args sqlsec.SQLOperationArgs) {
+			wafResult := shared.RunRASP(operation, wafCtx, waf.RunAddressData{Ephemeral: map[string]any{
+				ServerDBStatementAddr: args.Query,
+				ServerDBTypeAddr:      args.Driver,
+			}}, listener.RASPRuleTypeSQLi)
+			if wafResult.HasActions() || wafResult.HasEvents() {
+				shared.ProcessActions(operation, wafResult.Actions, &events.BlockingSecurityEvent{})
+				shared.AddSecurityEvents(op, l.limiter, wafResult.Events)
+				log.Debug("appsec: WAF detected a suspicious SQL query: %s", args.Query)
+			}
+		})
+	}
+
+	if _, ok := l.addresses[ServerIOFSFileAddr]; ok && l.config.RASP {
+		// OnOpenOperationStart happens when a file is about to be opened by an instrumented filesystem API while
+		// serving the request. The WAF is run with the file path in the same WAF context as the request so that it
+		// can be correlated with the request's user input, in order to detect Local File Inclusions (LFI).
+		dyngo.On(op, func(operation *ossec.OpenOperation, args ossec.OpenOperationArgs) {
+			wafResult := shared.RunRASP(operation, wafCtx, waf.RunAddressData{Ephemeral: map[string]any{ServerIOFSFileAddr: args.Path}}, listener.RASPRuleTypeLFI)
+			if wafResult.HasActions() || wafResult.HasEvents() {
+				shared.ProcessActions(operation, wafResult.Actions, &events.BlockingSecurityEvent{})
+				shared.AddSecurityEvents(op, l.limiter, wafResult.Events)
+				log.Debug("appsec: WAF detected a suspicious file path: %s", args.Path)
+			}
+		})
+	}
+
+	// Outgoing gRPC calls made while serving the request
+	shared.ListenGRPCClientCalls(op, wafCtx, l.addresses, l.limiter)
+
 	values := make(map[string]any, 8)
 	for addr := range l.addresses {
 		switch addr {
@@ -148,10 +215,15 @@ func (l *wafEventListener) onEvent(op *types.Operation, args types.HandlerOperat
 			}
 		}
 	}
-	if l.canExtractSchemas() {
+	if l.canExtractSchemas(args) {
 		// This address will be passed as persistent. The WAF will keep it in store and trigger schema extraction
 		// for each run.
 		values["waf.context.processor"] = map[string]any{"extract-schema": true}
+		if _, ok := l.addresses[ServerResponseBodyAddr]; ok && l.config.APISecResponseBodyMaxSize > 0 {
+			// Ask the HTTP integration to capture the response body so that its schema gets extracted too, along
+			// with the sensitive data it may leak.
+			dyngo.EmitData(op, &types.ResponseBodyCapture{MaxSize: l.config.APISecResponseBodyMaxSize})
+		}
 	}
 
 	wafResult := shared.RunWAF(wafCtx, waf.RunAddressData{Persistent: values})
@@ -182,10 +254,26 @@ func (l *wafEventListener) onEvent(op *types.Operation, args types.HandlerOperat
 		})
 	}
 
+	if _, ok := l.addresses[ServerRequestPathParamsAddr]; ok {
+		// OnPathParamsOperationStart happens when the path parameters of the request are only known once it is
+		// routed by the framework, after the start of the handler operation.
+		dyngo.On(op, func(pathParamsOp *types.PathParamsOperation, args types.PathParamsOperationArgs) {
+			wafResult := shared.RunWAF(wafCtx, waf.RunAddressData{Persistent: map[string]any{ServerRequestPathParamsAddr: args.PathParams}})
+			for tag, value := range wafResult.Derivatives {
+				op.AddSerializableTag(tag, value)
+			}
+			if wafResult.HasActions() || wafResult.HasEvents() {
+				shared.ProcessActions(pathParamsOp, wafResult.Actions, types.NewMonitoringError("Request blocked"))
+				shared.AddSecurityEvents(op, l.limiter, wafResult.Events)
+				log.Debug("appsec: WAF detected suspicious path parameters")
+			}
+		})
+	}
+
 	dyngo.OnFinish(op, func(op *types.Operation, res types.HandlerOperationRes) {
 		defer wafCtx.Close()
 
-		values = make(map[string]any, 2)
+		values = make(map[string]any, 3)
 		if _, ok := l.addresses[ServerResponseStatusAddr]; ok {
 			// serverResponseStatusAddr is a string address, so we must format the status code...
 			values[ServerResponseStatusAddr] = fmt.Sprintf("%d", res.Status)
@@ -195,6 +283,10 @@ func (l *wafEventListener) onEvent(op *types.Operation, args types.HandlerOperat
 			values[ServerResponseHeadersNoCookiesAddr] = res.Headers
 		}
 
+		if _, ok := l.addresses[ServerResponseBodyAddr]; ok && res.Body != nil {
+			values[ServerResponseBodyAddr] = res.Body
+		}
+
 		// Run the WAF, ignoring the returned actions - if any - since blocking after the request handler's
 		// response is not supported at the moment.
 		wafResult := shared.RunWAF(wafCtx, waf.RunAddressData{Persistent: values})
@@ -219,8 +311,17 @@ func (l *wafEventListener) onEvent(op *types.Operation, args types.HandlerOperat
 	})
 }
 
-// canExtractSchemas checks that API Security is enabled and that sampling rate
-// allows extracting schemas
-func (l *wafEventListener) canExtractSchemas() bool {
-	return l.config.APISec.Enabled && l.config.APISec.SampleRate >= rand.Float64()
+// canExtractSchemas checks that API Security is enabled and that both the
+// sampling rate and the sampling budget of the request endpoint allow
+// extracting schemas. The requests whose route is unknown to the integration
+// are not subject to the endpoint budget, since their raw paths are not
+// reliable endpoint identifiers.
+func (l *wafEventListener) canExtractSchemas(args types.HandlerOperationArgs) bool {
+	if !l.config.APISec.Enabled || l.config.APISec.SampleRate < rand.Float64() {
+		return false
+	}
+	if args.Route == "" {
+		return true
+	}
+	return l.config.APISecSampler.Sample(args.Method, args.Route)
 }
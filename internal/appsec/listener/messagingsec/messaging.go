@@ -0,0 +1,121 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2024 Datadog, Inc.
+
+package messagingsec
+
+import (
+	"sync"
+
+	"github.com/DataDog/appsec-internal-go/limiter"
+	waf "github.com/DataDog/go-libddwaf/v3"
+
+	"gopkg.in/DataDog/dd-trace-go.v1/ddtrace/ext"
+	"gopkg.in/DataDog/dd-trace-go.v1/internal/appsec/config"
+	"gopkg.in/DataDog/dd-trace-go.v1/internal/appsec/dyngo"
+	"gopkg.in/DataDog/dd-trace-go.v1/internal/appsec/emitter/messagingsec/types"
+	"gopkg.in/DataDog/dd-trace-go.v1/internal/appsec/listener"
+	"gopkg.in/DataDog/dd-trace-go.v1/internal/appsec/listener/httpsec"
+	shared "gopkg.in/DataDog/dd-trace-go.v1/internal/appsec/listener/sharedsec"
+	"gopkg.in/DataDog/dd-trace-go.v1/internal/log"
+	"gopkg.in/DataDog/dd-trace-go.v1/internal/samplernames"
+)
+
+// Rule addresses the consumed messages are mapped to. There are no dedicated
+// messaging addresses, so the message headers and payload are mapped to the
+// request headers and body addresses in order for the attack detection rules to
+// apply to them.
+const (
+	ServerRequestHeadersNoCookiesAddr = httpsec.ServerRequestHeadersNoCookiesAddr
+	ServerRequestBodyAddr             = httpsec.ServerRequestBodyAddr
+)
+
+// List of message rule addresses currently supported by the WAF
+var supportedAddresses = listener.AddressSet{
+	ServerRequestHeadersNoCookiesAddr: {},
+	ServerRequestBodyAddr:             {},
+}
+
+// Install registers the message consumption WAF Event Listener on the given root operation.
+func Install(wafHandle *waf.Handle, cfg *config.Config, lim limiter.Limiter, root dyngo.Operation) {
+	if listener := newWafEventListener(wafHandle, cfg, lim); listener != nil {
+		log.Debug("appsec: registering the message consumption WAF Event Listener")
+		dyngo.On(root, listener.onEvent)
+	}
+}
+
+type wafEventListener struct {
+	wafHandle *waf.Handle
+	config    *config.Config
+	addresses map[string]struct{}
+	limiter   limiter.Limiter
+	wafDiags  waf.Diagnostics
+	once      sync.Once
+}
+
+func newWafEventListener(wafHandle *waf.Handle, cfg *config.Config, limiter limiter.Limiter) *wafEventListener {
+	if wafHandle == nil {
+		log.Debug("appsec: no WAF Handle available, the message consumption WAF Event Listener will not be registered")
+		return nil
+	}
+
+	addresses := listener.FilterAddressSet(supportedAddresses, wafHandle)
+	if len(addresses) == 0 {
+		log.Debug("appsec: no supported message address is used by currently loaded WAF rules, the message consumption WAF Event Listener will not be registered")
+		return nil
+	}
+
+	return &wafEventListener{
+		wafHandle: wafHandle,
+		config:    cfg,
+		addresses: addresses,
+		limiter:   limiter,
+		wafDiags:  wafHandle.Diagnostics(),
+	}
+}
+
+// onEvent runs the WAF on the consumed message in monitoring mode: the actions
+// returned by the WAF are ignored as the message has already been consumed.
+func (l *wafEventListener) onEvent(op *types.ConsumeOperation, args types.ConsumeOperationArgs) {
+	wafCtx, err := l.wafHandle.NewContextWithBudget(l.config.WAFTimeout)
+	if err != nil {
+		log.Debug("appsec: could not create budgeted WAF context: %v", err)
+	}
+	// Early return in the following cases:
+	// - wafCtx is nil, meaning it was concurrently released
+	// - err is not nil, meaning context creation failed
+	if wafCtx == nil || err != nil {
+		return
+	}
+
+	dyngo.OnFinish(op, func(op *types.ConsumeOperation, _ types.ConsumeOperationRes) {
+		defer wafCtx.Close()
+
+		values := make(map[string]any, 2)
+		if l.isSecAddressListened(ServerRequestHeadersNoCookiesAddr) && len(args.Headers) > 0 {
+			values[ServerRequestHeadersNoCookiesAddr] = args.Headers
+		}
+		if l.isSecAddressListened(ServerRequestBodyAddr) && args.Payload != nil {
+			values[ServerRequestBodyAddr] = args.Payload
+		}
+		if len(values) > 0 {
+			if wafResult := shared.RunWAF(wafCtx, waf.RunAddressData{Persistent: values}); wafResult.HasEvents() {
+				log.Debug("appsec: attack detected by the waf in a message consumed from %s %s", args.System, args.Topic)
+				shared.AddSecurityEvents(op, l.limiter, wafResult.Events)
+			}
+		}
+		shared.AddWAFMonitoringTags(op, l.wafDiags.Version, wafCtx.Stats().Metrics())
+
+		// Log the following metrics once per instantiation of a WAF handle
+		l.once.Do(func() {
+			shared.AddRulesMonitoringTags(op, &l.wafDiags)
+			op.SetTag(ext.ManualKeep, samplernames.AppSec)
+		})
+	})
+}
+
+func (l *wafEventListener) isSecAddressListened(addr string) bool {
+	_, listened := l.addresses[addr]
+	return listened
+}
@@ -0,0 +1,28 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2024 Datadog, Inc.
+
+package listener
+
+// RASPRuleType is the type of the RASP rules evaluated for a given address.
+type RASPRuleType string
+
+// RASP rule types
+const (
+	RASPRuleTypeLFI  RASPRuleType = "lfi"
+	RASPRuleTypeSSRF RASPRuleType = "ssrf"
+	RASPRuleTypeSQLi RASPRuleType = "sql_injection"
+)
+
+// RASPEvaluation is the data emitted by the WAF event listeners every time
+// they evaluate RASP rules, so that it can be monitored up in the operation
+// stack.
+type RASPEvaluation struct {
+	// RuleType is the type of the evaluated rules.
+	RuleType RASPRuleType
+	// Matched is true when at least one rule matched.
+	Matched bool
+	// Timeout is true when the WAF timed out during the evaluation.
+	Timeout bool
+}
@@ -0,0 +1,82 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2024 Datadog, Inc.
+
+package sharedsec
+
+import (
+	"github.com/DataDog/appsec-internal-go/limiter"
+	waf "github.com/DataDog/go-libddwaf/v3"
+
+	"gopkg.in/DataDog/dd-trace-go.v1/appsec/events"
+	"gopkg.in/DataDog/dd-trace-go.v1/internal/appsec/dyngo"
+	"gopkg.in/DataDog/dd-trace-go.v1/internal/appsec/emitter/grpcsec/types"
+	"gopkg.in/DataDog/dd-trace-go.v1/internal/appsec/listener"
+	"gopkg.in/DataDog/dd-trace-go.v1/internal/log"
+)
+
+// gRPC client rule addresses currently supported by the WAF
+const (
+	GRPCClientMethodAddr          = "grpc.client.method"
+	GRPCClientTargetAddr          = "grpc.client.target"
+	GRPCClientRequestMessageAddr  = "grpc.client.request.message"
+	GRPCClientResponseMessageAddr = "grpc.client.response.message"
+)
+
+// securityEventsOperation is an operation security events can be added to.
+type securityEventsOperation interface {
+	dyngo.Operation
+	securityEventsAdder
+}
+
+// ListenGRPCClientCalls runs the WAF on the outgoing gRPC calls, and on their
+// messages, made while serving the request of the given operation, in the WAF
+// context of the request, when the given rule addresses include gRPC client
+// addresses. The calls and messages are blocked by a
+// *events.BlockingSecurityEvent error.
+func ListenGRPCClientCalls(op securityEventsOperation, wafCtx *waf.Context, addresses listener.AddressSet, lim limiter.Limiter) {
+	_, method := addresses[GRPCClientMethodAddr]
+	_, target := addresses[GRPCClientTargetAddr]
+	if method || target {
+		// OnClientCallOperationStart happens when an outgoing gRPC call is made by an instrumented gRPC client
+		// while serving the request.
+		dyngo.On(op, func(callOp *types.ClientCallOperation, args types.ClientCallOperationArgs) {
+			values := make(map[string]any, 2)
+			if method {
+				values[GRPCClientMethodAddr] = args.Method
+			}
+			if target {
+				values[GRPCClientTargetAddr] = args.Target
+			}
+			wafResult := RunWAF(wafCtx, waf.RunAddressData{Ephemeral: values})
+			if wafResult.HasActions() || wafResult.HasEvents() {
+				ProcessActions(callOp, wafResult.Actions, &events.BlockingSecurityEvent{})
+				AddSecurityEvents(op, lim, wafResult.Events)
+				log.Debug("appsec: WAF detected a suspicious outgoing gRPC call: %s", args.Method)
+			}
+		})
+	}
+
+	_, request := addresses[GRPCClientRequestMessageAddr]
+	_, response := addresses[GRPCClientResponseMessageAddr]
+	if request || response {
+		// OnClientMessageOperationStart happens when a message is sent or received by an outgoing gRPC call. The
+		// message addresses are ephemeral since streams can send and receive more than one message per call.
+		dyngo.On(op, func(msgOp *types.ClientMessageOperation, args types.ClientMessageOperationArgs) {
+			addr := GRPCClientRequestMessageAddr
+			if args.Received {
+				addr = GRPCClientResponseMessageAddr
+			}
+			if _, ok := addresses[addr]; !ok {
+				return
+			}
+			wafResult := RunWAF(wafCtx, waf.RunAddressData{Ephemeral: map[string]any{addr: args.Message}})
+			if wafResult.HasActions() || wafResult.HasEvents() {
+				ProcessActions(msgOp, wafResult.Actions, &events.BlockingSecurityEvent{})
+				AddSecurityEvents(op, lim, wafResult.Events)
+				log.Debug("appsec: WAF detected a suspicious gRPC client message")
+			}
+		})
+	}
+}
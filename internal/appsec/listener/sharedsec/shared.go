@@ -11,18 +11,39 @@ import (
 	waf "github.com/DataDog/go-libddwaf/v3"
 	"gopkg.in/DataDog/dd-trace-go.v1/internal/appsec/dyngo"
 	"gopkg.in/DataDog/dd-trace-go.v1/internal/appsec/emitter/sharedsec"
+	"gopkg.in/DataDog/dd-trace-go.v1/internal/appsec/eventsink"
+	"gopkg.in/DataDog/dd-trace-go.v1/internal/appsec/listener"
 	"gopkg.in/DataDog/dd-trace-go.v1/internal/appsec/trace"
 	"gopkg.in/DataDog/dd-trace-go.v1/internal/log"
 )
 
 func RunWAF(wafCtx *waf.Context, values waf.RunAddressData) waf.Result {
+	result, _ := runWAF(wafCtx, values)
+	return result
+}
+
+// RunRASP runs the WAF with the given RASP address data, like RunWAF, and
+// reports the evaluation of the RASP rules of the given type to the given
+// operation by emitting a *listener.RASPEvaluation data.
+func RunRASP(op dyngo.Operation, wafCtx *waf.Context, values waf.RunAddressData, ruleType listener.RASPRuleType) waf.Result {
+	result, err := runWAF(wafCtx, values)
+	dyngo.EmitData(op, &listener.RASPEvaluation{
+		RuleType: ruleType,
+		Matched:  result.HasEvents(),
+		Timeout:  err == waf.ErrTimeout,
+	})
+	return result
+}
+
+// runWAF runs the WAF with the given address data and logs its errors.
+func runWAF(wafCtx *waf.Context, values waf.RunAddressData) (waf.Result, error) {
 	result, err := wafCtx.Run(values)
 	if err == waf.ErrTimeout {
 		log.Debug("appsec: waf timeout value of reached: %v", err)
 	} else if err != nil {
 		log.Error("appsec: unexpected waf error: %v", err)
 	}
-	return result
+	return result, err
 }
 
 type securityEventsAdder interface {
@@ -30,7 +51,11 @@ type securityEventsAdder interface {
 }
 
 // AddSecurityEvents is a helper function to add sec events to an operation taking into account the rate limiter.
+// The events are also emitted for the event sink beforehand, as it has its own rate limiter.
 func AddSecurityEvents(op securityEventsAdder, limiter limiter.Limiter, matches []any) {
+	if dop, ok := op.(dyngo.Operation); ok {
+		eventsink.EmitEvents(dop, matches)
+	}
 	if len(matches) > 0 && limiter.Allow() {
 		op.AddSecurityEvents(matches)
 	}
@@ -108,7 +133,7 @@ func ActionsFromEntry(actionType string, params any) []sharedsec.Action {
 		return sharedsec.NewBlockAction(p)
 	case "redirect_request":
 		return []sharedsec.Action{sharedsec.NewRedirectAction(p)}
-	case "stack_trace":
+	case "generate_stack":
 		return []sharedsec.Action{sharedsec.NewStackTraceAction(p)}
 
 	default:
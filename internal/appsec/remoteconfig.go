@@ -383,6 +383,10 @@ func (a *appsec) enableRCBlocking() {
 		log.Debug("appsec: Remote config: using rules from %s, blocking capabilities won't be enabled", a.cfg.RulesManager.BasePath)
 		return
 	}
+	if a.cfg.RulesDir != "" {
+		log.Debug("appsec: Remote config: using rules from the local directory %s, blocking capabilities won't be enabled", a.cfg.RulesDir)
+		return
+	}
 
 	products := []string{rc.ProductASM, rc.ProductASMDD, rc.ProductASMData}
 	for _, p := range products {
@@ -0,0 +1,170 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2024 Datadog, Inc.
+
+package appsec
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	internal "github.com/DataDog/appsec-internal-go/appsec"
+	waf "github.com/DataDog/go-libddwaf/v3"
+
+	"gopkg.in/DataDog/dd-trace-go.v1/internal/appsec/config"
+	"gopkg.in/DataDog/dd-trace-go.v1/internal/log"
+)
+
+// rulesDirWatcher periodically checks the local security rules directory for
+// changes and reloads the security rules out of it.
+type rulesDirWatcher struct {
+	stop chan struct{}
+	done chan struct{}
+}
+
+// loadRulesDir loads the security rules of the local rules directory and
+// starts the WAF with them. The WAF is not started when the directory holds
+// invalid rules files, so that the caller can fallback to the rules given by
+// DD_APPSEC_RULES or to the default ones.
+func (a *appsec) loadRulesDir() {
+	// The rules given by DD_APPSEC_RULES, or the default ones, are the fallback
+	// base rules when no file of the directory defines rules.
+	a.rulesDirFallback = a.cfg.RulesManager.Base
+	a.rulesDirFingerprint, _ = rulesDirFingerprint(a.cfg.RulesDir)
+	if err := a.reloadRulesDir(); err != nil {
+		log.Error("appsec: could not load the security rules of %s, the rules of %s or the default rules are used instead: %v", a.cfg.RulesDir, internal.EnvRules, err)
+		reportRulesDirUpdate(err)
+		return
+	}
+	log.Debug("appsec: loaded the security rules of %s", a.cfg.RulesDir)
+	reportRulesDirUpdate(nil)
+}
+
+// reloadRulesDir atomically replaces the current WAF with a new one compiled
+// out of the security rules of the local rules directory. The current WAF is
+// kept when the directory holds invalid rules files or when the WAF reports
+// errors with the new rules.
+func (a *appsec) reloadRulesDir() error {
+	r, err := config.LoadRulesDir(a.cfg.RulesDir, a.rulesDirFallback)
+	if err != nil {
+		return err
+	}
+	handle, err := waf.NewHandle(r.Latest, a.cfg.Obfuscator.KeyRegex, a.cfg.Obfuscator.ValueRegex)
+	if err != nil {
+		return err
+	}
+	if err := diagnosticsError(handle.Diagnostics()); err != nil {
+		handle.Close()
+		return err
+	}
+	if err := a.swapWAFHandle(handle); err != nil {
+		return err
+	}
+	a.cfg.RulesManager = r
+	return nil
+}
+
+// startRulesDirWatcher starts watching the local rules directory for changes.
+func (a *appsec) startRulesDirWatcher() {
+	w := &rulesDirWatcher{
+		stop: make(chan struct{}),
+		done: make(chan struct{}),
+	}
+	a.rulesDirWatcher = w
+	log.Debug("appsec: watching %s for security rules changes every %s", a.cfg.RulesDir, a.cfg.RulesDirPollInterval)
+
+	go func() {
+		defer close(w.done)
+		ticker := time.NewTicker(a.cfg.RulesDirPollInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-w.stop:
+				return
+			case <-ticker.C:
+			}
+
+			fingerprint, err := rulesDirFingerprint(a.cfg.RulesDir)
+			if err != nil {
+				log.Debug("appsec: could not check %s for security rules changes: %v", a.cfg.RulesDir, err)
+				continue
+			}
+			if fingerprint == a.rulesDirFingerprint {
+				continue
+			}
+			// Invalid files are reported once until the directory changes again
+			a.rulesDirFingerprint = fingerprint
+
+			if err := a.reloadRulesDir(); err != nil {
+				log.Error("appsec: could not apply the new security rules of %s, keeping the current rules: %v", a.cfg.RulesDir, err)
+				reportRulesDirUpdate(err)
+				continue
+			}
+			log.Info("appsec: applied the new security rules of %s", a.cfg.RulesDir)
+			reportRulesDirUpdate(nil)
+		}
+	}()
+}
+
+// stopRulesDirWatcher stops watching the local rules directory and waits for
+// any ongoing rules reload to be over.
+func (a *appsec) stopRulesDirWatcher() {
+	if a.rulesDirWatcher == nil {
+		return
+	}
+	close(a.rulesDirWatcher.stop)
+	<-a.rulesDirWatcher.done
+	a.rulesDirWatcher = nil
+}
+
+// rulesDirFingerprint returns a string identifying the current state of the
+// JSON files of the given directory, out of their names, sizes and
+// modification times.
+func rulesDirFingerprint(dir string) (string, error) {
+	paths, err := config.RulesDirFiles(dir)
+	if err != nil {
+		return "", err
+	}
+	var b strings.Builder
+	for _, path := range paths {
+		info, err := os.Stat(path)
+		if err != nil {
+			return "", err
+		}
+		fmt.Fprintf(&b, "%s:%d:%d;", path, info.Size(), info.ModTime().UnixNano())
+	}
+	return b.String(), nil
+}
+
+// diagnosticsError returns the rule-level errors reported by the WAF while
+// loading its rules, or nil if there were none. Top-level errors are ignored as
+// the WAF reports the empty fields of the ruleset as such.
+func diagnosticsError(d waf.Diagnostics) error {
+	var errs []error
+	entries := map[string]*waf.DiagnosticEntry{
+		"rules":          d.Rules,
+		"custom_rules":   d.CustomRules,
+		"actions":        d.Actions,
+		"exclusions":     d.Exclusions,
+		"rules_override": d.RulesOverrides,
+		"rules_data":     d.RulesData,
+		"processors":     d.Processors,
+		"scanners":       d.Scanners,
+	}
+	for field, entry := range entries {
+		if entry == nil {
+			continue
+		}
+		for msg, ids := range entry.Errors {
+			errs = append(errs, fmt.Errorf("%s %v: %s", field, ids, msg))
+		}
+	}
+	// Sort the errors to have a stable error message
+	sort.Slice(errs, func(i, j int) bool { return errs[i].Error() < errs[j].Error() })
+	return errors.Join(errs...)
+}
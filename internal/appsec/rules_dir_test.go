@@ -0,0 +1,115 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2024 Datadog, Inc.
+
+package appsec_test
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	waf "github.com/DataDog/go-libddwaf/v3"
+	httptrace "gopkg.in/DataDog/dd-trace-go.v1/contrib/net/http"
+	"gopkg.in/DataDog/dd-trace-go.v1/ddtrace/mocktracer"
+	"gopkg.in/DataDog/dd-trace-go.v1/internal/appsec"
+	"gopkg.in/DataDog/dd-trace-go.v1/internal/appsec/config"
+	"gopkg.in/DataDog/dd-trace-go.v1/internal/telemetry"
+	"gopkg.in/DataDog/dd-trace-go.v1/internal/telemetry/telemetrytest"
+
+	"github.com/stretchr/testify/require"
+)
+
+// writeDenylist writes a rules file of the default blocked_ips rule data
+// blocking the given IPs.
+func writeDenylist(t *testing.T, path string, ips ...string) {
+	data := make([]string, len(ips))
+	for i, ip := range ips {
+		data[i] = fmt.Sprintf(`{"value": %q}`, ip)
+	}
+	writeRulesFile(t, path, fmt.Sprintf(`{"rules_data": [{"id": "blocked_ips", "type": "ip_with_expiration", "data": [%s]}]}`, strings.Join(data, ",")))
+}
+
+// writeRulesFile writes the given rules file and moves its modification time
+// forward so that the change is noticed even on coarse-grained file systems.
+func writeRulesFile(t *testing.T, path, content string) {
+	require.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+	mtime := time.Now().Add(time.Duration(len(content)) * time.Second)
+	require.NoError(t, os.Chtimes(path, mtime, mtime))
+}
+
+func TestRulesDir(t *testing.T) {
+	if ok, _ := waf.Health(); !ok {
+		t.Skip("WAF needs to be available for this test")
+	}
+	telemetryClient := new(telemetrytest.MockClient)
+	defer telemetry.MockGlobalClient(telemetryClient)()
+
+	dir := t.TempDir()
+	denylist := filepath.Join(dir, "denylist.json")
+	writeDenylist(t, denylist, "1.2.3.4")
+
+	t.Setenv(config.EnvEnabled, "true")
+	t.Setenv(config.EnvRulesDir, dir)
+	t.Setenv(config.EnvRulesDirPollInterval, "10ms")
+	appsec.Start()
+	defer appsec.Stop()
+	require.True(t, appsec.Enabled())
+
+	mux := httptrace.NewServeMux()
+	mux.HandleFunc("/", func(w http.ResponseWriter, _ *http.Request) {
+		w.Write([]byte("Hello World!\n"))
+	})
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	isBlocked := func(ip string) bool {
+		mt := mocktracer.Start()
+		defer mt.Stop()
+		req, err := http.NewRequest("GET", srv.URL, nil)
+		require.NoError(t, err)
+		req.Header.Set("x-forwarded-for", ip)
+		res, err := srv.Client().Do(req)
+		require.NoError(t, err)
+		defer res.Body.Close()
+		return res.StatusCode == http.StatusForbidden
+	}
+
+	// The denylist edits the default rules
+	require.True(t, isBlocked("1.2.3.4"))
+	require.False(t, isBlocked("1.2.3.5"))
+
+	t.Run("update", func(t *testing.T) {
+		writeDenylist(t, denylist, "1.2.3.4", "1.2.3.5")
+		require.Eventually(t, func() bool { return isBlocked("1.2.3.5") }, 5*time.Second, 10*time.Millisecond)
+	})
+
+	t.Run("invalid-file", func(t *testing.T) {
+		writeRulesFile(t, filepath.Join(dir, "exclusions.json"), `{"exclusions": [`)
+		writeDenylist(t, denylist, "1.2.3.6")
+		// Leave the watcher enough time to notice the changes
+		time.Sleep(20 * 10 * time.Millisecond)
+		// The current rules are kept
+		require.True(t, isBlocked("1.2.3.5"))
+		require.False(t, isBlocked("1.2.3.6"))
+	})
+
+	t.Run("fix", func(t *testing.T) {
+		require.NoError(t, os.Remove(filepath.Join(dir, "exclusions.json")))
+		require.Eventually(t, func() bool { return isBlocked("1.2.3.6") }, 5*time.Second, 10*time.Millisecond)
+		require.False(t, isBlocked("1.2.3.5"))
+	})
+
+	// Stop AppSec to wait for the watcher to be done before checking the telemetry
+	appsec.Stop()
+	tags := []string{"waf_version:" + waf.Version(), "source:rules_dir"}
+	telemetryClient.AssertCalled(t, "Count", telemetry.NamespaceAppSec, "waf.updates", 1.0, append(tags, "success:true"), true)
+	telemetryClient.AssertCalled(t, "Count", telemetry.NamespaceAppSec, "waf.updates", 1.0, append(tags, "success:false"), true)
+	telemetryClient.AssertCalled(t, "Count", telemetry.NamespaceAppSec, "waf.config_errors", 1.0, tags, true)
+}
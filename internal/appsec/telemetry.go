@@ -7,8 +7,11 @@ package appsec
 
 import (
 	"runtime"
+	"strconv"
 
 	waf "github.com/DataDog/go-libddwaf/v3"
+	"gopkg.in/DataDog/dd-trace-go.v1/internal/appsec/dyngo"
+	"gopkg.in/DataDog/dd-trace-go.v1/internal/appsec/listener"
 	"gopkg.in/DataDog/dd-trace-go.v1/internal/telemetry"
 )
 
@@ -81,3 +84,43 @@ func (a *appsecTelemetry) emit() {
 
 	telemetry.GlobalClient.ProductChange(telemetry.NamespaceAppSec, a.enabled, a.configs)
 }
+
+// RASP telemetry metric names
+const (
+	raspRuleEvalMetric  = "rasp.rule.eval"
+	raspRuleMatchMetric = "rasp.rule.match"
+	raspTimeoutMetric   = "rasp.timeout"
+)
+
+// Local rules directory telemetry metric names
+const (
+	wafUpdatesMetric      = "waf.updates"
+	wafConfigErrorsMetric = "waf.config_errors"
+)
+
+// reportRulesDirUpdate records the telemetry metrics of an update of the
+// security rules out of the local rules directory, which failed when err is
+// not nil.
+func reportRulesDirUpdate(err error) {
+	tags := []string{"waf_version:" + waf.Version(), "source:rules_dir", "success:" + strconv.FormatBool(err == nil)}
+	telemetry.GlobalClient.Count(telemetry.NamespaceAppSec, wafUpdatesMetric, 1, tags, true)
+	if err != nil {
+		telemetry.GlobalClient.Count(telemetry.NamespaceAppSec, wafConfigErrorsMetric, 1, tags[:2], true)
+	}
+}
+
+// registerRASPTelemetry records the RASP telemetry metrics out of the RASP
+// evaluations reported by the WAF event listeners installed on the given root
+// operation.
+func registerRASPTelemetry(root dyngo.Operation) {
+	dyngo.OnData(root, func(e *listener.RASPEvaluation) {
+		tags := []string{"rule_type:" + string(e.RuleType), "waf_version:" + waf.Version()}
+		telemetry.GlobalClient.Count(telemetry.NamespaceAppSec, raspRuleEvalMetric, 1, tags, true)
+		if e.Matched {
+			telemetry.GlobalClient.Count(telemetry.NamespaceAppSec, raspRuleMatchMetric, 1, tags, true)
+		}
+		if e.Timeout {
+			telemetry.GlobalClient.Count(telemetry.NamespaceAppSec, raspTimeoutMetric, 1, tags, true)
+		}
+	})
+}
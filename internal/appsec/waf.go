@@ -18,7 +18,13 @@ func (a *appsec) swapWAF(rules config.RulesFragment) (err error) {
 	if err != nil {
 		return err
 	}
+	return a.swapWAFHandle(newHandle)
+}
 
+// swapWAFHandle installs the WAF event listeners of the given WAF handle and
+// hot-swaps the current one with it. The given handle is closed in case of an
+// error.
+func (a *appsec) swapWAFHandle(newHandle *waf.Handle) (err error) {
 	// Close the WAF handle in case of an error in what's following
 	defer func() {
 		if err != nil {
@@ -30,6 +36,7 @@ func (a *appsec) swapWAF(rules config.RulesFragment) (err error) {
 	for _, fn := range wafEventListeners {
 		fn(newHandle, a.cfg, a.limiter, newRoot)
 	}
+	registerRASPTelemetry(newRoot)
 
 	// Hot-swap dyngo's root operation
 	dyngo.SwapRootOperation(newRoot)
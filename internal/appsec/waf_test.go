@@ -6,23 +6,33 @@
 package appsec_test
 
 import (
+	"context"
+	"database/sql/driver"
 	"encoding/json"
 	"io"
 	"net/http"
 	"net/http/httptest"
 	"net/url"
 	"os"
+	"path/filepath"
 	"strings"
 	"testing"
 
 	internal "github.com/DataDog/appsec-internal-go/appsec"
 	waf "github.com/DataDog/go-libddwaf/v3"
+	sqlite "github.com/mattn/go-sqlite3"
 	pAppsec "gopkg.in/DataDog/dd-trace-go.v1/appsec"
+	"gopkg.in/DataDog/dd-trace-go.v1/appsec/events"
+	sqltrace "gopkg.in/DataDog/dd-trace-go.v1/contrib/database/sql"
 	httptrace "gopkg.in/DataDog/dd-trace-go.v1/contrib/net/http"
+	ostrace "gopkg.in/DataDog/dd-trace-go.v1/contrib/os"
 	"gopkg.in/DataDog/dd-trace-go.v1/ddtrace/mocktracer"
 	"gopkg.in/DataDog/dd-trace-go.v1/internal/appsec"
 	"gopkg.in/DataDog/dd-trace-go.v1/internal/appsec/config"
+	"gopkg.in/DataDog/dd-trace-go.v1/internal/appsec/eventsink"
 	"gopkg.in/DataDog/dd-trace-go.v1/internal/appsec/listener/httpsec"
+	"gopkg.in/DataDog/dd-trace-go.v1/internal/telemetry"
+	"gopkg.in/DataDog/dd-trace-go.v1/internal/telemetry/telemetrytest"
 
 	"github.com/stretchr/testify/require"
 )
@@ -433,6 +443,299 @@ func TestBlocking(t *testing.T) {
 	}
 }
 
+// Test that outgoing HTTP requests made with user-controlled URLs are detected
+// and blocked by RASP when performed with an instrumented HTTP client.
+func TestRASPSSRF(t *testing.T) {
+	t.Setenv("DD_APPSEC_RULES", "testdata/rasp.json")
+	t.Setenv(config.EnvRASPEnabled, "true")
+	appsec.Start()
+	defer appsec.Stop()
+	if !appsec.RASPEnabled() {
+		t.Skip("RASP needs to be enabled for this test")
+	}
+
+	const ssrfRule = "rasp-934-100"
+
+	// Start and trace an HTTP server performing outgoing requests to the host
+	// given in the query string, or to itself by default
+	var srvURL string
+	client := httptrace.WrapClient(http.DefaultClient)
+	mux := httptrace.NewServeMux()
+	mux.HandleFunc("/fetch", func(w http.ResponseWriter, r *http.Request) {
+		target := srvURL + "/"
+		if host := r.URL.Query().Get("host"); host != "" {
+			target = "http://" + host + "/latest/meta-data/"
+		}
+		req, err := http.NewRequestWithContext(r.Context(), "GET", target, nil)
+		if err != nil {
+			w.WriteHeader(500)
+			return
+		}
+		res, err := client.Do(req)
+		if events.IsSecurityError(err) {
+			return
+		}
+		if err == nil {
+			res.Body.Close()
+		}
+		w.Write([]byte("Hello World!\n"))
+	})
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+	srvURL = srv.URL
+
+	for _, tc := range []struct {
+		name      string
+		url       string
+		status    int
+		ruleMatch string
+	}{
+		{
+			name:   "no-ssrf",
+			url:    srv.URL + "/fetch",
+			status: 200,
+		},
+		{
+			name:      "ssrf",
+			url:       srv.URL + "/fetch?host=169.254.169.254",
+			status:    403,
+			ruleMatch: ssrfRule,
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			mt := mocktracer.Start()
+			defer mt.Stop()
+			res, err := srv.Client().Get(tc.url)
+			require.NoError(t, err)
+			defer res.Body.Close()
+			require.Equal(t, tc.status, res.StatusCode)
+			if tc.ruleMatch == "" {
+				return
+			}
+			var handlerSpan mocktracer.Span
+			for _, s := range mt.FinishedSpans() {
+				if s.Tag("_dd.appsec.json") != nil {
+					handlerSpan = s
+				}
+			}
+			require.NotNil(t, handlerSpan)
+			require.Contains(t, handlerSpan.Tag("_dd.appsec.json"), tc.ruleMatch)
+			require.NotNil(t, handlerSpan.Tag("_dd.stack"))
+		})
+	}
+}
+
+// Test that SQL queries built out of user input are detected and blocked by
+// RASP when executed with an instrumented database client.
+func TestRASPSQLi(t *testing.T) {
+	t.Setenv("DD_APPSEC_RULES", "testdata/rasp.json")
+	t.Setenv(config.EnvRASPEnabled, "true")
+	appsec.Start()
+	defer appsec.Stop()
+	if !appsec.RASPEnabled() {
+		t.Skip("RASP needs to be enabled for this test")
+	}
+
+	const sqliRule = "rasp-942-100"
+
+	sqltrace.Register("sqlite3", &sqlite.SQLiteDriver{})
+	db, err := sqltrace.Open("sqlite3", ":memory:")
+	require.NoError(t, err)
+	defer db.Close()
+
+	// Start and trace an HTTP server looking up users by the name given in
+	// the query string
+	mux := httptrace.NewServeMux()
+	mux.HandleFunc("/user", func(w http.ResponseWriter, r *http.Request) {
+		query := "SELECT name FROM sqlite_master WHERE name = '" + r.URL.Query().Get("name") + "'"
+		rows, err := db.QueryContext(r.Context(), query)
+		if events.IsSecurityError(err) {
+			return
+		}
+		if err == nil {
+			rows.Close()
+		}
+		w.Write([]byte("Hello World!\n"))
+	})
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	for _, tc := range []struct {
+		name      string
+		query     string
+		status    int
+		ruleMatch string
+	}{
+		{
+			name:   "no-sqli",
+			query:  "name=alice",
+			status: 200,
+		},
+		{
+			name:      "sqli",
+			query:     "name=" + url.QueryEscape("1' OR '1'='1"),
+			status:    403,
+			ruleMatch: sqliRule,
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			mt := mocktracer.Start()
+			defer mt.Stop()
+			res, err := srv.Client().Get(srv.URL + "/user?" + tc.query)
+			require.NoError(t, err)
+			defer res.Body.Close()
+			require.Equal(t, tc.status, res.StatusCode)
+			if tc.ruleMatch == "" {
+				return
+			}
+			var handlerSpan mocktracer.Span
+			for _, s := range mt.FinishedSpans() {
+				if s.Tag("_dd.appsec.json") != nil {
+					handlerSpan = s
+				}
+			}
+			require.NotNil(t, handlerSpan)
+			require.Contains(t, handlerSpan.Tag("_dd.appsec.json"), tc.ruleMatch)
+			require.NotNil(t, handlerSpan.Tag("_dd.stack"))
+		})
+	}
+}
+
+// Test that the SQL queries whose direct execution is skipped by the driver
+// are only checked once, when database/sql falls back to preparing them.
+func TestRASPSQLiSkippedQuery(t *testing.T) {
+	t.Setenv("DD_APPSEC_RULES", "testdata/rasp.json")
+	t.Setenv(config.EnvRASPEnabled, "true")
+	appsec.Start()
+	defer appsec.Stop()
+	if !appsec.RASPEnabled() {
+		t.Skip("RASP needs to be enabled for this test")
+	}
+	telemetryClient := new(telemetrytest.MockClient)
+	defer telemetry.MockGlobalClient(telemetryClient)()
+
+	sqltrace.Register("sqlite3-skip", &skipQueriesDriver{})
+	db, err := sqltrace.Open("sqlite3-skip", ":memory:")
+	require.NoError(t, err)
+	defer db.Close()
+
+	mux := httptrace.NewServeMux()
+	mux.HandleFunc("/user", func(w http.ResponseWriter, r *http.Request) {
+		rows, err := db.QueryContext(r.Context(), "SELECT name FROM sqlite_master WHERE name = 'alice'")
+		require.NoError(t, err)
+		rows.Close()
+	})
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	res, err := srv.Client().Get(srv.URL + "/user")
+	require.NoError(t, err)
+	defer res.Body.Close()
+	require.Equal(t, 200, res.StatusCode)
+
+	tags := []string{"rule_type:sql_injection", "waf_version:" + waf.Version()}
+	telemetryClient.AssertCalled(t, "Count", telemetry.NamespaceAppSec, "rasp.rule.eval", 1.0, tags, true)
+	telemetryClient.AssertNumberOfCalls(t, "Count", 1)
+}
+
+// skipQueriesDriver is a SQLite driver whose connections skip the direct
+// queries, which database/sql prepares instead.
+type skipQueriesDriver struct {
+	sqlite.SQLiteDriver
+}
+
+func (d *skipQueriesDriver) Open(name string) (driver.Conn, error) {
+	conn, err := d.SQLiteDriver.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	return &skipQueriesConn{conn.(*sqlite.SQLiteConn)}, nil
+}
+
+type skipQueriesConn struct {
+	*sqlite.SQLiteConn
+}
+
+func (*skipQueriesConn) QueryContext(context.Context, string, []driver.NamedValue) (driver.Rows, error) {
+	return nil, driver.ErrSkip
+}
+
+// Test that files opened with paths built out of user input are detected and
+// blocked by RASP when opened with the instrumented os package.
+func TestRASPLFI(t *testing.T) {
+	t.Setenv("DD_APPSEC_RULES", "testdata/rasp.json")
+	t.Setenv(config.EnvRASPEnabled, "true")
+	appsec.Start()
+	defer appsec.Stop()
+	if !appsec.RASPEnabled() {
+		t.Skip("RASP needs to be enabled for this test")
+	}
+	telemetryClient := new(telemetrytest.MockClient)
+	defer telemetry.MockGlobalClient(telemetryClient)()
+
+	const lfiRule = "rasp-930-100"
+
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(dir+"/file.txt", []byte("Hello World!\n"), 0o600))
+
+	// Start and trace an HTTP server serving the file given in the query string
+	mux := httptrace.NewServeMux()
+	mux.HandleFunc("/file", func(w http.ResponseWriter, r *http.Request) {
+		f, err := ostrace.Open(r.Context(), dir+"/"+r.URL.Query().Get("name"))
+		if events.IsSecurityError(err) {
+			return
+		}
+		if err != nil {
+			w.WriteHeader(404)
+			return
+		}
+		defer f.Close()
+		io.Copy(w, f)
+	})
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	for _, tc := range []struct {
+		name      string
+		query     string
+		status    int
+		ruleMatch string
+	}{
+		{
+			name:   "no-lfi",
+			query:  "name=file.txt",
+			status: 200,
+		},
+		{
+			name:      "lfi",
+			query:     "name=" + url.QueryEscape("../../../../../../etc/passwd"),
+			status:    403,
+			ruleMatch: lfiRule,
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			mt := mocktracer.Start()
+			defer mt.Stop()
+			res, err := srv.Client().Get(srv.URL + "/file?" + tc.query)
+			require.NoError(t, err)
+			defer res.Body.Close()
+			require.Equal(t, tc.status, res.StatusCode)
+			if tc.ruleMatch == "" {
+				return
+			}
+			spans := mt.FinishedSpans()
+			require.Len(t, spans, 1)
+			require.Contains(t, spans[0].Tag("_dd.appsec.json"), tc.ruleMatch)
+			require.NotNil(t, spans[0].Tag("_dd.stack"))
+		})
+	}
+
+	tags := []string{"rule_type:lfi", "waf_version:" + waf.Version()}
+	telemetryClient.AssertCalled(t, "Count", telemetry.NamespaceAppSec, "rasp.rule.eval", 1.0, tags, true)
+	telemetryClient.AssertCalled(t, "Count", telemetry.NamespaceAppSec, "rasp.rule.match", 1.0, tags, true)
+	telemetryClient.AssertNumberOfCalls(t, "Count", 3)
+}
+
 // Test that API Security schemas get collected when API security is enabled
 func TestAPISecurity(t *testing.T) {
 	// Start and trace an HTTP server
@@ -495,6 +798,112 @@ func TestAPISecurity(t *testing.T) {
 	})
 }
 
+// Test that API Security extracts the schemas of the JSON response bodies and
+// scans them for sensitive data
+func TestAPISecurityResponseBody(t *testing.T) {
+	t.Setenv(config.EnvEnabled, "true")
+	t.Setenv(internal.EnvAPISecEnabled, "true")
+	t.Setenv(internal.EnvAPISecSampleRate, "1.0")
+	t.Setenv(config.EnvAPISecResponseBodyEnabled, "true")
+	t.Setenv(config.EnvAPISecResponseBodyMaxSize, "64")
+	// The WAF runs of the first request can exceed the default WAF timeout
+	t.Setenv(internal.EnvWAFTimeout, "1s")
+	if wafOK, err := waf.Health(); !wafOK {
+		t.Skipf("WAF must be usable for this test to run correctly: %v", err)
+	}
+	appsec.Start()
+	defer appsec.Stop()
+	require.True(t, appsec.Enabled())
+
+	mux := httptrace.NewServeMux()
+	mux.HandleFunc("/json", func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "application/json; charset=utf-8")
+		w.Write([]byte(`{"vin": "`))
+		w.Write([]byte(`AAAAAAAAAAAAAAAAA"}`))
+	})
+	mux.HandleFunc("/large", func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"padding": "` + strings.Repeat("A", 64) + `"}`))
+	})
+	mux.HandleFunc("/text", func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "text/plain")
+		w.Write([]byte(`{"vin": "AAAAAAAAAAAAAAAAA"}`))
+	})
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	for _, tc := range []struct {
+		endpoint string
+		schema   bool
+	}{
+		{endpoint: "/json", schema: true},
+		{endpoint: "/large"},
+		{endpoint: "/text"},
+	} {
+		t.Run(tc.endpoint, func(t *testing.T) {
+			mt := mocktracer.Start()
+			defer mt.Stop()
+
+			res, err := srv.Client().Get(srv.URL + tc.endpoint)
+			require.NoError(t, err)
+			defer res.Body.Close()
+			body, err := io.ReadAll(res.Body)
+			require.NoError(t, err)
+			require.NotEmpty(t, body)
+
+			spans := mt.FinishedSpans()
+			require.Len(t, spans, 1)
+			// The request schemas are always extracted
+			require.NotNil(t, spans[0].Tag("_dd.appsec.s.req.headers"))
+			schema := spans[0].Tag("_dd.appsec.s.res.body")
+			if !tc.schema {
+				require.Nil(t, schema)
+				return
+			}
+			require.Equal(t, `[{"vin":[8,{"category":"pii","type":"vin"}]}]`, schema)
+		})
+	}
+}
+
+// Test that API Security samples up to the configured budget of requests per
+// endpoint
+func TestAPISecurityEndpointBudget(t *testing.T) {
+	t.Setenv(config.EnvEnabled, "true")
+	t.Setenv(internal.EnvAPISecEnabled, "true")
+	t.Setenv(internal.EnvAPISecSampleRate, "1.0")
+	t.Setenv(config.EnvAPISecEndpointSampleBudget, "2")
+	if wafOK, err := waf.Health(); !wafOK {
+		t.Skipf("WAF must be usable for this test to run correctly: %v", err)
+	}
+	appsec.Start()
+	defer appsec.Stop()
+	require.True(t, appsec.Enabled())
+
+	mux := httptrace.NewServeMux()
+	mux.HandleFunc("/a", func(w http.ResponseWriter, _ *http.Request) {})
+	mux.HandleFunc("/b", func(w http.ResponseWriter, _ *http.Request) {})
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	mt := mocktracer.Start()
+	defer mt.Stop()
+	for _, endpoint := range []string{"/a", "/a", "/a", "/b"} {
+		res, err := srv.Client().Get(srv.URL + endpoint)
+		require.NoError(t, err)
+		res.Body.Close()
+	}
+
+	spans := mt.FinishedSpans()
+	require.Len(t, spans, 4)
+	var sampled []string
+	for _, span := range spans {
+		if span.Tag("_dd.appsec.s.req.headers") != nil {
+			sampled = append(sampled, span.Tag("http.url").(string))
+		}
+	}
+	require.Equal(t, []string{srv.URL + "/a", srv.URL + "/a", srv.URL + "/b"}, sampled)
+}
+
 // BenchmarkSampleWAFContext benchmarks the creation of a WAF context and running the WAF on a request/response pair
 // This is a basic sample of what could happen in a real-world scenario.
 func BenchmarkSampleWAFContext(b *testing.B) {
@@ -575,3 +984,69 @@ func init() {
 		os.Setenv(internal.EnvWAFTimeout, "1s")
 	}
 }
+
+// Test that every WAF match gets exported to the event sink, independently of
+// the trace rate limit.
+func TestEventSink(t *testing.T) {
+	t.Setenv("DD_APPSEC_RULES", "testdata/blocking.json")
+	t.Setenv("DD_APPSEC_TRACE_RATE_LIMIT", "1")
+	path := filepath.Join(t.TempDir(), "events.ndjson")
+	t.Setenv(config.EnvEventSink, "file://"+path)
+	appsec.Start()
+	defer appsec.Stop()
+	if !appsec.Enabled() {
+		t.Skip("AppSec needs to be enabled for this test")
+	}
+
+	mux := httptrace.NewServeMux()
+	mux.HandleFunc("/user", func(w http.ResponseWriter, r *http.Request) {
+		if err := pAppsec.SetUser(r.Context(), r.Header.Get("test-usr")); err != nil {
+			return
+		}
+		w.Write([]byte("Hello World!\n"))
+	})
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	mt := mocktracer.Start()
+	defer mt.Stop()
+	for i := 0; i < 2; i++ {
+		req, err := http.NewRequest("GET", srv.URL+"/user", nil)
+		require.NoError(t, err)
+		req.Header.Set("test-usr", "blocked-user-1")
+		req.Header.Set("x-forwarded-for", "1.2.3.5")
+		res, err := srv.Client().Do(req)
+		require.NoError(t, err)
+		res.Body.Close()
+		require.Equal(t, 403, res.StatusCode)
+	}
+
+	spans := mt.FinishedSpans()
+	require.Len(t, spans, 2)
+	// The second trace got rate limited
+	require.Contains(t, spans[0].Tag("_dd.appsec.json"), "blk-001-002")
+	require.Nil(t, spans[1].Tag("_dd.appsec.json"))
+
+	// Stop AppSec to flush the event sink
+	appsec.Stop()
+	f, err := os.Open(path)
+	require.NoError(t, err)
+	defer f.Close()
+	var events []eventsink.Event
+	for dec := json.NewDecoder(f); dec.More(); {
+		var e eventsink.Event
+		require.NoError(t, dec.Decode(&e))
+		events = append(events, e)
+	}
+	require.Len(t, events, 2)
+	for i, e := range events {
+		require.Equal(t, "blk-001-002", e.RuleID)
+		require.Equal(t, "usr.id", e.Address)
+		require.Equal(t, "blocked-user-1", e.Value)
+		require.Equal(t, "blocked-user-1", e.UserID)
+		require.Equal(t, "1.2.3.5", e.ClientIP)
+		require.True(t, e.Blocked)
+		require.Equal(t, spans[i].TraceID(), e.TraceID)
+		require.Equal(t, spans[i].SpanID(), e.SpanID)
+	}
+}
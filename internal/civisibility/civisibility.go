@@ -34,6 +34,9 @@ var (
 
 	// mTracer contains the mock tracer instance for testing purposes
 	mTracer mocktracer.Tracer
+
+	// serviceName is the service name of the test session.
+	serviceName string
 )
 
 // EnsureCiVisibilityInitialization initializes the CI visibility tracer if it hasn't been initialized already.
@@ -45,6 +48,9 @@ func EnsureCiVisibilityInitialization() {
 		// Initialize the tracer.
 		tracer.Start(opts...)
 
+		// Load the settings and data of the additional features from the backend.
+		ensureAdditionalFeaturesInitialization()
+
 		// Handle SIGINT and SIGTERM signals.
 		signals := make(chan os.Signal, 1)
 		signal.Notify(signals, syscall.SIGINT, syscall.SIGTERM)
@@ -87,7 +93,7 @@ func initCiVisibilityTags() []tracer.StartOption {
 
 	// Check if DD_SERVICE has been set; otherwise default to the repo name.
 	var opts []tracer.StartOption
-	if v := os.Getenv("DD_SERVICE"); v == "" {
+	if serviceName = os.Getenv("DD_SERVICE"); serviceName == "" {
 		if repoUrl, ok := ciTags[constants.GitRepositoryURL]; ok {
 			// regex to sanitize the repository url to be used as a service name
 			repoRegex := regexp.MustCompile(`(?m)/([a-zA-Z0-9\\\-_.]*)$`)
@@ -95,6 +101,7 @@ func initCiVisibilityTags() []tracer.StartOption {
 			if len(matches) > 1 {
 				repoUrl = strings.TrimSuffix(matches[1], ".git")
 			}
+			serviceName = repoUrl
 			opts = append(opts, tracer.WithService(repoUrl))
 		}
 	}
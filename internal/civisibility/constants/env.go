@@ -24,4 +24,38 @@ const (
 	// This environment variable should be set to your Datadog API key, allowing the agentless mode to authenticate and
 	// send data directly to the Datadog platform.
 	ApiKeyEnvironmentVariable = "DD_API_KEY"
+
+	// CiVisibilityFlakyRetryEnabledEnvironmentVariable indicates if Automatic Test Retries are enabled.
+	// This environment variable should be set to "1" or "true" to retry the failed tests in place, reporting
+	// them as passed if any of their retries passes.
+	CiVisibilityFlakyRetryEnabledEnvironmentVariable = "DD_CIVISIBILITY_FLAKY_RETRY_ENABLED"
+
+	// CiVisibilityFlakyRetryCountEnvironmentVariable indicates the maximum number of retries of a failed test.
+	// This environment variable defaults to 5 when Automatic Test Retries are enabled.
+	CiVisibilityFlakyRetryCountEnvironmentVariable = "DD_CIVISIBILITY_FLAKY_RETRY_COUNT"
+
+	// CiVisibilityTotalFlakyRetryCountEnvironmentVariable indicates the maximum number of retries in the whole session.
+	// This environment variable defaults to 1000 when Automatic Test Retries are enabled, and caps the cost of a
+	// broken build that would otherwise retry every test.
+	CiVisibilityTotalFlakyRetryCountEnvironmentVariable = "DD_CIVISIBILITY_TOTAL_FLAKY_RETRY_COUNT"
+
+	// CiVisibilityEarlyFlakeDetectionEnabledEnvironmentVariable indicates if Early Flake Detection is enabled.
+	// Early Flake Detection is enabled by the CI Visibility settings of the repository, this environment variable
+	// can be set to "0" or "false" to disable it locally.
+	CiVisibilityEarlyFlakeDetectionEnabledEnvironmentVariable = "DD_CIVISIBILITY_EARLY_FLAKE_DETECTION_ENABLED"
+
+	// CiVisibilityIntelligentTestRunnerEnabledEnvironmentVariable indicates if the Intelligent Test Runner is enabled.
+	// The Intelligent Test Runner is enabled by the CI Visibility settings of the repository, this environment variable
+	// can be set to "0" or "false" to disable it locally and run all the tests.
+	CiVisibilityIntelligentTestRunnerEnabledEnvironmentVariable = "DD_CIVISIBILITY_ITR_ENABLED"
+
+	// CiVisibilityGitUploadEnabledEnvironmentVariable indicates if the git metadata of the repository is uploaded.
+	// The backend needs the history of the repository to select the tests affected by a commit, this environment
+	// variable can be set to "0" or "false" to disable the upload.
+	CiVisibilityGitUploadEnabledEnvironmentVariable = "DD_CIVISIBILITY_GIT_UPLOAD_ENABLED"
+
+	// CiVisibilityPayloadFileEnvironmentVariable indicates a file the test cycle payloads are written to.
+	// When set, the payloads are appended to this file instead of being sent, and no request is made to the
+	// backend, allowing to report tests offline and to upload them later.
+	CiVisibilityPayloadFileEnvironmentVariable = "DD_CIVISIBILITY_PAYLOAD_FILE"
 )
@@ -31,6 +31,37 @@ const (
 	ItrCorrelationIdTagName string = "itr_correlation_id"
 )
 
+// Intelligent Test Runner tags
+const (
+	// TestsSkippingEnabledTagName defines if the Intelligent Test Runner skips tests.
+	// This constant is used to tag the test session and modules when the skippable tests are skipped.
+	TestsSkippingEnabledTagName string = "test.itr.tests_skipping.enabled"
+
+	// TestsSkippingTypeTagName defines the granularity of the tests skipped by the Intelligent Test Runner.
+	// This constant is used to tag the test session and modules, with "test" as tests are skipped one by one.
+	TestsSkippingTypeTagName string = "test.itr.tests_skipping.type"
+
+	// TestsSkippingCountTagName defines the number of tests skipped by the Intelligent Test Runner.
+	// This constant is used to tag the test session and modules with their number of skipped tests.
+	TestsSkippingCountTagName string = "test.itr.tests_skipping.count"
+
+	// ItrTestsSkippedTagName defines if the Intelligent Test Runner skipped any test.
+	// This constant is used to tag the test session and modules with "true" when at least one of their tests was skipped.
+	ItrTestsSkippedTagName string = "_dd.ci.itr.tests_skipped"
+
+	// TestSkippedByItrTagName defines if a test was skipped by the Intelligent Test Runner.
+	// This constant is used to tag the tests that weren't run as they aren't affected by the changes of the commit.
+	TestSkippedByItrTagName string = "test.skipped_by_itr"
+
+	// TestUnskippableTagName defines if a test is marked as unskippable.
+	// This constant is used to tag the tests, and their suites, that the Intelligent Test Runner never skips.
+	TestUnskippableTagName string = "test.itr.unskippable"
+
+	// TestForcedToRunTagName defines if an unskippable test was run although it was skippable.
+	// This constant is used to tag the tests, and their suites, that would have been skipped without the unskippable marker.
+	TestForcedToRunTagName string = "test.itr.forced_run"
+)
+
 // Coverage tags
 const (
 	// CodeCoverageEnabledTagName defines if code coverage has been enabled.
@@ -61,6 +61,50 @@ const (
 	// TestCommandWorkingDirectory indicates the test command working directory relative to the source root.
 	// This constant is used to tag traces with the working directory path relative to the source root.
 	TestCommandWorkingDirectory = "test.working_directory"
+
+	// TestIsRetry indicates a retry execution of the test.
+	// This constant is used to tag the test events of the executions following the first one of a test.
+	TestIsRetry = "test.is_retry"
+
+	// TestIsNew indicates a new test, unknown to the backend.
+	// This constant is used to tag the test events of the tests run by Early Flake Detection.
+	TestIsNew = "test.is_new"
+
+	// TestEarlyFlakeDetectionEnabled indicates if Early Flake Detection is enabled in the session.
+	// This constant is used to tag the test session when the new tests are run several times.
+	TestEarlyFlakeDetectionEnabled = "test.early_flake.enabled"
+
+	// TestEarlyFlakeDetectionRetryAborted indicates why Early Flake Detection was aborted.
+	// This constant is used to tag the test session, e.g. with "faulty" when too many tests are new.
+	TestEarlyFlakeDetectionRetryAborted = "test.early_flake.abort_reason"
+
+	// TestOutput indicates the output of a failed test.
+	// This constant is used to tag the test events with the messages logged by the test, and the goroutine dump of its panic.
+	TestOutput = "test.output"
+
+	// TestOutputTruncated indicates that the output of the test was truncated.
+	// This constant is used to tag the test events whose output exceeded the size limit, only its end being reported.
+	TestOutputTruncated = "test.output.truncated"
+
+	// TestFuzzMode indicates how a fuzz test was run.
+	// This constant is used to tag the fuzz tests with "seed_corpus", when only their seed corpus is run, or "fuzzing".
+	TestFuzzMode = "test.fuzz.mode"
+
+	// TestFuzzIterations indicates the number of inputs run while fuzzing.
+	// This constant is used to tag the fuzz tests run with the fuzzing engine.
+	TestFuzzIterations = "test.fuzz.iterations"
+
+	// TestFuzzNewInterestingInputs indicates the number of new inputs expanding the code coverage found while fuzzing.
+	// This constant is used to tag the fuzz tests run with the fuzzing engine.
+	TestFuzzNewInterestingInputs = "test.fuzz.new_interesting_inputs"
+
+	// TestFuzzCrashInputPath indicates the path of the input written to the seed corpus after a fuzzing failure.
+	// This constant is used to tag the fuzz tests that failed while fuzzing, the input can be re-run with go test.
+	TestFuzzCrashInputPath = "test.fuzz.crash_input_path"
+
+	// TestLabels indicates the labels of a test, comma-separated.
+	// This constant is used to tag the tests of the frameworks supporting labels, such as the Ginkgo specs.
+	TestLabels = "test.labels"
 )
 
 // Define valid test status types.
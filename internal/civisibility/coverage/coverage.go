@@ -0,0 +1,90 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2024 Datadog, Inc.
+
+// Package coverage holds the per-test code coverage reported to CI Visibility, and the
+// registration of the writer sending it to the backend.
+package coverage
+
+import "sync"
+
+type (
+	// TestCoverage is the code coverage of a test execution: the lines it executed, by file.
+	TestCoverage struct {
+		SessionID uint64          // Span ID of the test session.
+		SuiteID   uint64          // Span ID of the test suite.
+		SpanID    uint64          // Span ID of the test.
+		Files     []*FileCoverage // Files executed by the test.
+	}
+
+	// FileCoverage is the code coverage of a file, as a bitmap of its executed lines.
+	FileCoverage struct {
+		Filename string // Path of the file, relative to the repository root.
+		Bitmap   Bitmap // Executed lines of the file.
+	}
+
+	// Bitmap is a bitmap of lines, where the most significant bit of the first byte is line 0.
+	Bitmap []byte
+
+	// Writer sends the test coverages to the backend.
+	Writer interface {
+		Write(coverage *TestCoverage)
+	}
+)
+
+var (
+	// writer is the current coverage writer, nil when the coverage isn't sent.
+	writer Writer
+
+	// writerMutex synchronizes access to writer.
+	writerMutex sync.RWMutex
+)
+
+// Set marks the given line as executed, growing the bitmap if needed.
+func (b *Bitmap) Set(line int) {
+	if line < 0 {
+		return
+	}
+	if n := line/8 + 1; n > len(*b) {
+		*b = append(*b, make([]byte, n-len(*b))...)
+	}
+	(*b)[line/8] |= 1 << (7 - line%8)
+}
+
+// IsSet returns true when the given line is marked as executed.
+func (b Bitmap) IsSet(line int) bool {
+	return line >= 0 && line/8 < len(b) && b[line/8]&(1<<(7-line%8)) != 0
+}
+
+// SetWriter sets the writer of the test coverages.
+func SetWriter(w Writer) {
+	writerMutex.Lock()
+	defer writerMutex.Unlock()
+	writer = w
+}
+
+// ClearWriter removes the writer of the test coverages if it's still w.
+func ClearWriter(w Writer) {
+	writerMutex.Lock()
+	defer writerMutex.Unlock()
+	if writer == w {
+		writer = nil
+	}
+}
+
+// Enabled returns true when there is a writer to send the test coverages.
+func Enabled() bool {
+	writerMutex.RLock()
+	defer writerMutex.RUnlock()
+	return writer != nil
+}
+
+// Write sends the given test coverage with the current writer, if any.
+func Write(coverage *TestCoverage) {
+	writerMutex.RLock()
+	defer writerMutex.RUnlock()
+	if writer != nil {
+		writer.Write(coverage)
+	}
+}
@@ -0,0 +1,127 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2024 Datadog, Inc.
+
+package civisibility
+
+import (
+	"context"
+	"os"
+	"sync"
+	"time"
+
+	"gopkg.in/DataDog/dd-trace-go.v1/internal"
+	"gopkg.in/DataDog/dd-trace-go.v1/internal/civisibility/constants"
+	"gopkg.in/DataDog/dd-trace-go.v1/internal/civisibility/utils/net"
+	"gopkg.in/DataDog/dd-trace-go.v1/internal/log"
+)
+
+// additionalFeaturesTimeout is the time budget of the loading of the settings of the additional features
+// and their data, as it delays the start of the tests. It bounds the requests, retries included, and the
+// wait for the git metadata upload when the settings require it.
+const additionalFeaturesTimeout = time.Minute
+
+var (
+	// additionalFeaturesInitializationOnce ensures we load the additional features data only once.
+	additionalFeaturesInitializationOnce sync.Once
+
+	// ciVisibilitySettings contains the CI Visibility settings of the repository and service.
+	ciVisibilitySettings net.SettingsResponseData
+
+	// ciVisibilityKnownTests contains the tests known by the backend, nil when Early Flake Detection is disabled.
+	ciVisibilityKnownTests *net.KnownTestsResponseData
+
+	// ciVisibilitySkippableTests contains the tests that can be skipped, nil when the Intelligent Test Runner doesn't skip tests.
+	ciVisibilitySkippableTests *net.SkippableTestsResponseData
+)
+
+// ensureAdditionalFeaturesInitialization loads the settings of the additional features (Early Flake
+// Detection, Intelligent Test Runner...) and their data from the CI Visibility backend. The features stay disabled when the
+// backend can't be reached.
+func ensureAdditionalFeaturesInitialization() {
+	additionalFeaturesInitializationOnce.Do(func() {
+		// The payloads are written to a file without reaching the backend: the features stay disabled.
+		if os.Getenv(constants.CiVisibilityPayloadFileEnvironmentVariable) != "" {
+			return
+		}
+
+		// Upload the git metadata in the background, as the backend needs the repository history.
+		if internal.BoolEnv(constants.CiVisibilityGitUploadEnabledEnvironmentVariable, true) {
+			if client := net.NewClient(serviceName); client != nil {
+				startGitUpload(client)
+			}
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), additionalFeaturesTimeout)
+		defer cancel()
+		client := net.NewClientWithContext(ctx, serviceName)
+		if client == nil {
+			// The tracer isn't started in CI Visibility mode.
+			return
+		}
+
+		settings, err := client.GetSettings()
+		if err != nil {
+			log.Error("civisibility: error getting the CI Visibility settings: %v", err)
+			return
+		}
+		if settings.RequireGit && gitUploadDone != nil {
+			// The settings depend on the git metadata being uploaded: wait for it and reload them.
+			waitForGitUpload(ctx)
+			if settings, err = client.GetSettings(); err != nil {
+				log.Error("civisibility: error getting the CI Visibility settings: %v", err)
+				return
+			}
+		}
+		ciVisibilitySettings = *settings
+
+		// Early Flake Detection can be disabled locally.
+		if !internal.BoolEnv(constants.CiVisibilityEarlyFlakeDetectionEnabledEnvironmentVariable, true) {
+			ciVisibilitySettings.EarlyFlakeDetection.Enabled = false
+		}
+		if ciVisibilitySettings.EarlyFlakeDetection.Enabled {
+			knownTests, err := client.GetKnownTests()
+			if err != nil {
+				// Without the known tests every test would be new.
+				log.Error("civisibility: error getting the known tests, disabling Early Flake Detection: %v", err)
+				ciVisibilitySettings.EarlyFlakeDetection.Enabled = false
+			} else {
+				ciVisibilityKnownTests = knownTests
+			}
+		}
+
+		// The Intelligent Test Runner can be disabled locally.
+		if !internal.BoolEnv(constants.CiVisibilityIntelligentTestRunnerEnabledEnvironmentVariable, true) {
+			ciVisibilitySettings.ItrEnabled = false
+			ciVisibilitySettings.TestsSkipping = false
+		}
+		if ciVisibilitySettings.ItrEnabled && ciVisibilitySettings.TestsSkipping {
+			skippableTests, err := client.GetSkippableTests()
+			if err != nil {
+				// Without the skippable tests every test is run.
+				log.Error("civisibility: error getting the skippable tests, disabling tests skipping: %v", err)
+				ciVisibilitySettings.TestsSkipping = false
+			} else {
+				ciVisibilitySkippableTests = skippableTests
+			}
+		}
+	})
+}
+
+// GetSettings returns the CI Visibility settings of the repository and service, with all the
+// features disabled if they couldn't be loaded.
+func GetSettings() *net.SettingsResponseData {
+	return &ciVisibilitySettings
+}
+
+// GetKnownTests returns the tests known by the backend, or nil when Early Flake Detection is disabled.
+func GetKnownTests() *net.KnownTestsResponseData {
+	return ciVisibilityKnownTests
+}
+
+// GetSkippableTests returns the tests that can be skipped for the current commit, or nil when the
+// Intelligent Test Runner doesn't skip tests.
+func GetSkippableTests() *net.SkippableTestsResponseData {
+	return ciVisibilitySkippableTests
+}
@@ -0,0 +1,117 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2024 Datadog, Inc.
+
+package civisibility
+
+import (
+	"context"
+	"os"
+	"time"
+
+	"gopkg.in/DataDog/dd-trace-go.v1/internal/civisibility/constants"
+	"gopkg.in/DataDog/dd-trace-go.v1/internal/civisibility/utils"
+	"gopkg.in/DataDog/dd-trace-go.v1/internal/civisibility/utils/net"
+	"gopkg.in/DataDog/dd-trace-go.v1/internal/log"
+)
+
+// gitUploadTimeout bounds the upload of the git metadata, including the git commands and the requests.
+const gitUploadTimeout = 60 * time.Second
+
+var (
+	// gitUploadDone is closed when the upload of the git metadata is over, nil when it wasn't started.
+	gitUploadDone chan struct{}
+
+	// gitUploadDeadline is the time after which the upload of the git metadata is canceled.
+	gitUploadDeadline time.Time
+)
+
+// startGitUpload uploads the git metadata of the repository in the background, so that the backend
+// knows its history for the Intelligent Test Runner. The upload is bounded by gitUploadTimeout, and
+// waited for on exit.
+func startGitUpload(client net.Client) {
+	gitUploadDone = make(chan struct{})
+	gitUploadDeadline = time.Now().Add(gitUploadTimeout)
+	ctx, cancel := context.WithDeadline(context.Background(), gitUploadDeadline)
+	go func() {
+		defer close(gitUploadDone)
+		defer cancel()
+		start := time.Now()
+		bytes, err := uploadRepositoryChanges(ctx, client)
+		if err != nil {
+			log.Error("civisibility: error uploading the git metadata: %v", err)
+			return
+		}
+		log.Debug("civisibility: git metadata uploaded in %s (%d bytes)", time.Since(start), bytes)
+	}()
+	PushCiVisibilityCloseAction(func() { waitForGitUpload(context.Background()) })
+}
+
+// waitForGitUpload waits for the upload of the git metadata to be over, up to its deadline or until ctx is done.
+func waitForGitUpload(ctx context.Context) {
+	if gitUploadDone == nil {
+		return
+	}
+	select {
+	case <-gitUploadDone:
+	case <-time.After(time.Until(gitUploadDeadline)):
+		log.Warn("civisibility: timed out waiting for the git metadata upload")
+	case <-ctx.Done():
+		log.Warn("civisibility: timed out waiting for the git metadata upload")
+	}
+}
+
+// uploadRepositoryChanges asks the backend which of the latest local commits it lacks, and uploads the
+// pack files of their objects. Shallow clones are unshallowed first when the backend lacks commits, as
+// their history is incomplete.
+func uploadRepositoryChanges(ctx context.Context, client net.Client) (bytes int64, err error) {
+	localCommits := utils.GetLastLocalGitCommitShas(ctx)
+	if len(localCommits) == 0 {
+		return 0, nil
+	}
+	knownCommits, missingCommits, err := searchCommits(client, localCommits)
+	if err != nil || len(missingCommits) == 0 {
+		return 0, err
+	}
+
+	if utils.IsShallowGitRepository(ctx) {
+		if err := utils.UnshallowGitRepository(ctx); err != nil {
+			log.Warn("civisibility: error unshallowing the git repository: %v", err)
+		} else if localCommits = utils.GetLastLocalGitCommitShas(ctx); len(localCommits) > 0 {
+			knownCommits, missingCommits, err = searchCommits(client, localCommits)
+			if err != nil || len(missingCommits) == 0 {
+				return 0, err
+			}
+		}
+	}
+
+	dir, err := os.MkdirTemp("", "dd-git-packfiles-")
+	if err != nil {
+		return 0, err
+	}
+	defer os.RemoveAll(dir)
+	packFiles, err := utils.CreatePackFiles(ctx, missingCommits, knownCommits, dir)
+	if err != nil || len(packFiles) == 0 {
+		return 0, err
+	}
+	return client.SendPackFiles(utils.GetCiTags()[constants.GitCommitSHA], packFiles)
+}
+
+// searchCommits splits the local commits between the ones known by the backend and the missing ones.
+func searchCommits(client net.Client, localCommits []string) (knownCommits []string, missingCommits []string, err error) {
+	knownCommits, err = client.GetCommits(localCommits)
+	if err != nil {
+		return nil, nil, err
+	}
+	known := make(map[string]struct{}, len(knownCommits))
+	for _, commit := range knownCommits {
+		known[commit] = struct{}{}
+	}
+	for _, commit := range localCommits {
+		if _, ok := known[commit]; !ok {
+			missingCommits = append(missingCommits, commit)
+		}
+	}
+	return knownCommits, missingCommits, nil
+}
@@ -0,0 +1,93 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2024 Datadog, Inc.
+
+package civisibility
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"gopkg.in/DataDog/dd-trace-go.v1/internal/civisibility/utils/net"
+)
+
+// gitBackendStandIn is a net.Client knowing some commits of the repository and recording the uploaded pack files.
+type gitBackendStandIn struct {
+	net.Client
+	knownCommits []string
+	packFiles    int
+}
+
+func (c *gitBackendStandIn) GetCommits(localCommits []string) ([]string, error) {
+	var known []string
+	for _, commit := range localCommits {
+		for _, knownCommit := range c.knownCommits {
+			if commit == knownCommit {
+				known = append(known, commit)
+			}
+		}
+	}
+	return known, nil
+}
+
+func (c *gitBackendStandIn) SendPackFiles(_ string, packFiles []string) (int64, error) {
+	var bytes int64
+	for _, packFile := range packFiles {
+		info, err := os.Stat(packFile)
+		if err != nil {
+			return bytes, err
+		}
+		bytes += info.Size()
+		c.packFiles++
+	}
+	return bytes, nil
+}
+
+func TestUploadRepositoryChanges(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+	dir := t.TempDir()
+	for _, args := range [][]string{
+		{"init", "-q"},
+		{"-c", "user.name=test", "-c", "user.email=test@example.com", "commit", "-q", "--allow-empty", "-m", "first"},
+		{"-c", "user.name=test", "-c", "user.email=test@example.com", "commit", "-q", "--allow-empty", "-m", "second"},
+	} {
+		cmd := exec.Command("git", args...)
+		cmd.Dir = dir
+		out, err := cmd.CombinedOutput()
+		require.NoError(t, err, string(out))
+	}
+	wd, err := os.Getwd()
+	require.NoError(t, err)
+	require.NoError(t, os.Chdir(dir))
+	defer func() { _ = os.Chdir(wd) }()
+	out, err := exec.Command("git", "rev-parse", "HEAD~1").Output()
+	require.NoError(t, err)
+	firstCommit := string(out[:len(out)-1])
+
+	t.Run("missing commits", func(t *testing.T) {
+		client := &gitBackendStandIn{knownCommits: []string{firstCommit}}
+		bytes, err := uploadRepositoryChanges(context.Background(), client)
+		require.NoError(t, err)
+		assert.Positive(t, bytes)
+		assert.Equal(t, 1, client.packFiles)
+	})
+
+	t.Run("known commits", func(t *testing.T) {
+		client := &gitBackendStandIn{knownCommits: []string{firstCommit}}
+		out, err := exec.Command("git", "rev-parse", "HEAD").Output()
+		require.NoError(t, err)
+		client.knownCommits = append(client.knownCommits, string(out[:len(out)-1]))
+		bytes, err := uploadRepositoryChanges(context.Background(), client)
+		require.NoError(t, err)
+		assert.Zero(t, bytes)
+		assert.Zero(t, client.packFiles)
+	})
+}
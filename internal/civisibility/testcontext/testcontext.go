@@ -0,0 +1,82 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2024 Datadog, Inc.
+
+// Package testcontext keeps track of the tests running in the process, so that the tracer reports the
+// spans started without a parent during a test, such as the spans of the integrations, as its children.
+package testcontext
+
+import (
+	"sync"
+
+	"gopkg.in/DataDog/dd-trace-go.v1/ddtrace"
+)
+
+// runningTest is a test whose execution is in progress.
+type runningTest struct {
+	ctx    ddtrace.SpanContext // Span context of the test.
+	parent ddtrace.SpanContext // Span context of the parent test, nil for a top-level test.
+}
+
+var (
+	// runningTests holds the running tests, in their start order.
+	runningTests []*runningTest
+
+	// runningTestsMutex synchronizes access to runningTests.
+	runningTestsMutex sync.RWMutex
+)
+
+// Start marks the test of the span context ctx as running, as a subtest of the running test of the span
+// context parent, or as a top-level test when parent is nil. The returned function marks the end of the test.
+func Start(ctx, parent ddtrace.SpanContext) (end func()) {
+	test := &runningTest{ctx: ctx, parent: parent}
+	runningTestsMutex.Lock()
+	runningTests = append(runningTests, test)
+	runningTestsMutex.Unlock()
+
+	return func() {
+		runningTestsMutex.Lock()
+		defer runningTestsMutex.Unlock()
+		for i, t := range runningTests {
+			if t == test {
+				runningTests = append(runningTests[:i], runningTests[i+1:]...)
+				return
+			}
+		}
+	}
+}
+
+// Active returns the span context of the running test, the innermost one when subtests are running. It
+// returns nil when no test is running, and when tests are running in parallel, since the test a span
+// belongs to is unknown then.
+func Active() ddtrace.SpanContext {
+	runningTestsMutex.RLock()
+	defer runningTestsMutex.RUnlock()
+	if len(runningTests) == 0 {
+		return nil
+	}
+
+	// The running tests must all be the ancestors of the last started one.
+	active := runningTests[len(runningTests)-1]
+	chainLength := 1
+	for test := active; test.parent != nil; chainLength++ {
+		if test = find(test.parent); test == nil {
+			break
+		}
+	}
+	if chainLength != len(runningTests) {
+		return nil
+	}
+	return active.ctx
+}
+
+// find returns the running test of the span context ctx, or nil.
+func find(ctx ddtrace.SpanContext) *runningTest {
+	for _, test := range runningTests {
+		if test.ctx == ctx {
+			return test
+		}
+	}
+	return nil
+}
@@ -0,0 +1,61 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2024 Datadog, Inc.
+
+package testcontext
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type spanContext uint64
+
+func (c *spanContext) SpanID() uint64                                    { return uint64(*c) }
+func (c *spanContext) TraceID() uint64                                   { return uint64(*c) }
+func (c *spanContext) ForeachBaggageItem(handler func(k, v string) bool) {}
+
+func newSpanContext(id uint64) *spanContext {
+	c := spanContext(id)
+	return &c
+}
+
+func TestActive(t *testing.T) {
+	assert.Nil(t, Active())
+
+	test, subtest := newSpanContext(1), newSpanContext(2)
+	endTest := Start(test, nil)
+	assert.Equal(t, test, Active())
+
+	endSubtest := Start(subtest, test)
+	assert.Equal(t, subtest, Active())
+	endSubtest()
+	assert.Equal(t, test, Active())
+
+	endTest()
+	assert.Nil(t, Active())
+}
+
+func TestActiveParallel(t *testing.T) {
+	test, subtest1, subtest2 := newSpanContext(1), newSpanContext(2), newSpanContext(3)
+	endTest := Start(test, nil)
+	endSubtest1 := Start(subtest1, test)
+	endSubtest2 := Start(subtest2, test)
+	assert.Nil(t, Active())
+
+	endSubtest1()
+	assert.Equal(t, subtest2, Active())
+	endSubtest2()
+
+	// The parallel subtests resume once their parent test returns.
+	endTest()
+	endSubtest1 = Start(subtest1, test)
+	endSubtest2 = Start(subtest2, test)
+	assert.Nil(t, Active())
+	endSubtest2()
+	assert.Equal(t, subtest1, Active())
+	endSubtest1()
+	assert.Nil(t, Active())
+}
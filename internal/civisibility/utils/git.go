@@ -6,8 +6,12 @@
 package utils
 
 import (
+	"bytes"
+	"context"
 	"errors"
+	"fmt"
 	"os/exec"
+	"path/filepath"
 	"regexp"
 	"strconv"
 	"strings"
@@ -102,3 +106,132 @@ func getLocalGitData() (localGitData, error) {
 func filterSensitiveInfo(url string) string {
 	return string(regexpSensitiveInfo.ReplaceAll([]byte(url), []byte("$1"))[:])
 }
+
+// gitHistorySince bounds the history of the repository uploaded to the backend.
+const gitHistorySince = "1 month ago"
+
+// execGit runs a git command bound to the given context, returning its trimmed standard output.
+func execGit(ctx context.Context, stdin []byte, args ...string) (string, error) {
+	cmd := exec.CommandContext(ctx, "git", args...)
+	if stdin != nil {
+		cmd.Stdin = bytes.NewReader(stdin)
+	}
+	out, err := cmd.Output()
+	if err != nil {
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) && len(exitErr.Stderr) > 0 {
+			return "", fmt.Errorf("git %s: %v: %s", args[0], err, strings.TrimSpace(string(exitErr.Stderr)))
+		}
+		return "", fmt.Errorf("git %s: %v", args[0], err)
+	}
+	return strings.TrimSpace(string(out)), nil
+}
+
+// GetLastLocalGitCommitShas returns the SHAs of the commits of the last month of the local repository,
+// starting from HEAD, up to 1000 commits.
+//
+// Parameters:
+//
+//	ctx - The context bounding the git command.
+//
+// Returns:
+//
+//	The commit SHAs, empty if the git command fails.
+func GetLastLocalGitCommitShas(ctx context.Context) []string {
+	out, err := execGit(ctx, nil, "log", "--format=%H", "-n", "1000", "--since="+gitHistorySince)
+	if err != nil || out == "" {
+		return nil
+	}
+	return strings.Split(out, "\n")
+}
+
+// IsShallowGitRepository returns true when the local repository is a shallow clone.
+//
+// Parameters:
+//
+//	ctx - The context bounding the git command.
+//
+// Returns:
+//
+//	True if the repository is shallow.
+func IsShallowGitRepository(ctx context.Context) bool {
+	out, err := execGit(ctx, nil, "rev-parse", "--is-shallow-repository")
+	return err == nil && out == "true"
+}
+
+// UnshallowGitRepository fetches the history of the last month of a shallow clone, without the file
+// contents, so that its commits and trees can be uploaded to the backend. It fetches the HEAD commit
+// from the default remote, falling back to the upstream branch and then to the whole remote.
+//
+// Parameters:
+//
+//	ctx - The context bounding the git commands.
+//
+// Returns:
+//
+//	An error if the repository couldn't be unshallowed.
+func UnshallowGitRepository(ctx context.Context) error {
+	remote, err := execGit(ctx, nil, "config", "--default", "origin", "--get", "clone.defaultRemoteName")
+	if err != nil || remote == "" {
+		remote = "origin"
+	}
+	fetchArgs := []string{"fetch", "--shallow-since=" + gitHistorySince, "--update-shallow", "--filter=blob:none", "--recurse-submodules=no", remote}
+
+	var refs []string
+	if head, err := execGit(ctx, nil, "rev-parse", "HEAD"); err == nil {
+		refs = append(refs, head)
+	}
+	if upstream, err := execGit(ctx, nil, "rev-parse", "--abbrev-ref", "--symbolic-full-name", "@{upstream}"); err == nil {
+		refs = append(refs, upstream)
+	}
+	for _, ref := range refs {
+		if _, err = execGit(ctx, nil, append(fetchArgs, ref)...); err == nil {
+			return nil
+		}
+	}
+	_, err = execGit(ctx, nil, fetchArgs...)
+	return err
+}
+
+// CreatePackFiles creates in the given directory the pack files of the objects of the commits to include,
+// excluding the objects reachable from the commits to exclude. File contents are left out, as the backend
+// only needs the commits and trees. Pack files are limited to 3MB each.
+//
+// Parameters:
+//
+//	ctx - The context bounding the git commands.
+//	commitsToInclude - The commits whose objects are packed.
+//	commitsToExclude - The commits already known, whose objects are not packed.
+//	dir - The directory of the pack files.
+//
+// Returns:
+//
+//	The paths of the pack files.
+//	An error if any git command fails.
+func CreatePackFiles(ctx context.Context, commitsToInclude []string, commitsToExclude []string, dir string) ([]string, error) {
+	revListArgs := []string{"rev-list", "--objects", "--no-object-names", "--filter=blob:none", "--since=" + gitHistorySince, "HEAD"}
+	for _, commit := range commitsToExclude {
+		revListArgs = append(revListArgs, "^"+commit)
+	}
+	revListArgs = append(revListArgs, commitsToInclude...)
+	objects, err := execGit(ctx, nil, revListArgs...)
+	if err != nil {
+		return nil, err
+	}
+	if objects == "" {
+		return nil, nil
+	}
+
+	prefix := filepath.Join(dir, "pack")
+	out, err := execGit(ctx, []byte(objects+"\n"), "pack-objects", "--compression=9", "--max-pack-size=3m", prefix)
+	if err != nil {
+		return nil, err
+	}
+	var packFiles []string
+	for _, hash := range strings.Split(out, "\n") {
+		if hash != "" {
+			packFiles = append(packFiles, fmt.Sprintf("%s-%s.pack", prefix, hash))
+		}
+	}
+	return packFiles, nil
+}
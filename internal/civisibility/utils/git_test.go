@@ -0,0 +1,86 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2024 Datadog, Inc.
+
+package utils
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newGitRepository creates a git repository with the given number of commits, returning its directory.
+func newGitRepository(t *testing.T, commits int) string {
+	dir := t.TempDir()
+	git(t, dir, "init", "-q")
+	for i := 0; i < commits; i++ {
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "file.txt"), []byte{byte('a' + i)}, 0o600))
+		git(t, dir, "add", "file.txt")
+		git(t, dir, "-c", "user.name=test", "-c", "user.email=test@example.com", "commit", "-q", "-m", "commit")
+	}
+	return dir
+}
+
+// git runs a git command in the given directory.
+func git(t *testing.T, dir string, args ...string) {
+	cmd := exec.Command("git", args...)
+	cmd.Dir = dir
+	out, err := cmd.CombinedOutput()
+	require.NoError(t, err, string(out))
+}
+
+// chdir changes the working directory for the duration of the test.
+func chdir(t *testing.T, dir string) {
+	wd, err := os.Getwd()
+	require.NoError(t, err)
+	require.NoError(t, os.Chdir(dir))
+	t.Cleanup(func() { _ = os.Chdir(wd) })
+}
+
+func TestGitUploadHelpers(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+	ctx := context.Background()
+	chdir(t, newGitRepository(t, 2))
+
+	commits := GetLastLocalGitCommitShas(ctx)
+	require.Len(t, commits, 2)
+	assert.False(t, IsShallowGitRepository(ctx))
+
+	// Pack the last commit, excluding the first one
+	packFiles, err := CreatePackFiles(ctx, commits[:1], commits[1:], t.TempDir())
+	require.NoError(t, err)
+	require.Len(t, packFiles, 1)
+	assert.FileExists(t, packFiles[0])
+
+	// Nothing to pack when every commit is known
+	packFiles, err = CreatePackFiles(ctx, nil, commits, t.TempDir())
+	require.NoError(t, err)
+	assert.Empty(t, packFiles)
+}
+
+func TestUnshallowGitRepository(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+	ctx := context.Background()
+	origin := newGitRepository(t, 3)
+	clone := filepath.Join(t.TempDir(), "clone")
+	git(t, origin, "clone", "-q", "--depth=1", "file://"+origin, clone)
+	chdir(t, clone)
+
+	require.True(t, IsShallowGitRepository(ctx))
+	require.Len(t, GetLastLocalGitCommitShas(ctx), 1)
+
+	require.NoError(t, UnshallowGitRepository(ctx))
+	assert.False(t, IsShallowGitRepository(ctx))
+	assert.Len(t, GetLastLocalGitCommitShas(ctx), 3)
+}
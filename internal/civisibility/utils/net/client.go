@@ -0,0 +1,171 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2024 Datadog, Inc.
+
+package net
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"net/http"
+	"os"
+	"runtime"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"gopkg.in/DataDog/dd-trace-go.v1/internal/civisibility/constants"
+	"gopkg.in/DataDog/dd-trace-go.v1/internal/civisibility/utils"
+	"gopkg.in/DataDog/dd-trace-go.v1/internal/version"
+)
+
+// Constants for the CI Visibility backend API.
+const (
+	// DefaultMaxRetries is the maximum number of attempts of a request.
+	DefaultMaxRetries = 3
+
+	// DefaultBackoff is the delay before retrying a request, doubled after every attempt.
+	DefaultBackoff = 150 * time.Millisecond
+
+	// DefaultTimeout is the timeout of a request.
+	DefaultTimeout = 30 * time.Second
+
+	apiSubdomain = "api" // Subdomain of the backend API.
+)
+
+// Ensure that client implements the Client interface.
+var _ Client = (*client)(nil)
+
+type (
+	// Client is the CI Visibility backend API client.
+	Client interface {
+		// GetSettings returns the CI Visibility settings of the repository and service.
+		GetSettings() (*SettingsResponseData, error)
+
+		// GetKnownTests returns the tests of the repository and service known by the backend.
+		GetKnownTests() (*KnownTestsResponseData, error)
+
+		// GetSkippableTests returns the tests of the repository and service that can be skipped for the current commit.
+		GetSkippableTests() (*SkippableTestsResponseData, error)
+
+		// GetCommits returns the commits among the given local commits that are already known by the backend.
+		GetCommits(localCommits []string) ([]string, error)
+
+		// SendPackFiles uploads the given pack files of the repository objects for the given commit, returning
+		// the number of bytes sent.
+		SendPackFiles(commitSha string, packFiles []string) (bytes int64, err error)
+	}
+
+	// client is the Client implementation, sending the requests to the intake set by the tracer.
+	client struct {
+		ctx                context.Context
+		id                 string
+		baseURL            string
+		environment        string
+		serviceName        string
+		repositoryURL      string
+		commitSha          string
+		branchName         string
+		testConfigurations testConfigurations
+		headers            map[string]string
+		httpClient         *http.Client
+	}
+
+	// testConfigurations holds the configurations of the test environment, which together
+	// with the repository and service identify the test results of the backend.
+	testConfigurations struct {
+		OsPlatform     string `json:"os.platform,omitempty"`
+		OsVersion      string `json:"os.version,omitempty"`
+		OsArchitecture string `json:"os.architecture,omitempty"`
+		RuntimeName    string `json:"runtime.name,omitempty"`
+		RuntimeVersion string `json:"runtime.version,omitempty"`
+	}
+)
+
+// Intake locates the CI Visibility backend API. The tracer sets it to the intake it sends the test
+// events to, so that the client follows the tracer configuration (agent address, agentless mode...).
+type Intake interface {
+	// IntakeURL returns the URL of the given path of the intake of the given subdomain, and adds
+	// the headers required to reach it to the given headers.
+	IntakeURL(subdomain, path string, headers map[string]string) string
+
+	// HTTPClient returns the HTTP client reaching the intake.
+	HTTPClient() *http.Client
+}
+
+var (
+	// intake is the CI Visibility intake set by the tracer, if any.
+	intake Intake
+
+	// intakeMutex synchronizes access to intake.
+	intakeMutex sync.RWMutex
+)
+
+// SetIntake sets the CI Visibility intake the clients send their requests to.
+func SetIntake(i Intake) {
+	intakeMutex.Lock()
+	defer intakeMutex.Unlock()
+	intake = i
+}
+
+// NewClient creates a new CI Visibility backend API client for the given service, sending its
+// requests to the intake set by the tracer. It returns nil when there is no intake, as the tracer
+// isn't started in CI Visibility mode.
+func NewClient(serviceName string) Client {
+	return NewClientWithContext(context.Background(), serviceName)
+}
+
+// NewClientWithContext is like NewClient, but the requests of the client, retries included, are
+// cancelled once ctx is done.
+func NewClientWithContext(ctx context.Context, serviceName string) Client {
+	intakeMutex.RLock()
+	i := intake
+	intakeMutex.RUnlock()
+	if i == nil {
+		return nil
+	}
+
+	ciTags := utils.GetCiTags()
+
+	defaultHeaders := map[string]string{
+		"Datadog-Meta-Lang":           "go",
+		"Datadog-Meta-Lang-Version":   strings.TrimPrefix(runtime.Version(), "go"),
+		"Datadog-Meta-Tracer-Version": version.Tag,
+	}
+	baseURL := strings.TrimSuffix(i.IntakeURL(apiSubdomain, "", defaultHeaders), "/")
+
+	// The API responses can be larger than the tracer payloads: keep the transport of the
+	// tracer (unix sockets...) with a longer timeout.
+	httpClient := &http.Client{Timeout: DefaultTimeout}
+	if c := i.HTTPClient(); c != nil {
+		httpClient.Transport = c.Transport
+	}
+
+	return &client{
+		ctx:           ctx,
+		id:            strconv.FormatUint(rand.Uint64(), 16),
+		baseURL:       baseURL,
+		environment:   os.Getenv("DD_ENV"),
+		serviceName:   serviceName,
+		repositoryURL: ciTags[constants.GitRepositoryURL],
+		commitSha:     ciTags[constants.GitCommitSHA],
+		branchName:    ciTags[constants.GitBranch],
+		testConfigurations: testConfigurations{
+			OsPlatform:     ciTags[constants.OSPlatform],
+			OsVersion:      ciTags[constants.OSVersion],
+			OsArchitecture: ciTags[constants.OSArchitecture],
+			RuntimeName:    ciTags[constants.RuntimeName],
+			RuntimeVersion: ciTags[constants.RuntimeVersion],
+		},
+		headers:    defaultHeaders,
+		httpClient: httpClient,
+	}
+}
+
+// getURLPath returns the URL of the given API path.
+func (c *client) getURLPath(urlPath string) string {
+	return fmt.Sprintf("%s/%s", c.baseURL, urlPath)
+}
@@ -0,0 +1,229 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2024 Datadog, Inc.
+
+package net
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// request is a request received by the backend stand-in.
+type request struct {
+	path    string
+	headers http.Header
+	payload []byte
+	body    map[string]any
+}
+
+// newBackendStandIn starts a stand-in of the CI Visibility backend answering every request with the
+// given status code and body, and recording the requests.
+func newBackendStandIn(t *testing.T, statusCode int, response string) (*httptest.Server, *[]request) {
+	var requests []request
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		payload, _ := io.ReadAll(r.Body)
+		var body map[string]any
+		_ = json.Unmarshal(payload, &body)
+		requests = append(requests, request{path: r.URL.Path, headers: r.Header, payload: payload, body: body})
+		w.WriteHeader(statusCode)
+		_, _ = w.Write([]byte(response))
+	}))
+	t.Cleanup(server.Close)
+	return server, &requests
+}
+
+// testIntake is an intake reached at url, with an API key.
+type testIntake struct {
+	url       string
+	subdomain string
+}
+
+func (i *testIntake) IntakeURL(subdomain, path string, headers map[string]string) string {
+	i.subdomain = subdomain
+	headers["dd-api-key"] = "api-key"
+	return i.url + "/" + path
+}
+
+func (i *testIntake) HTTPClient() *http.Client { return nil }
+
+// setTestIntake sets the intake of the clients to a test intake reached at url for the duration of the test.
+func setTestIntake(t *testing.T, url string) *testIntake {
+	intake := &testIntake{url: url}
+	SetIntake(intake)
+	t.Cleanup(func() { SetIntake(nil) })
+	return intake
+}
+
+func TestNewClient(t *testing.T) {
+	assert.Nil(t, NewClient("my-service"))
+
+	server, requests := newBackendStandIn(t, http.StatusServiceUnavailable, "unavailable")
+	setTestIntake(t, server.URL)
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err := NewClientWithContext(ctx, "my-service").GetSettings()
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.Empty(t, *requests)
+}
+
+func TestGetSettings(t *testing.T) {
+	server, requests := newBackendStandIn(t, http.StatusOK, `{"data":{"id":"1","type":"ci_app_tracers_test_service_settings","attributes":{
+		"code_coverage":true,
+		"early_flake_detection":{"enabled":true,"slow_test_retries":{"5s":10,"10s":5,"30s":3,"5m":2},"faulty_session_threshold":30},
+		"itr_enabled":true}}}`)
+	setTestIntake(t, server.URL)
+	t.Setenv("DD_ENV", "ci")
+
+	settings, err := NewClient("my-service").GetSettings()
+	require.NoError(t, err)
+	assert.True(t, settings.CodeCoverage)
+	assert.True(t, settings.ItrEnabled)
+	assert.False(t, settings.TestsSkipping)
+	assert.True(t, settings.EarlyFlakeDetection.Enabled)
+	assert.Equal(t, 10, settings.EarlyFlakeDetection.SlowTestRetries.FiveS)
+	assert.Equal(t, 5, settings.EarlyFlakeDetection.SlowTestRetries.TenS)
+	assert.Equal(t, 3, settings.EarlyFlakeDetection.SlowTestRetries.ThirtyS)
+	assert.Equal(t, 2, settings.EarlyFlakeDetection.SlowTestRetries.FiveM)
+	assert.Equal(t, 30, settings.EarlyFlakeDetection.FaultySessionThreshold)
+
+	require.Len(t, *requests, 1)
+	req := (*requests)[0]
+	assert.Equal(t, "/"+settingsURLPath, req.path)
+	assert.Equal(t, "api-key", req.headers.Get("dd-api-key"))
+	assert.Equal(t, "application/json", req.headers.Get("Content-Type"))
+	data := req.body["data"].(map[string]any)
+	assert.Equal(t, settingsRequestType, data["type"])
+	attributes := data["attributes"].(map[string]any)
+	assert.Equal(t, "my-service", attributes["service"])
+	assert.Equal(t, "ci", attributes["env"])
+	assert.Equal(t, "test", attributes["test_level"])
+	assert.Contains(t, attributes["configurations"], "os.platform")
+}
+
+func TestGetKnownTests(t *testing.T) {
+	server, requests := newBackendStandIn(t, http.StatusOK, `{"data":{"id":"1","type":"ci_app_libraries_tests","attributes":{
+		"tests":{"module":{"suite":["TestA","TestB"]}}}}}`)
+	intake := setTestIntake(t, server.URL+"/evp_proxy/v2")
+
+	knownTests, err := NewClient("my-service").GetKnownTests()
+	require.NoError(t, err)
+	assert.True(t, knownTests.Contains("module", "suite", "TestA"))
+	assert.True(t, knownTests.Contains("module", "suite", "TestB"))
+	assert.False(t, knownTests.Contains("module", "suite", "TestC"))
+	assert.False(t, knownTests.Contains("module", "other", "TestA"))
+
+	require.Len(t, *requests, 1)
+	req := (*requests)[0]
+	assert.Equal(t, "/evp_proxy/v2/"+knownTestsURLPath, req.path)
+	assert.Equal(t, apiSubdomain, intake.subdomain)
+	data := req.body["data"].(map[string]any)
+	assert.Equal(t, knownTestsRequestType, data["type"])
+	assert.Equal(t, "my-service", data["attributes"].(map[string]any)["service"])
+}
+
+func TestGetSkippableTests(t *testing.T) {
+	server, requests := newBackendStandIn(t, http.StatusOK, `{"meta":{"correlation_id":"correlation"},"data":[
+		{"id":"1","type":"test","attributes":{"suite":"suite","name":"TestA"}},
+		{"id":"2","type":"test","attributes":{"suite":"suite","name":"TestB","configurations":{"test.bundle":"module"}}}]}`)
+	setTestIntake(t, server.URL)
+
+	skippableTests, err := NewClient("my-service").GetSkippableTests()
+	require.NoError(t, err)
+	assert.Equal(t, "correlation", skippableTests.CorrelationID)
+	assert.True(t, skippableTests.Contains("module", "suite", "TestA"))
+	assert.True(t, skippableTests.Contains("other", "suite", "TestA"))
+	assert.True(t, skippableTests.Contains("module", "suite", "TestB"))
+	assert.False(t, skippableTests.Contains("other", "suite", "TestB"))
+	assert.False(t, skippableTests.Contains("module", "suite", "TestC"))
+
+	require.Len(t, *requests, 1)
+	req := (*requests)[0]
+	assert.Equal(t, "/"+skippableURLPath, req.path)
+	data := req.body["data"].(map[string]any)
+	assert.Equal(t, skippableRequestType, data["type"])
+	attributes := data["attributes"].(map[string]any)
+	assert.Equal(t, "my-service", attributes["service"])
+	assert.Equal(t, "test", attributes["test_level"])
+}
+
+func TestGetCommits(t *testing.T) {
+	server, requests := newBackendStandIn(t, http.StatusOK, `{"data":[{"id":"abc","type":"commit"}]}`)
+	setTestIntake(t, server.URL)
+
+	commits, err := NewClient("my-service").GetCommits([]string{"abc", "def"})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"abc"}, commits)
+
+	require.Len(t, *requests, 1)
+	req := (*requests)[0]
+	assert.Equal(t, "/"+searchCommitsURLPath, req.path)
+	assert.Equal(t, []any{
+		map[string]any{"id": "abc", "type": searchCommitsType},
+		map[string]any{"id": "def", "type": searchCommitsType},
+	}, req.body["data"])
+	assert.Contains(t, req.body["meta"], "repository_url")
+}
+
+func TestSendPackFiles(t *testing.T) {
+	server, requests := newBackendStandIn(t, http.StatusNoContent, "")
+	setTestIntake(t, server.URL)
+	packFile := filepath.Join(t.TempDir(), "pack-1.pack")
+	require.NoError(t, os.WriteFile(packFile, []byte("PACK"), 0o600))
+
+	bytes, err := NewClient("my-service").SendPackFiles("abc", []string{packFile})
+	require.NoError(t, err)
+	assert.EqualValues(t, 4, bytes)
+
+	require.Len(t, *requests, 1)
+	req := (*requests)[0]
+	assert.Equal(t, "/"+packFileURLPath, req.path)
+	mediaType, params, err := mime.ParseMediaType(req.headers.Get("Content-Type"))
+	require.NoError(t, err)
+	assert.Equal(t, "multipart/form-data", mediaType)
+	form, err := multipart.NewReader(strings.NewReader(string(req.payload)), params["boundary"]).ReadForm(1 << 20)
+	require.NoError(t, err)
+	require.Len(t, form.File["pushedSha"], 1)
+	require.Len(t, form.File["packfile"], 1)
+	assert.Equal(t, "pack-1.pack", form.File["packfile"][0].Filename)
+
+	f, err := form.File["pushedSha"][0].Open()
+	require.NoError(t, err)
+	defer f.Close()
+	var pushedSha map[string]any
+	require.NoError(t, json.NewDecoder(f).Decode(&pushedSha))
+	assert.Equal(t, map[string]any{"id": "abc", "type": searchCommitsType}, pushedSha["data"])
+}
+
+func TestRequestErrors(t *testing.T) {
+	t.Run("retried", func(t *testing.T) {
+		server, requests := newBackendStandIn(t, http.StatusServiceUnavailable, "unavailable")
+		setTestIntake(t, server.URL)
+
+		_, err := NewClient("my-service").GetSettings()
+		assert.ErrorContains(t, err, "unavailable")
+		assert.Len(t, *requests, DefaultMaxRetries)
+	})
+
+	t.Run("not-retried", func(t *testing.T) {
+		server, requests := newBackendStandIn(t, http.StatusForbidden, "")
+		setTestIntake(t, server.URL)
+
+		_, err := NewClient("my-service").GetKnownTests()
+		assert.EqualError(t, err, http.StatusText(http.StatusForbidden))
+		assert.Len(t, *requests, 1)
+	})
+}
@@ -0,0 +1,89 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2024 Datadog, Inc.
+
+package net
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+const (
+	searchCommitsType    = "commit"
+	searchCommitsURLPath = "api/v2/git/repository/search_commits"
+	packFileURLPath      = "api/v2/git/repository/packfile"
+)
+
+type (
+	searchCommits struct {
+		Data []searchCommitsData `json:"data"`
+		Meta searchCommitsMeta   `json:"meta"`
+	}
+
+	searchCommitsData struct {
+		ID   string `json:"id"`
+		Type string `json:"type"`
+	}
+
+	searchCommitsMeta struct {
+		RepositoryURL string `json:"repository_url"`
+	}
+
+	pushedShaBody struct {
+		Data searchCommitsData `json:"data"`
+		Meta searchCommitsMeta `json:"meta"`
+	}
+)
+
+// GetCommits returns the commits among the given local commits that are already known by the backend.
+func (c *client) GetCommits(localCommits []string) ([]string, error) {
+	body := searchCommits{
+		Data: make([]searchCommitsData, 0, len(localCommits)),
+		Meta: searchCommitsMeta{RepositoryURL: c.repositoryURL},
+	}
+	for _, commit := range localCommits {
+		body.Data = append(body.Data, searchCommitsData{ID: commit, Type: searchCommitsType})
+	}
+
+	var response searchCommits
+	if err := c.sendJSON(searchCommitsURLPath, body, &response); err != nil {
+		return nil, err
+	}
+	commits := make([]string, 0, len(response.Data))
+	for _, commit := range response.Data {
+		commits = append(commits, commit.ID)
+	}
+	return commits, nil
+}
+
+// SendPackFiles uploads the given pack files of the repository objects for the given commit, returning
+// the number of bytes sent.
+func (c *client) SendPackFiles(commitSha string, packFiles []string) (bytes int64, err error) {
+	pushedSha, err := json.Marshal(pushedShaBody{
+		Data: searchCommitsData{ID: commitSha, Type: searchCommitsType},
+		Meta: searchCommitsMeta{RepositoryURL: c.repositoryURL},
+	})
+	if err != nil {
+		return 0, fmt.Errorf("cannot encode request body: %v", err)
+	}
+
+	for _, packFile := range packFiles {
+		content, err := os.ReadFile(packFile)
+		if err != nil {
+			return bytes, err
+		}
+		err = c.sendMultipart(packFileURLPath, []formFile{
+			{FieldName: "pushedSha", FileName: "pushedSha.json", ContentType: "application/json", Content: pushedSha},
+			{FieldName: "packfile", FileName: filepath.Base(packFile), ContentType: "application/octet-stream", Content: content},
+		})
+		if err != nil {
+			return bytes, err
+		}
+		bytes += int64(len(content))
+	}
+	return bytes, nil
+}
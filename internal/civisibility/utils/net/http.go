@@ -0,0 +1,119 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2024 Datadog, Inc.
+
+package net
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/textproto"
+	"time"
+
+	"gopkg.in/DataDog/dd-trace-go.v1/internal/log"
+)
+
+// formFile is a file part of a multipart form request.
+type formFile struct {
+	FieldName   string
+	FileName    string
+	ContentType string
+	Content     []byte
+}
+
+// sendJSON sends a POST request with the JSON encoding of body to the given API path, and decodes
+// the JSON response into response.
+func (c *client) sendJSON(urlPath string, body any, response any) error {
+	payload, err := json.Marshal(body)
+	if err != nil {
+		return fmt.Errorf("cannot encode request body: %v", err)
+	}
+	return c.sendRequest(urlPath, "application/json", payload, response)
+}
+
+// sendMultipart sends a POST request with a multipart form made of the given files to the given API path.
+func (c *client) sendMultipart(urlPath string, files []formFile) error {
+	var body bytes.Buffer
+	form := multipart.NewWriter(&body)
+	for _, file := range files {
+		h := make(textproto.MIMEHeader)
+		h.Set("Content-Disposition", fmt.Sprintf(`form-data; name="%s"; filename="%s"`, file.FieldName, file.FileName))
+		h.Set("Content-Type", file.ContentType)
+		part, err := form.CreatePart(h)
+		if err != nil {
+			return fmt.Errorf("cannot create multipart form: %v", err)
+		}
+		if _, err := part.Write(file.Content); err != nil {
+			return fmt.Errorf("cannot create multipart form: %v", err)
+		}
+	}
+	if err := form.Close(); err != nil {
+		return fmt.Errorf("cannot create multipart form: %v", err)
+	}
+	return c.sendRequest(urlPath, form.FormDataContentType(), body.Bytes(), nil)
+}
+
+// sendRequest sends a POST request with the given payload to the given API path, and decodes the JSON
+// response into response, if any. Network errors, throttling and server errors are retried up to
+// DefaultMaxRetries attempts with an exponential backoff, until the context of the client is done.
+func (c *client) sendRequest(urlPath string, contentType string, payload []byte, response any) error {
+	var err error
+	url := c.getURLPath(urlPath)
+	backoff := DefaultBackoff
+	for attempt := 1; ; attempt++ {
+		var retryable bool
+		retryable, err = c.doRequest(url, contentType, payload, response)
+		if err == nil || !retryable || attempt >= DefaultMaxRetries {
+			return err
+		}
+		log.Debug("civisibility: request to %s failed (attempt %d), will retry: %v", url, attempt, err)
+		select {
+		case <-time.After(backoff):
+		case <-c.ctx.Done():
+			return err
+		}
+		backoff *= 2
+	}
+}
+
+// doRequest sends a single request, returning whether it can be retried when it fails.
+func (c *client) doRequest(url string, contentType string, payload []byte, response any) (retryable bool, err error) {
+	req, err := http.NewRequestWithContext(c.ctx, http.MethodPost, url, bytes.NewReader(payload))
+	if err != nil {
+		return false, fmt.Errorf("cannot create http request: %v", err)
+	}
+	for header, value := range c.headers {
+		req.Header.Set(header, value)
+	}
+	req.Header.Set("Content-Type", contentType)
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return true, err
+	}
+	defer resp.Body.Close()
+	if code := resp.StatusCode; code >= 400 {
+		// error, check the body for context information and
+		// return a nice error.
+		msg := make([]byte, 1000)
+		n, _ := io.ReadFull(resp.Body, msg)
+		txt := http.StatusText(code)
+		retryable = code == http.StatusTooManyRequests || code >= 500
+		if n > 0 {
+			return retryable, fmt.Errorf("%s (Status: %s)", msg[:n], txt)
+		}
+		return retryable, fmt.Errorf("%s", txt)
+	}
+	if response == nil {
+		return false, nil
+	}
+	if err := json.NewDecoder(resp.Body).Decode(response); err != nil {
+		return false, fmt.Errorf("cannot decode response body: %v", err)
+	}
+	return false, nil
+}
@@ -0,0 +1,81 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2024 Datadog, Inc.
+
+package net
+
+const (
+	knownTestsRequestType = "ci_app_libraries_tests_request"
+	knownTestsURLPath     = "api/v2/ci/libraries/tests"
+)
+
+type (
+	knownTestsRequest struct {
+		Data knownTestsRequestHeader `json:"data"`
+	}
+
+	knownTestsRequestHeader struct {
+		ID         string                `json:"id"`
+		Type       string                `json:"type"`
+		Attributes knownTestsRequestData `json:"attributes"`
+	}
+
+	knownTestsRequestData struct {
+		Service        string             `json:"service,omitempty"`
+		Env            string             `json:"env,omitempty"`
+		RepositoryURL  string             `json:"repository_url,omitempty"`
+		Configurations testConfigurations `json:"configurations,omitempty"`
+	}
+
+	knownTestsResponse struct {
+		Data struct {
+			ID         string                 `json:"id"`
+			Type       string                 `json:"type"`
+			Attributes KnownTestsResponseData `json:"attributes"`
+		} `json:"data"`
+	}
+
+	// KnownTestsResponseData holds the tests known by the backend, by module and suite.
+	KnownTestsResponseData struct {
+		Tests KnownTestsModules `json:"tests"`
+	}
+
+	// KnownTestsModules maps the module names to their known suites.
+	KnownTestsModules map[string]KnownTestsSuites
+
+	// KnownTestsSuites maps the suite names to their known test names.
+	KnownTestsSuites map[string][]string
+)
+
+// GetKnownTests returns the tests of the repository and service known by the backend.
+func (c *client) GetKnownTests() (*KnownTestsResponseData, error) {
+	body := knownTestsRequest{
+		Data: knownTestsRequestHeader{
+			ID:   c.id,
+			Type: knownTestsRequestType,
+			Attributes: knownTestsRequestData{
+				Service:        c.serviceName,
+				Env:            c.environment,
+				RepositoryURL:  c.repositoryURL,
+				Configurations: c.testConfigurations,
+			},
+		},
+	}
+
+	var response knownTestsResponse
+	if err := c.sendJSON(knownTestsURLPath, body, &response); err != nil {
+		return nil, err
+	}
+	return &response.Data.Attributes, nil
+}
+
+// Contains returns true when the given test is known.
+func (d *KnownTestsResponseData) Contains(module, suite, test string) bool {
+	for _, name := range d.Tests[module][suite] {
+		if name == test {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,94 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2024 Datadog, Inc.
+
+package net
+
+const (
+	settingsRequestType = "ci_app_test_service_libraries_settings"
+	settingsURLPath     = "api/v2/libraries/tests/services/setting"
+)
+
+type (
+	settingsRequest struct {
+		Data settingsRequestHeader `json:"data"`
+	}
+
+	settingsRequestHeader struct {
+		ID         string              `json:"id"`
+		Type       string              `json:"type"`
+		Attributes settingsRequestData `json:"attributes"`
+	}
+
+	settingsRequestData struct {
+		Service        string             `json:"service,omitempty"`
+		Env            string             `json:"env,omitempty"`
+		RepositoryURL  string             `json:"repository_url,omitempty"`
+		Branch         string             `json:"branch,omitempty"`
+		Sha            string             `json:"sha,omitempty"`
+		Configurations testConfigurations `json:"configurations,omitempty"`
+		TestLevel      string             `json:"test_level,omitempty"`
+	}
+
+	settingsResponse struct {
+		Data struct {
+			ID         string               `json:"id"`
+			Type       string               `json:"type"`
+			Attributes SettingsResponseData `json:"attributes"`
+		} `json:"data"`
+	}
+
+	// SettingsResponseData holds the CI Visibility settings of a repository and service.
+	SettingsResponseData struct {
+		CodeCoverage            bool                        `json:"code_coverage"`
+		EarlyFlakeDetection     EarlyFlakeDetectionSettings `json:"early_flake_detection"`
+		FlakyTestRetriesEnabled bool                        `json:"flaky_test_retries_enabled"`
+		ItrEnabled              bool                        `json:"itr_enabled"`
+		RequireGit              bool                        `json:"require_git"`
+		TestsSkipping           bool                        `json:"tests_skipping"`
+	}
+
+	// EarlyFlakeDetectionSettings holds the Early Flake Detection settings.
+	EarlyFlakeDetectionSettings struct {
+		Enabled bool `json:"enabled"`
+
+		// SlowTestRetries holds the number of retries of a new test, depending on the duration
+		// of its first execution.
+		SlowTestRetries struct {
+			FiveS   int `json:"5s"`
+			TenS    int `json:"10s"`
+			ThirtyS int `json:"30s"`
+			FiveM   int `json:"5m"`
+		} `json:"slow_test_retries"`
+
+		// FaultySessionThreshold is the percentage of new tests above which the session is
+		// considered faulty, and Early Flake Detection is disabled.
+		FaultySessionThreshold int `json:"faulty_session_threshold"`
+	}
+)
+
+// GetSettings returns the CI Visibility settings of the repository and service.
+func (c *client) GetSettings() (*SettingsResponseData, error) {
+	body := settingsRequest{
+		Data: settingsRequestHeader{
+			ID:   c.id,
+			Type: settingsRequestType,
+			Attributes: settingsRequestData{
+				Service:        c.serviceName,
+				Env:            c.environment,
+				RepositoryURL:  c.repositoryURL,
+				Branch:         c.branchName,
+				Sha:            c.commitSha,
+				Configurations: c.testConfigurations,
+				TestLevel:      "test",
+			},
+		},
+	}
+
+	var response settingsResponse
+	if err := c.sendJSON(settingsURLPath, body, &response); err != nil {
+		return nil, err
+	}
+	return &response.Data.Attributes, nil
+}
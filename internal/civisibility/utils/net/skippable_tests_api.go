@@ -0,0 +1,111 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2024 Datadog, Inc.
+
+package net
+
+const (
+	skippableRequestType = "test_params"
+	skippableURLPath     = "api/v2/ci/tests/skippable"
+
+	// skippableModuleConfiguration is the configuration restricting a skippable test to a module.
+	skippableModuleConfiguration = "test.bundle"
+)
+
+type (
+	skippableRequest struct {
+		Data skippableRequestHeader `json:"data"`
+	}
+
+	skippableRequestHeader struct {
+		Type       string               `json:"type"`
+		Attributes skippableRequestData `json:"attributes"`
+	}
+
+	skippableRequestData struct {
+		TestLevel      string             `json:"test_level"`
+		Configurations testConfigurations `json:"configurations,omitempty"`
+		Service        string             `json:"service,omitempty"`
+		Env            string             `json:"env,omitempty"`
+		RepositoryURL  string             `json:"repository_url,omitempty"`
+		Sha            string             `json:"sha,omitempty"`
+	}
+
+	skippableResponse struct {
+		Meta struct {
+			CorrelationID string `json:"correlation_id"`
+		} `json:"meta"`
+		Data []struct {
+			ID         string        `json:"id"`
+			Type       string        `json:"type"`
+			Attributes SkippableTest `json:"attributes"`
+		} `json:"data"`
+	}
+
+	// SkippableTestsResponseData holds the tests that can be skipped for the current commit, as they
+	// aren't affected by its changes.
+	SkippableTestsResponseData struct {
+		// CorrelationID identifies the response, to correlate the test events with the skipped tests.
+		CorrelationID string
+
+		// Tests holds the skippable tests, by suite and name.
+		Tests SkippableTestsSuites
+	}
+
+	// SkippableTestsSuites maps the suite names to their skippable tests, by name.
+	SkippableTestsSuites map[string]map[string][]SkippableTest
+
+	// SkippableTest is a test that can be skipped.
+	SkippableTest struct {
+		Suite          string            `json:"suite"`
+		Name           string            `json:"name"`
+		Parameters     string            `json:"parameters"`
+		Configurations map[string]string `json:"configurations"`
+	}
+)
+
+// GetSkippableTests returns the tests of the repository and service that can be skipped for the current commit.
+func (c *client) GetSkippableTests() (*SkippableTestsResponseData, error) {
+	body := skippableRequest{
+		Data: skippableRequestHeader{
+			Type: skippableRequestType,
+			Attributes: skippableRequestData{
+				TestLevel:      "test",
+				Configurations: c.testConfigurations,
+				Service:        c.serviceName,
+				Env:            c.environment,
+				RepositoryURL:  c.repositoryURL,
+				Sha:            c.commitSha,
+			},
+		},
+	}
+
+	var response skippableResponse
+	if err := c.sendJSON(skippableURLPath, body, &response); err != nil {
+		return nil, err
+	}
+
+	data := &SkippableTestsResponseData{
+		CorrelationID: response.Meta.CorrelationID,
+		Tests:         SkippableTestsSuites{},
+	}
+	for _, item := range response.Data {
+		test := item.Attributes
+		if data.Tests[test.Suite] == nil {
+			data.Tests[test.Suite] = map[string][]SkippableTest{}
+		}
+		data.Tests[test.Suite][test.Name] = append(data.Tests[test.Suite][test.Name], test)
+	}
+	return data, nil
+}
+
+// Contains returns true when the given test can be skipped.
+func (d *SkippableTestsResponseData) Contains(module, suite, test string) bool {
+	for _, skippable := range d.Tests[suite][test] {
+		if bundle, ok := skippable.Configurations[skippableModuleConfiguration]; !ok || bundle == module {
+			return true
+		}
+	}
+	return false
+}
@@ -32,6 +32,13 @@ const (
 	defaultServiceName        = "unnamed-go-service"
 )
 
+const (
+	// SchemaOperationSerialization is reported for schemas used by producers.
+	SchemaOperationSerialization = "serialization"
+	// SchemaOperationDeserialization is reported for schemas used by consumers.
+	SchemaOperationDeserialization = "deserialization"
+)
+
 // use the same gamma and index offset as the Datadog backend, to avoid doing any conversions in
 // the backend that would lead to a loss of precision
 var sketchMapping, _ = mapping.NewLogarithmicMappingWithGamma(1.015625, 1.8761281912861705)
@@ -176,6 +183,7 @@ type kafkaOffset struct {
 type Processor struct {
 	in                   *fastQueue
 	hashCache            *hashCache
+	schemaSampler        *schemaSampler
 	inKafka              chan kafkaOffset
 	tsTypeCurrentBuckets map[int64]bucket
 	tsTypeOriginBuckets  map[int64]bucket
@@ -211,6 +219,7 @@ func NewProcessor(statsd internal.StatsdClient, env, service, version string, ag
 		tsTypeCurrentBuckets:        make(map[int64]bucket),
 		tsTypeOriginBuckets:         make(map[int64]bucket),
 		hashCache:                   newHashCache(),
+		schemaSampler:               newSchemaSampler(internal.DurationEnv("DD_DATA_STREAMS_SCHEMA_SAMPLING_INTERVAL", defaultSchemaSamplingInterval)),
 		in:                          newFastQueue(),
 		stopped:                     1,
 		statsd:                      statsd,
@@ -422,6 +431,9 @@ func (p *Processor) flush(now time.Time) StatsPayload {
 		}
 		sp.Stats = append(sp.Stats, p.flushBucket(p.tsTypeOriginBuckets, ts, TimestampTypeOrigin))
 	}
+	// now may be in the future to flush every bucket, which must not evict the
+	// schema samples of the current interval
+	p.schemaSampler.evict(p.time())
 	return sp
 }
 
@@ -468,6 +480,15 @@ func (p *Processor) SetCheckpointWithParams(ctx context.Context, params options.
 	return ContextWithPathway(ctx, child)
 }
 
+// TrySampleSchema records that the schema identified by schemaID was used by the
+// given operation on the given topic. It reports whether the schema should be
+// sent, and the number of uses the sample stands for since the previous one.
+// A schema seen for the first time on a topic is always sampled, so schema
+// changes are reported right away.
+func (p *Processor) TrySampleSchema(topic, operation, schemaID string) (weight int64, ok bool) {
+	return p.schemaSampler.trySample(operation+"|"+topic+"|"+schemaID, p.time())
+}
+
 func (p *Processor) TrackKafkaCommitOffset(group string, topic string, partition int32, offset int64) {
 	dropped := p.in.push(&processorInput{typ: pointTypeKafkaOffset, kafkaOffset: kafkaOffset{
 		offset:     offset,
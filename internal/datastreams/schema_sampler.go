@@ -0,0 +1,71 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package datastreams
+
+import (
+	"sync"
+	"time"
+)
+
+// defaultSchemaSamplingInterval is the minimum time between two samples of the
+// schema used for a given topic and operation.
+const defaultSchemaSamplingInterval = 30 * time.Second
+
+// schemaSampler decides which schemas are reported. It keeps one sample per
+// interval and per key, and counts how many schemas were seen in between so
+// that the sampled schema can be weighted accordingly.
+type schemaSampler struct {
+	mu       sync.Mutex
+	interval time.Duration
+	samples  map[string]*schemaSample
+}
+
+type schemaSample struct {
+	last   time.Time
+	weight int64
+}
+
+func newSchemaSampler(interval time.Duration) *schemaSampler {
+	return &schemaSampler{
+		interval: interval,
+		samples:  make(map[string]*schemaSample),
+	}
+}
+
+// trySample records one occurrence of the schema identified by key. It returns
+// true if the schema should be reported, along with the number of occurrences
+// it represents.
+func (s *schemaSampler) trySample(key string, now time.Time) (weight int64, ok bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	sample, found := s.samples[key]
+	if !found {
+		sample = &schemaSample{}
+		s.samples[key] = sample
+	}
+	sample.weight++
+	if found && now.Sub(sample.last) < s.interval {
+		return 0, false
+	}
+	weight = sample.weight
+	sample.last = now
+	sample.weight = 0
+	return weight, true
+}
+
+// evict removes the samples whose last schema was sampled more than one
+// interval ago, so that the samples of the schemas no longer seen do not
+// accumulate. The occurrences counted since are dropped, as the next occurrence
+// of such a schema is sampled right away anyway.
+func (s *schemaSampler) evict(now time.Time) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for key, sample := range s.samples {
+		if now.Sub(sample.last) >= s.interval {
+			delete(s.samples, key)
+		}
+	}
+}
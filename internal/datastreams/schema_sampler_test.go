@@ -0,0 +1,52 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package datastreams
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSchemaSampler(t *testing.T) {
+	s := newSchemaSampler(30 * time.Second)
+	now := time.Now()
+
+	weight, ok := s.trySample("key", now)
+	assert.True(t, ok)
+	assert.Equal(t, int64(1), weight)
+
+	_, ok = s.trySample("key", now.Add(10*time.Second))
+	assert.False(t, ok)
+	_, ok = s.trySample("key", now.Add(20*time.Second))
+	assert.False(t, ok)
+
+	// a new key is sampled right away
+	weight, ok = s.trySample("other", now.Add(20*time.Second))
+	assert.True(t, ok)
+	assert.Equal(t, int64(1), weight)
+
+	weight, ok = s.trySample("key", now.Add(30*time.Second))
+	assert.True(t, ok)
+	assert.Equal(t, int64(3), weight)
+}
+
+func TestSchemaSamplerEvict(t *testing.T) {
+	s := newSchemaSampler(30 * time.Second)
+	now := time.Now()
+
+	s.trySample("old", now)
+	s.trySample("recent", now.Add(20*time.Second))
+	s.evict(now.Add(30 * time.Second))
+	assert.Len(t, s.samples, 1)
+	assert.Contains(t, s.samples, "recent")
+
+	// an evicted key is sampled right away
+	weight, ok := s.trySample("old", now.Add(35*time.Second))
+	assert.True(t, ok)
+	assert.Equal(t, int64(1), weight)
+}
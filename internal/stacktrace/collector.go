@@ -0,0 +1,190 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2024 Datadog, Inc.
+
+package stacktrace
+
+import (
+	"encoding/binary"
+	"hash/fnv"
+	"sync"
+
+	"gopkg.in/DataDog/dd-trace-go.v1/ddtrace"
+	"gopkg.in/DataDog/dd-trace-go.v1/internal"
+)
+
+// Collector collects the stack trace events of a root span, such as the ones
+// of the exploits and vulnerabilities detected by AppSec, in order to bound the
+// size of the span's meta_struct. It keeps at most DD_APPSEC_MAX_STACK_TRACES
+// events, truncates their stack traces to DD_APPSEC_MAX_STACK_TRACE_DEPTH
+// frames by keeping their top and bottom frames, and drops the events whose
+// stack trace was already collected for the same category. The collectors of
+// the same root span share their events and limits, so that they can report
+// them independently, e.g. from nested request handlers. It is safe for
+// concurrent use. It collects nothing when stacktrace collection is disabled.
+type Collector struct {
+	root     *rootCollector
+	released bool
+}
+
+// rootCollector holds the events collected for a root span.
+type rootCollector struct {
+	span      ddtrace.Span
+	maxEvents int
+	maxDepth  int
+	events    []*Event
+	hashes    map[uint64]struct{}
+	mu        sync.Mutex
+	// refs is the number of collectors of the root span, or -1 once they were
+	// all released and the root span events removed from rootCollectors.
+	refs int
+}
+
+// rootCollectors holds the *rootCollector of the root spans being collected,
+// keyed by root span, so that the collectors of different root spans do not
+// contend on a shared lock.
+var rootCollectors sync.Map
+
+// NewCollector returns a new stack trace collector of the root span of the
+// given span, configured by the environment. AddToSpan must be called once the
+// events are collected.
+func NewCollector(span ddtrace.Span) *Collector {
+	return newCollector(span, defaultMaxStackTraces, defaultMaxDepth)
+}
+
+func newCollector(span ddtrace.Span, maxEvents, maxDepth int) *Collector {
+	if !Enabled() {
+		return &Collector{}
+	}
+	type rooter interface {
+		Root() ddtrace.Span
+	}
+	if lrs, ok := span.(rooter); ok {
+		span = lrs.Root()
+	}
+
+	for {
+		v, ok := rootCollectors.Load(span)
+		if !ok {
+			v, _ = rootCollectors.LoadOrStore(span, &rootCollector{
+				span:      span,
+				maxEvents: maxEvents,
+				maxDepth:  maxDepth,
+				hashes:    make(map[uint64]struct{}),
+			})
+		}
+		root := v.(*rootCollector)
+		root.mu.Lock()
+		released := root.refs < 0
+		if !released {
+			root.refs++
+		}
+		root.mu.Unlock()
+		// Retry with new root span events when the loaded ones were just
+		// released by the last collector of the root span.
+		if !released {
+			return &Collector{root: root}
+		}
+	}
+}
+
+// Add collects the given event, and returns false when it was dropped because
+// the maximum number of events of the root span was reached or because its
+// stack trace was already collected.
+func (c *Collector) Add(event *Event) bool {
+	r := c.root
+	if r == nil {
+		return false
+	}
+	event.Frames = event.Frames.truncate(r.maxDepth)
+	h := event.hash()
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.maxEvents > 0 && len(r.events) >= r.maxEvents {
+		return false
+	}
+	if _, dup := r.hashes[h]; dup {
+		return false
+	}
+	r.hashes[h] = struct{}{}
+	r.events = append(r.events, event)
+	return true
+}
+
+// Len returns the number of events collected for the root span.
+func (c *Collector) Len() int {
+	if c.root == nil {
+		return 0
+	}
+	c.root.mu.Lock()
+	defer c.root.mu.Unlock()
+	return len(c.root.events)
+}
+
+// AddToSpan adds the events collected for the root span to it, grouped by
+// category, if stacktrace collection is enabled and events were collected,
+// including the ones of the other collectors of the root span. The collector
+// is released and must not be used afterwards.
+func (c *Collector) AddToSpan() {
+	r := c.root
+	if r == nil {
+		return
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if !c.released {
+		c.released = true
+		if r.refs--; r.refs == 0 {
+			r.refs = -1
+			rootCollectors.CompareAndDelete(r.span, r)
+		}
+	}
+	if len(r.events) == 0 {
+		return
+	}
+
+	groupByCategory := map[EventCategory][]*Event{
+		ExceptionEvent:     {},
+		VulnerabilityEvent: {},
+		ExploitEvent:       {},
+	}
+	for _, event := range r.events {
+		groupByCategory[event.Category] = append(groupByCategory[event.Category], event)
+	}
+	// The tag holds the events of every collector of the root span, and is set
+	// while holding the lock so that the last collector sets all of them.
+	r.span.SetTag("_dd.stack", internal.MetaStructValue{Value: groupByCategory})
+}
+
+// hash returns the hash of the event category and stack trace frames.
+func (e *Event) hash() uint64 {
+	h := fnv.New64a()
+	h.Write([]byte(e.Category))
+	var line [4]byte
+	for _, f := range e.Frames {
+		h.Write([]byte{0})
+		h.Write([]byte(f.Namespace))
+		h.Write([]byte(f.ClassName))
+		h.Write([]byte(f.Function))
+		h.Write([]byte(f.File))
+		binary.LittleEndian.PutUint32(line[:], f.Line)
+		h.Write(line[:])
+	}
+	return h.Sum64()
+}
+
+// truncate returns the stack trace truncated to maxDepth frames by keeping its
+// top three quarters and bottom quarter, the same way the stack traces are
+// captured, or the stack trace itself when it is not deeper than maxDepth.
+func (st StackTrace) truncate(maxDepth int) StackTrace {
+	if maxDepth <= 0 || len(st) <= maxDepth {
+		return st
+	}
+	bottom := maxDepth / 4
+	truncated := make(StackTrace, 0, maxDepth)
+	truncated = append(truncated, st[:maxDepth-bottom]...)
+	return append(truncated, st[len(st)-bottom:]...)
+}
@@ -0,0 +1,158 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2024 Datadog, Inc.
+
+package stacktrace
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+
+	"gopkg.in/DataDog/dd-trace-go.v1/ddtrace/mocktracer"
+	ddtracer "gopkg.in/DataDog/dd-trace-go.v1/ddtrace/tracer"
+	"gopkg.in/DataDog/dd-trace-go.v1/internal"
+
+	"github.com/stretchr/testify/require"
+)
+
+func makeStackTrace(depth int) StackTrace {
+	st := make(StackTrace, depth)
+	for i := range st {
+		st[i] = StackFrame{Index: uint32(i), Function: fmt.Sprintf("f%d", i), File: "main.go", Line: uint32(i)}
+	}
+	return st
+}
+
+func TestCollector(t *testing.T) {
+	mt := mocktracer.Start()
+	defer mt.Stop()
+
+	t.Run("max-events", func(t *testing.T) {
+		c := newCollector(ddtracer.StartSpan("op"), 2, 32)
+		for i := 0; i < 3; i++ {
+			added := c.Add(&Event{Category: ExploitEvent, Frames: makeStackTrace(i + 1)})
+			require.Equal(t, i < 2, added)
+		}
+		require.Equal(t, 2, c.Len())
+	})
+
+	t.Run("unlimited", func(t *testing.T) {
+		c := newCollector(ddtracer.StartSpan("op"), 0, 32)
+		for i := 0; i < 10; i++ {
+			require.True(t, c.Add(&Event{Category: ExploitEvent, Frames: makeStackTrace(i + 1)}))
+		}
+	})
+
+	t.Run("dedup", func(t *testing.T) {
+		c := newCollector(ddtracer.StartSpan("op"), 10, 32)
+		require.True(t, c.Add(&Event{Category: ExploitEvent, ID: "1", Frames: makeStackTrace(5)}))
+		require.False(t, c.Add(&Event{Category: ExploitEvent, ID: "2", Frames: makeStackTrace(5)}))
+		// The same stack trace is kept for other categories
+		require.True(t, c.Add(&Event{Category: VulnerabilityEvent, ID: "3", Frames: makeStackTrace(5)}))
+		// A different line makes it a different stack trace
+		st := makeStackTrace(5)
+		st[2].Line++
+		require.True(t, c.Add(&Event{Category: ExploitEvent, ID: "4", Frames: st}))
+		require.Equal(t, 3, c.Len())
+	})
+
+	t.Run("truncation", func(t *testing.T) {
+		c := newCollector(ddtracer.StartSpan("op"), 10, 8)
+		event := &Event{Category: ExceptionEvent, Frames: makeStackTrace(20)}
+		require.True(t, c.Add(event))
+		require.Len(t, event.Frames, 8)
+		// Top frames
+		for i := 0; i < 6; i++ {
+			require.Equal(t, uint32(i), event.Frames[i].Index)
+		}
+		// Bottom frames
+		require.Equal(t, uint32(18), event.Frames[6].Index)
+		require.Equal(t, uint32(19), event.Frames[7].Index)
+	})
+}
+
+func TestCollectorToSpan(t *testing.T) {
+	mt := mocktracer.Start()
+	defer mt.Stop()
+
+	root := ddtracer.StartSpan("root")
+	child := ddtracer.StartSpan("child", ddtracer.ChildOf(root.Context()))
+	c := NewCollector(child)
+	// Stack traces captured at different lines
+	exploit := NewEvent(ExploitEvent, WithID("1"))
+	vuln := NewEvent(VulnerabilityEvent, WithID("2"))
+	require.True(t, c.Add(exploit))
+	require.True(t, c.Add(vuln))
+	c.AddToSpan()
+	child.Finish()
+	root.Finish()
+
+	spans := mt.FinishedSpans()
+	require.Len(t, spans, 2)
+	require.Nil(t, spans[0].Tag("_dd.stack"))
+	eventsMap := spans[1].Tag("_dd.stack").(internal.MetaStructValue).Value.(map[EventCategory][]*Event)
+	require.Equal(t, []*Event{exploit}, eventsMap[ExploitEvent])
+	require.Equal(t, []*Event{vuln}, eventsMap[VulnerabilityEvent])
+	require.Empty(t, eventsMap[ExceptionEvent])
+
+	t.Run("shared-root", func(t *testing.T) {
+		mt.Reset()
+		root := ddtracer.StartSpan("root")
+		c1 := newCollector(root, 2, 32)
+		c2 := newCollector(ddtracer.StartSpan("child", ddtracer.ChildOf(root.Context())), 2, 32)
+		require.True(t, c1.Add(&Event{Category: ExploitEvent, ID: "1", Frames: makeStackTrace(1)}))
+		require.True(t, c2.Add(&Event{Category: ExploitEvent, ID: "2", Frames: makeStackTrace(2)}))
+		// The limit is shared by the collectors of the root span
+		require.False(t, c2.Add(&Event{Category: ExploitEvent, ID: "3", Frames: makeStackTrace(3)}))
+		// Neither collector overwrites the events of the other one
+		var wg sync.WaitGroup
+		for _, c := range []*Collector{c2, c1} {
+			wg.Add(1)
+			go func(c *Collector) {
+				defer wg.Done()
+				c.AddToSpan()
+			}(c)
+		}
+		wg.Wait()
+		root.Finish()
+
+		eventsMap := mt.FinishedSpans()[0].Tag("_dd.stack").(internal.MetaStructValue).Value.(map[EventCategory][]*Event)
+		require.Len(t, eventsMap[ExploitEvent], 2)
+		// The root span events are released once every collector reported them
+		_, ok := rootCollectors.Load(root)
+		require.False(t, ok)
+	})
+
+	t.Run("disabled", func(t *testing.T) {
+		defer func(e bool) { enabled = e }(enabled)
+		enabled = false
+		mt.Reset()
+		span := ddtracer.StartSpan("op")
+		c := NewCollector(span)
+		require.False(t, c.Add(NewEvent(ExploitEvent)))
+		require.Zero(t, c.Len())
+		c.AddToSpan()
+		span.Finish()
+		require.Nil(t, mt.FinishedSpans()[0].Tag("_dd.stack"))
+		// Nothing is shared when stacktrace collection is disabled
+		_, ok := rootCollectors.Load(span)
+		require.False(t, ok)
+	})
+
+	t.Run("empty", func(t *testing.T) {
+		mt.Reset()
+		span := ddtracer.StartSpan("op")
+		NewCollector(span).AddToSpan()
+		span.Finish()
+		require.Nil(t, mt.FinishedSpans()[0].Tag("_dd.stack"))
+	})
+}
+
+func TestTruncate(t *testing.T) {
+	st := makeStackTrace(4)
+	require.Equal(t, st, st.truncate(4))
+	require.Equal(t, st, st.truncate(0))
+	require.Equal(t, StackTrace{st[0], st[1], st[2]}, st.truncate(3))
+}
@@ -9,7 +9,6 @@ package stacktrace
 
 import (
 	"gopkg.in/DataDog/dd-trace-go.v1/ddtrace"
-	"gopkg.in/DataDog/dd-trace-go.v1/internal"
 
 	"github.com/tinylib/msgp/msgp"
 )
@@ -82,28 +81,16 @@ func WithID(id string) Options {
 	}
 }
 
-// AddToSpan adds the event to the given span's root span as a tag if stacktrace collection is enabled
+// AddToSpan adds the events to the given span's root span as a tag if stacktrace collection is enabled, within the
+// limits of a Collector and along with the events of the other collectors of the root span.
 func AddToSpan(span ddtrace.Span, events ...*Event) {
 	if !Enabled() {
 		return
 	}
 
-	groupByCategory := map[EventCategory][]*Event{
-		ExceptionEvent:     {},
-		VulnerabilityEvent: {},
-		ExploitEvent:       {},
-	}
-
+	c := NewCollector(span)
 	for _, event := range events {
-		groupByCategory[event.Category] = append(groupByCategory[event.Category], event)
-	}
-
-	type rooter interface {
-		Root() ddtrace.Span
-	}
-	if lrs, ok := span.(rooter); ok {
-		span = lrs.Root()
+		c.Add(event)
 	}
-
-	span.SetTag("_dd.stack", internal.MetaStructValue{Value: groupByCategory})
+	c.AddToSpan()
 }
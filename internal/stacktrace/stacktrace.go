@@ -21,9 +21,10 @@ import (
 )
 
 var (
-	enabled              = true
-	defaultTopFrameDepth = 8
-	defaultMaxDepth      = 32
+	enabled               = true
+	defaultTopFrameDepth  = 8
+	defaultMaxDepth       = 32
+	defaultMaxStackTraces = 2
 
 	// internalPackagesPrefixes is the list of prefixes for internal packages that should be hidden in the stack trace
 	internalSymbolPrefixes = []string{
@@ -40,6 +41,7 @@ const (
 	defaultCallerSkip    = 4
 	envStackTraceDepth   = "DD_APPSEC_MAX_STACK_TRACE_DEPTH"
 	envStackTraceEnabled = "DD_APPSEC_STACK_TRACE_ENABLE"
+	envMaxStackTraces    = "DD_APPSEC_MAX_STACK_TRACES"
 )
 
 func init() {
@@ -51,6 +53,17 @@ func init() {
 		}
 	}
 
+	if env := os.Getenv(envMaxStackTraces); env != "" {
+		if n, err := parseutil.SafeParseInt(env); err == nil && n >= 0 {
+			defaultMaxStackTraces = n
+		} else {
+			if err == nil {
+				err = errors.New("value is not a positive integer")
+			}
+			log.Error("Failed to parse %s env var as a positive integer: %v (using default value: %v)", envMaxStackTraces, err, defaultMaxStackTraces)
+		}
+	}
+
 	if env := os.Getenv(envStackTraceDepth); env != "" {
 		if !enabled {
 			log.Warn("Ignoring %s because stacktrace generation is disable", envStackTraceDepth)